@@ -0,0 +1,252 @@
+package ipod
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ACKStatus is the status of an ACK command.
+// The values are common for all lingos.
+type ACKStatus uint8
+
+const (
+	ACKStatusSuccess      ACKStatus = 0x00
+	ACKStatusFailed       ACKStatus = 0x02
+	ACKStatusBadParameter ACKStatus = 0x04
+	ACKStatusPending      ACKStatus = 0x06
+)
+
+// ACKPayload is implemented by the ACK payloads of the lingos
+type ACKPayload interface {
+	ACKStatus() ACKStatus
+	ACKCmdID() uint16
+}
+
+// ACKPendingPayload is implemented by the ACK payloads
+// that extend the time to wait for the final response
+type ACKPendingPayload interface {
+	ACKPayload
+	ACKMaxWait() time.Duration
+}
+
+// ACKError is returned by Call when the response
+// is an ACK with a failure status
+type ACKError struct {
+	ID     LingoCmdID
+	Status ACKStatus
+}
+
+func (e *ACKError) Error() string {
+	return fmt.Sprintf("ipod: cmd %v: ack status %#02x", e.ID, uint8(e.Status))
+}
+
+// TimeoutError is returned by Call when no response
+// is received in time
+type TimeoutError struct {
+	ID          LingoCmdID
+	Transaction Transaction
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("ipod: cmd %v trx %v: response timeout", e.ID, e.Transaction)
+}
+
+// Timeout is always true
+func (e *TimeoutError) Timeout() bool {
+	return true
+}
+
+// DefaultCallTimeout is the time Call waits for a response
+// unless it is extended by a pending ACK
+const DefaultCallTimeout = 1 * time.Second
+
+// pendingCall is a Call waiting for its response
+type pendingCall struct {
+	id LingoCmdID
+	ch chan *Command
+}
+
+// matches reports whether cmd is the response to the call
+func (c *pendingCall) matches(cmd *Command) bool {
+	return isResponse(c.id, cmd)
+}
+
+// isResponse reports whether cmd responds to the request id: the command
+// of the same lingo following the request or an ACK of the request
+func isResponse(id LingoCmdID, cmd *Command) bool {
+	if cmd.ID.LingoID() != id.LingoID() {
+		return false
+	}
+	if ack, ok := cmd.Payload.(ACKPayload); ok {
+		return ack.ACKCmdID() == id.CmdID()
+	}
+	return cmd.ID == responseID(id)
+}
+
+// responseID returns the ID of the response to the request id,
+// the response follows the request in all lingos
+func responseID(id LingoCmdID) LingoCmdID {
+	return NewLingoCmdID(id.LingoID(), id.CmdID()+1)
+}
+
+// sentLen is the number of commands written with Send
+// whose responses are recognized
+const sentLen = 16
+
+// sentCommand is an ipod-initiated command written with Send
+type sentCommand struct {
+	id       LingoCmdID
+	trx      Transaction
+	answered bool
+}
+
+type callTracker struct {
+	mu    sync.Mutex
+	calls map[Transaction]*pendingCall
+
+	sent  [sentLen]sentCommand
+	nSent int
+}
+
+// responseTracker is implemented by the writers of a Session,
+// Send passes them its commands so that the responses
+// are not taken for accessory-initiated transactions
+type responseTracker interface {
+	trackResponse(cmd *Command)
+}
+
+func (ct *callTracker) add(id LingoCmdID, trx Transaction) chan *Command {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	if ct.calls == nil {
+		ct.calls = make(map[Transaction]*pendingCall)
+	}
+	c := &pendingCall{id: id, ch: make(chan *Command, 4)}
+	ct.calls[trx] = c
+	return c.ch
+}
+
+func (ct *callTracker) remove(trx Transaction) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	delete(ct.calls, trx)
+}
+
+// send records cmd, an ipod-initiated command written without Call
+func (ct *callTracker) send(cmd *Command) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.sent[ct.nSent%sentLen] = sentCommand{id: cmd.ID, trx: *cmd.Transaction}
+	ct.nSent++
+}
+
+// response reports whether cmd responds to one of the recent commands
+// recorded by send. The command is forgotten, so that a repeated
+// response is checked like any other transaction.
+func (ct *callTracker) response(cmd *Command) bool {
+	if cmd.Transaction == nil {
+		return false
+	}
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	n := ct.nSent
+	if n > sentLen {
+		n = sentLen
+	}
+	for i := 0; i < n; i++ {
+		c := &ct.sent[i]
+		if !c.answered && c.trx == *cmd.Transaction && isResponse(c.id, cmd) {
+			c.answered = true
+			return true
+		}
+	}
+	return false
+}
+
+// deliver passes cmd to the pending call it responds to,
+// the transaction and the command must match.
+// It reports whether there was one.
+func (ct *callTracker) deliver(cmd *Command) bool {
+	if cmd.Transaction == nil {
+		return false
+	}
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	c, ok := ct.calls[*cmd.Transaction]
+	if !ok || !c.matches(cmd) {
+		return false
+	}
+	select {
+	case c.ch <- cmd:
+	default:
+	}
+	return true
+}
+
+// Call sends an ipod-initiated request with a new transaction and waits
+// for the response with the same transaction: the command of the lingo
+// with the ID following the request, i.e. RetAccessoryInfo for GetAccessoryInfo,
+// or an ACK of the request. Other commands with the transaction go to the handlers.
+// A pending ACK extends the wait by its max wait time,
+// an ACK with a failure status results in *ACKError, and no response
+// within the timeout results in *TimeoutError.
+// The matching responses are not passed to the handlers.
+//
+// Call needs Serve to be running, so it must not be called
+// from a handler synchronously.
+func (s *Session) Call(ctx context.Context, payload interface{}) (*Command, error) {
+	cmd, err := s.LingoRegistry().BuildCommand(payload)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Transaction = s.trx.Next()
+	trx := *cmd.Transaction
+
+	ch := s.calls.add(cmd.ID, trx)
+	defer s.calls.remove(trx)
+
+	if err := s.WriteCommand(cmd); err != nil {
+		return nil, err
+	}
+
+	timeout := s.CallTimeout
+	if timeout == 0 {
+		timeout = DefaultCallTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+			return nil, &TimeoutError{ID: cmd.ID, Transaction: trx}
+		case resp := <-ch:
+			ack, ok := resp.Payload.(ACKPayload)
+			if !ok {
+				return resp, nil
+			}
+			switch ack.ACKStatus() {
+			case ACKStatusSuccess:
+				return resp, nil
+			case ACKStatusPending:
+				wait := timeout
+				if p, ok := ack.(ACKPendingPayload); ok {
+					wait = p.ACKMaxWait()
+				}
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(wait)
+			default:
+				return resp, &ACKError{ID: cmd.ID, Status: ack.ACKStatus()}
+			}
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package ipod_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/oandrew/ipod"
+)
+
+type chanFrameReadWriter struct {
+	in  chan []byte
+	out chan []byte
+}
+
+func newChanFrameReadWriter() *chanFrameReadWriter {
+	return &chanFrameReadWriter{
+		in:  make(chan []byte, 16),
+		out: make(chan []byte, 16),
+	}
+}
+
+func (rw *chanFrameReadWriter) ReadFrame() ([]byte, error) {
+	frame, ok := <-rw.in
+	if !ok {
+		return nil, io.EOF
+	}
+	return frame, nil
+}
+
+func (rw *chanFrameReadWriter) WriteFrame(data []byte) error {
+	rw.out <- append([]byte(nil), data...)
+	return nil
+}
+
+func readCommand(t *testing.T, frame []byte) *ipod.Command {
+	pkt, err := ipod.NewPacketReader(bytes.NewReader(frame)).ReadPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cmd ipod.Command
+	if err := cmd.UnmarshalBinary(pkt); err != nil {
+		t.Fatal(err)
+	}
+	return &cmd
+}
+
+func TestSession_Call(t *testing.T) {
+	ack := func(trx ipod.Transaction, status uint8, maxWait uint32) *ipod.Command {
+		return &ipod.Command{
+			ID:          ipod.NewLingoCmdID(testSessionLingoID, 0x03),
+			Transaction: &trx,
+			Payload:     &SessionACK{Status: status, CmdID: 0x01, MaxWait: maxWait},
+		}
+	}
+	resp := func(trx ipod.Transaction) *ipod.Command {
+		return &ipod.Command{
+			ID:          ipod.NewLingoCmdID(testSessionLingoID, 0x02),
+			Transaction: &trx,
+			Payload:     &SessionResponse{V: 0x02},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		responses func(trx ipod.Transaction) []*ipod.Command
+		delay     time.Duration
+		wantErr   func(error) bool
+	}{
+		{"response", func(trx ipod.Transaction) []*ipod.Command {
+			return []*ipod.Command{resp(trx)}
+		}, 0, nil},
+		{"ack-success", func(trx ipod.Transaction) []*ipod.Command {
+			return []*ipod.Command{ack(trx, 0x00, 0)}
+		}, 0, nil},
+		{"ack-failed", func(trx ipod.Transaction) []*ipod.Command {
+			return []*ipod.Command{ack(trx, 0x02, 0)}
+		}, 0, func(err error) bool {
+			e, ok := err.(*ipod.ACKError)
+			return ok && e.Status == 0x02
+		}},
+		{"pending-then-response", func(trx ipod.Transaction) []*ipod.Command {
+			return []*ipod.Command{ack(trx, 0x06, 1000), resp(trx)}
+		}, 100 * time.Millisecond, nil},
+		{"other-trx-timeout", func(trx ipod.Transaction) []*ipod.Command {
+			return []*ipod.Command{resp(trx + 1)}
+		}, 0, func(err error) bool {
+			e, ok := err.(*ipod.TimeoutError)
+			return ok && e.Timeout()
+		}},
+		{"pending-timeout", func(trx ipod.Transaction) []*ipod.Command {
+			return []*ipod.Command{ack(trx, 0x06, 10)}
+		}, 0, func(err error) bool {
+			_, ok := err.(*ipod.TimeoutError)
+			return ok
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frw := newChanFrameReadWriter()
+			s := ipod.NewSession(frw)
+			s.CallTimeout = 50 * time.Millisecond
+			go s.Serve()
+
+			done := make(chan struct{})
+			defer func() {
+				<-done
+				close(frw.in)
+			}()
+			go func() {
+				defer close(done)
+				req := readCommand(t, <-frw.out)
+				for i, resp := range tt.responses(*req.Transaction) {
+					if i > 0 {
+						time.Sleep(tt.delay)
+					}
+					frw.in <- buildFrame(t, resp)
+				}
+			}()
+
+			resp, err := s.Call(context.Background(), &SessionRequest{V: 0x01})
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("Session.Call() error = %v", err)
+				}
+				if resp == nil {
+					t.Fatalf("Session.Call() no response")
+				}
+				return
+			}
+			if !tt.wantErr(err) {
+				t.Errorf("Session.Call() unexpected error = %v", err)
+			}
+		})
+	}
+}
+
+func TestSession_CallContext(t *testing.T) {
+	frw := newChanFrameReadWriter()
+	s := ipod.NewSession(frw)
+	go s.Serve()
+	defer close(frw.in)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := s.Call(ctx, &SessionRequest{V: 0x01}); err != context.DeadlineExceeded {
+		t.Errorf("Session.Call() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestSession_CallTransactionCollision(t *testing.T) {
+	frw := newChanFrameReadWriter()
+	s := ipod.NewSession(frw)
+	s.CallTimeout = time.Second
+	handled := make(chan *ipod.Command, 1)
+	s.HandleFunc(testSessionLingoID, func(cmd *ipod.Command, w ipod.CommandWriter) error {
+		handled <- cmd
+		return nil
+	})
+	go s.Serve()
+	defer close(frw.in)
+
+	go func() {
+		req := readCommand(t, <-frw.out)
+		trx := *req.Transaction
+		// an accessory-initiated request that happens to use the transaction
+		frw.in <- buildFrame(t, &ipod.Command{
+			ID:          req.ID,
+			Transaction: &trx,
+			Payload:     &SessionRequest{V: 0x05},
+		})
+		frw.in <- buildFrame(t, &ipod.Command{
+			ID:          ipod.NewLingoCmdID(testSessionLingoID, 0x02),
+			Transaction: &trx,
+			Payload:     &SessionResponse{V: 0x02},
+		})
+	}()
+
+	resp, err := s.Call(context.Background(), &SessionRequest{V: 0x01})
+	if err != nil {
+		t.Fatalf("Session.Call() error = %v", err)
+	}
+	if p, ok := resp.Payload.(*SessionResponse); !ok || p.V != 0x02 {
+		t.Errorf("Session.Call() = %v, want the response", resp)
+	}
+	select {
+	case cmd := <-handled:
+		if p, ok := cmd.Payload.(*SessionRequest); !ok || p.V != 0x05 {
+			t.Errorf("handler got %v, want the accessory request", cmd)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("the accessory request didn't reach the handler")
+	}
+}
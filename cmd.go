@@ -4,9 +4,9 @@ import (
 	"bytes"
 	"encoding"
 	"encoding/binary"
-	"errors"
 	"fmt"
-	"sync/atomic"
+	"io"
+	"reflect"
 
 	"log"
 )
@@ -59,90 +59,147 @@ func (tr *Transaction) Delta(d int) *Transaction {
 	return nil
 }
 
-func (cmd *Command) MarshalBinary() ([]byte, error) {
-	pktBuf := &bytes.Buffer{}
+// BinaryAppender is implemented by payloads that encode themselves
+// at the end of a buffer, i.e. the payloads generated by ipodgen.
+// AppendBinary must not retain b.
+type BinaryAppender interface {
+	AppendBinary(b []byte) ([]byte, error)
+}
 
-	if err := marshalLingoCmdID(pktBuf, cmd.ID); err != nil {
-		return nil, fmt.Errorf("ipod.Command marshal: %v", err)
-	}
+// MarshalBinary encodes the command into a new packet payload
+func (cmd *Command) MarshalBinary() ([]byte, error) {
+	return cmd.AppendBinary(nil)
+}
 
+// AppendBinary appends the encoded command to b and returns the extended buffer.
+// The caller owns the result, with a reused b of enough capacity the payloads
+// implementing BinaryAppender are encoded without allocating.
+// On error nil is returned.
+func (cmd *Command) AppendBinary(b []byte) ([]byte, error) {
+	b = appendLingoCmdID(b, cmd.ID)
 	if cmd.Transaction != nil {
-		binary.Write(pktBuf, binary.BigEndian, *cmd.Transaction)
-	}
-	if cmd.Payload == nil {
-		return nil, fmt.Errorf("ipod.Command marshal: nil payload")
+		b = append(b, byte(*cmd.Transaction>>8), byte(*cmd.Transaction))
 	}
 
-	if d, ok := cmd.Payload.(encoding.BinaryMarshaler); ok {
-		payload, err := d.MarshalBinary()
+	switch p := cmd.Payload.(type) {
+	case nil:
+		return nil, fmt.Errorf("ipod.Command marshal: nil payload")
+	case UnknownPayload:
+		return append(b, p...), nil
+	case BinaryAppender:
+		b, err := p.AppendBinary(b)
+		if err != nil {
+			return nil, fmt.Errorf("ipod.Command marshal: BinaryAppender: %v", err)
+		}
+		return b, nil
+	case encoding.BinaryMarshaler:
+		payload, err := p.MarshalBinary()
 		if err != nil {
 			return nil, fmt.Errorf("ipod.Command marshal: BinaryMarshaler: %v", err)
 		}
-		pktBuf.Write(payload)
+		return append(b, payload...), nil
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if v, ok := taggedPayload(cmd.Payload); ok {
+		if err := marshalTagged(buf, v); err != nil {
+			return nil, fmt.Errorf("ipod.Command marshal: %v", err)
+		}
 	} else {
-		err := binary.Write(pktBuf, binary.BigEndian, cmd.Payload)
+		err := binary.Write(buf, binary.BigEndian, cmd.Payload)
 		if err != nil {
 			return nil, fmt.Errorf("ipod.Command marshal: binary.Write: %v", err)
 		}
 	}
-
-	return pktBuf.Bytes(), nil
-
+	return append(b, buf.Bytes()...), nil
 }
 
+// UnmarshalBinary decodes the command using the DefaultRegistry
 func (cmd *Command) UnmarshalBinary(pkt []byte) error {
-	pktBuf := bytes.NewBuffer(pkt)
-	if err := unmarshalLingoCmdID(pktBuf, &cmd.ID); err != nil {
+	return DefaultRegistry.UnmarshalCommand(cmd, pkt, nil)
+}
+
+// UnmarshalCommand decodes the packet into cmd choosing the payload
+// with LookupPayload in the context returned by ctx for the lingo.
+// If ctx is nil the context is unknown.
+//
+// The command doesn't retain pkt, so the packet buffer can be reused.
+// The Transaction and the Payload of cmd are reused if they are set
+// and the payload has the type found, so decoding repeatedly
+// into the same Command doesn't allocate for generated payloads.
+func (r *Registry) UnmarshalCommand(cmd *Command, pkt []byte, ctx func(lingoID uint8) LookupContext) error {
+	id, n, err := decodeLingoCmdID(pkt)
+	if err != nil {
 		return fmt.Errorf("ipod.Command unmarshal: %v", err)
 	}
+	cmd.ID = id
+	data := pkt[n:]
 
-	lookup, ok := Lookup(cmd.ID, pktBuf.Len())
-	if !ok {
-		cmd.Payload = UnknownPayload(pktBuf.Bytes())
-		return fmt.Errorf("ipod.Command unmarshal: unknown cmd %v", cmd.ID)
+	var lctx LookupContext
+	if ctx != nil {
+		lctx = ctx(uint8(cmd.ID.LingoID()))
+	}
+	p, trx, err := r.lookupType(cmd.ID, data, lctx)
+	if err != nil {
+		cmd.Payload = UnknownPayload(append(make([]byte, 0, len(data)), data...))
+		return fmt.Errorf("ipod.Command unmarshal: %v", err)
 	}
 
-	if lookup.Transaction {
-		var tr Transaction
-		err := binary.Read(pktBuf, binary.BigEndian, &tr)
-		if err != nil {
-			return err
+	if trx {
+		switch len(data) {
+		case 0:
+			return fmt.Errorf("ipod.Command unmarshal: transaction: %v", io.EOF)
+		case 1:
+			return fmt.Errorf("ipod.Command unmarshal: transaction: %v", io.ErrUnexpectedEOF)
+		}
+		tr := binary.BigEndian.Uint16(data)
+		if cmd.Transaction != nil {
+			*cmd.Transaction = Transaction(tr)
+		} else {
+			cmd.Transaction = NewTransaction(tr)
 		}
-		cmd.Transaction = &tr
+		data = data[2:]
+	} else {
+		cmd.Transaction = nil
+	}
+
+	payload := cmd.Payload
+	if payload != nil && reflect.TypeOf(payload) == p.ptr {
+		reflect.ValueOf(payload).Elem().Set(reflect.Zero(p.t))
+	} else {
+		payload = p.new()
 	}
+	cmd.Payload = nil
 
-	if d, ok := lookup.Payload.(encoding.BinaryUnmarshaler); ok {
-		err := d.UnmarshalBinary(pktBuf.Bytes())
+	if d, ok := payload.(encoding.BinaryUnmarshaler); ok {
+		err := d.UnmarshalBinary(data)
 		if err != nil {
 			return fmt.Errorf("ipod.Command unmarshal: BinaryUnmarshaler: %v", err)
 		}
 
+	} else if v, ok := taggedPayload(payload); ok {
+		if _, err := unmarshalTagged(data, v); err != nil {
+			return fmt.Errorf("ipod.Command unmarshal: %v", err)
+		}
 	} else {
-		err := binary.Read(pktBuf, binary.BigEndian, lookup.Payload)
+		err := binary.Read(bytes.NewReader(data), binary.BigEndian, payload)
 		if err != nil {
 			return fmt.Errorf("ipod.Command unmarshal: binary.Read: %v", err)
 		}
 	}
-	//cmd.Payload = reflect.Indirect(reflect.ValueOf(lookup.Payload)).Interface()
-	cmd.Payload = lookup.Payload
+	cmd.Payload = payload
 	return nil
 
 }
 
+// BuildCommand creates a command using the DefaultRegistry
 func BuildCommand(payload interface{}) (*Command, error) {
-	id, ok := LookupID(payload)
-	if !ok {
-		return nil, errors.New("payload not known")
-	}
-	return &Command{
-		ID:      id,
-		Payload: payload,
-	}, nil
-
+	return DefaultRegistry.BuildCommand(payload)
 }
 
 func Respond(req *Command, pw CommandWriter, payload interface{}) {
-	cmd, err := BuildCommand(payload)
+	cmd, err := writerRegistry(pw).BuildCommand(payload)
 	if err != nil {
 		log.Printf("BuildCommand err: %v", err)
 		return
@@ -151,22 +208,18 @@ func Respond(req *Command, pw CommandWriter, payload interface{}) {
 	pw.WriteCommand(cmd)
 }
 
-var trxCounter uint32
-
-func TrxReset() {
-	atomic.StoreUint32(&trxCounter, 0)
-}
-func TrxNext() *Transaction {
-	trx := atomic.AddUint32(&trxCounter, 1)
-	return NewTransaction(uint16(trx))
-}
-
+// Send sends an ipod-initiated command with a new transaction
+// taken from the link of pw. The response goes to the handlers
+// of a Session like any other command.
 func Send(pw CommandWriter, payload interface{}) {
-	cmd, err := BuildCommand(payload)
+	cmd, err := writerRegistry(pw).BuildCommand(payload)
 	if err != nil {
 		return
 	}
-	cmd.Transaction = TrxNext()
+	cmd.Transaction = NextTransaction(pw)
+	if rt, ok := pw.(responseTracker); ok {
+		rt.trackResponse(cmd)
+	}
 	pw.WriteCommand(cmd)
 }
 
@@ -7,11 +7,27 @@ ipod talks to an ipod accessory using iap protocol.
 # save a trace file
 ./ipod -d serve -w ipod.trace /dev/iap0
 
+# serve over a serial link
+./ipod -d serve -t serial -b 57600 /dev/ttyAMA0
+
+# use the reports of the HID report descriptor of the gadget
+./ipod -d serve -r /sys/class/hidraw/hidraw0/device/report_descriptor /dev/iap0
+# send frames in the fewest bytes instead of the first fitting report
+./ipod -d serve --report-strategy min-bytes /dev/iap0
+# write the report descriptor of the default reports for a configfs HID function
+./ipod descriptor > /sys/kernel/config/usb_gadget/g1/functions/hid.usb0/report_desc
+
 # simulate incoming requests from a trace file
 ./ipod -d replay ./ipod.trace
 
 # view a trace file
 ./ipod -d view ./ipod.trace
+# decode a trace file to JSON lines
+./ipod view --json ./ipod.trace > ipod.jsonl
+# send commands as an accessory, see ipod.ParseCommand for the syntax
+./ipod send -c "extremote.PlayControl Cmd=Toggle" /dev/iap0
+# send a script with a command per line or type them (-s -)
+./ipod send -s commands.txt /dev/iap0
 
 
 Each line of a trace file starts with a
@@ -3,26 +3,36 @@ package main
 import (
 	"bytes"
 	"fmt"
+	"sync"
 
-	"github.com/davecgh/go-spew/spew"
-
+	"github.com/oandrew/ipod"
 	"github.com/oandrew/ipod/lingo-general"
 
 	"github.com/fullsailor/pkcs7"
 )
 
 type DevGeneral struct {
+	// mu guards uimode, it is set asynchronously
+	mu     sync.Mutex
 	uimode general.UIMode
 	tokens []general.FIDTokenValue
+	prefs  map[uint8]uint8
+	// restore holds the settings to restore on exit by class
+	restore map[uint8]uint8
 }
 
 var _ general.DeviceGeneral = &DevGeneral{}
+var _ general.PrefRestorer = &DevGeneral{}
 
 func (d *DevGeneral) UIMode() general.UIMode {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	return d.uimode
 }
 
 func (d *DevGeneral) SetUIMode(mode general.UIMode) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	d.uimode = mode
 }
 
@@ -58,10 +68,26 @@ func (d *DevGeneral) LingoOptions(lingo uint8) uint64 {
 }
 
 func (d *DevGeneral) PrefSettingID(classID uint8) uint8 {
-	return 0
+	return d.prefs[classID]
 }
 
 func (d *DevGeneral) SetPrefSettingID(classID uint8, settingID uint8, restoreOnExit bool) {
+	if d.prefs == nil {
+		d.prefs = make(map[uint8]uint8)
+		d.restore = make(map[uint8]uint8)
+	}
+	if _, ok := d.restore[classID]; restoreOnExit && !ok {
+		d.restore[classID] = d.prefs[classID]
+	}
+	d.prefs[classID] = settingID
+}
+
+func (d *DevGeneral) RestorePrefSettings() {
+	for classID, settingID := range d.restore {
+		log.WithField("class", classID).WithField("setting", settingID).Info("restoring preference")
+		d.prefs[classID] = settingID
+		delete(d.restore, classID)
+	}
 }
 
 func (d *DevGeneral) SetEventNotificationMask(mask uint64) {
@@ -77,7 +103,7 @@ func (d *DevGeneral) SupportedEventNotificationMask() uint64 {
 }
 
 func (d *DevGeneral) CancelCommand(lingo uint8, cmd uint16, transaction uint16) {
-
+	log.WithField("lingo", lingo).WithField("cmd", cmd).WithField("trx", transaction).Info("command cancelled")
 }
 
 func (d *DevGeneral) MaxPayload() uint16 {
@@ -90,6 +116,9 @@ func (d *DevGeneral) StartIDPS() {
 
 func (d *DevGeneral) SetToken(token general.FIDTokenValue) error {
 	d.tokens = append(d.tokens, token)
+	if t, ok := token.Token.(*general.FIDiPodPreferenceToken); ok {
+		d.SetPrefSettingID(t.PrefClass, t.PrefClassSetting, t.RestoreOnExit != 0)
+	}
 	return nil
 }
 
@@ -97,36 +126,7 @@ func (d *DevGeneral) EndIDPS(status general.AccEndIDPSStatus) {
 	var buf bytes.Buffer
 	fmt.Fprintf(&buf, "Tokens:\n")
 	for _, token := range d.tokens {
-
-		fmt.Fprintf(&buf, "* Token: %T\n", token.Token)
-		//log.Printf("New token: %T", token.Token)
-		switch t := token.Token.(type) {
-		case *general.FIDIdentifyToken:
-
-		case *general.FIDAccCapsToken:
-			for _, c := range general.AccCaps {
-				if t.AccCapsBitmask&uint64(c) != 0 {
-					fmt.Fprintf(&buf, "Capability: %v\n", c)
-				}
-			}
-		case *general.FIDAccInfoToken:
-			key := general.AccInfoType(t.AccInfoType).String()
-			fmt.Fprintf(&buf, "%s: %s\n", key, spew.Sdump(t.Value))
-
-		case *general.FIDiPodPreferenceToken:
-
-		case *general.FIDEAProtocolToken:
-
-		case *general.FIDBundleSeedIDPrefToken:
-
-		case *general.FIDScreenInfoToken:
-
-		case *general.FIDEAProtocolMetadataToken:
-
-		case *general.FIDMicrophoneCapsToken:
-
-		}
-
+		fmt.Fprintf(&buf, "* %s\n", ipod.FormatPayload(token.Token))
 	}
 	log.Print(buf.String())
 }
@@ -1,8 +1,6 @@
 package main
 
 import (
-	"fmt"
-
 	"github.com/oandrew/ipod"
 	"github.com/sirupsen/logrus"
 )
@@ -20,6 +18,16 @@ func CommandLogEntry(e *logrus.Entry, cmd *ipod.Command) *logrus.Entry {
 	return e.WithFields(logrus.Fields{
 		"id":   cmd.ID,
 		"trx":  cmd.Transaction,
-		"type": fmt.Sprintf("%T", cmd.Payload),
+		"type": ipod.PayloadName(cmd.Payload),
+	})
+}
+
+func PacketStatsLogEntry(e *logrus.Entry, stats ipod.PacketStats) *logrus.Entry {
+	return e.WithFields(logrus.Fields{
+		"packets":       stats.Packets,
+		"crc_errors":    stats.CRCErrors,
+		"truncated":     stats.Truncated,
+		"resyncs":       stats.Resyncs,
+		"bytes_skipped": stats.BytesSkipped,
 	})
 }
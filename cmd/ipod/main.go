@@ -2,9 +2,14 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"os"
@@ -20,6 +25,7 @@ import (
 	"github.com/oandrew/ipod/lingo-extremote"
 	"github.com/oandrew/ipod/lingo-general"
 	"github.com/oandrew/ipod/lingo-simpleremote"
+	"github.com/oandrew/ipod/serial"
 	"github.com/oandrew/ipod/trace"
 )
 
@@ -53,6 +59,20 @@ type UsageError struct {
 	error
 }
 
+// signalContext returns a context that is cancelled on SIGINT or SIGTERM
+func signalContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		s := <-sig
+		signal.Stop(sig)
+		log.WithField("signal", s).Warn("shutting down")
+		cancel()
+	}()
+	return ctx
+}
+
 func main() {
 	logOut := os.Stdout
 	log.Formatter = &TextFormatter{
@@ -99,6 +119,8 @@ func main() {
 		return nil
 	}
 
+	ctx := signalContext()
+
 	app.Commands = []cli.Command{
 		{
 			Name:  "lingos",
@@ -109,6 +131,38 @@ func main() {
 				return nil
 			},
 		},
+		{
+			Name:  "descriptor",
+			Usage: "write the HID report descriptor of the default reports to stdout i.e. for a configfs HID function",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "hex",
+					Usage: "write the bytes as a C array initializer",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				desc, err := hid.DefaultReportDefs.ReportDescriptor()
+				if err != nil {
+					return err
+				}
+				if !c.Bool("hex") {
+					_, err := os.Stdout.Write(desc)
+					return err
+				}
+				for i := 0; i < len(desc); i += 16 {
+					line := desc[i:]
+					if len(line) > 16 {
+						line = line[:16]
+					}
+					items := make([]string, len(line))
+					for j, b := range line {
+						items[j] = fmt.Sprintf("0x%02X,", b)
+					}
+					fmt.Println(strings.Join(items, " "))
+				}
+				return nil
+			},
+		},
 		{
 			Name:      "serve",
 			Aliases:   []string{"s"},
@@ -119,20 +173,94 @@ func main() {
 					Name:  "write-trace, w",
 					Usage: "Write trace to a `file`",
 				},
+				cli.StringFlag{
+					Name:  "transport, t",
+					Value: "hid",
+					Usage: "Device transport: hid or serial",
+				},
+				cli.IntFlag{
+					Name:  "baud, b",
+					Value: serial.BaudRate57600,
+					Usage: "Baud `rate` of the serial transport",
+				},
+				cli.StringFlag{
+					Name:  "flush",
+					Value: "packet",
+					Usage: "Send a frame per packet or per handled frame: packet or frame",
+				},
+				cli.StringFlag{
+					Name:  "report-strategy",
+					Value: "greedy",
+					Usage: "Choose the HID reports of a frame: greedy, min-bytes or min-reports",
+				},
+				cli.StringFlag{
+					Name:  "report-descriptor, r",
+					Usage: "Read the HID reports from a report descriptor `file` i.e. /sys/class/hidraw/hidraw0/device/report_descriptor",
+				},
 			},
 			Action: func(c *cli.Context) error {
 				path := c.Args().First()
 				if path == "" {
 					return UsageError{fmt.Errorf("device path is missing")}
 				}
+				transport := c.String("transport")
+				if transport != "hid" && transport != "serial" {
+					return UsageError{fmt.Errorf("unknown transport: %s", transport)}
+				}
+				var flush ipod.FlushPolicy
+				switch c.String("flush") {
+				case "packet":
+					flush = ipod.FlushPacket
+				case "frame":
+					flush = ipod.FlushFrame
+				default:
+					return UsageError{fmt.Errorf("unknown flush policy: %s", c.String("flush"))}
+				}
+				var strategy hid.ReportStrategy
+				switch c.String("report-strategy") {
+				case "greedy":
+					// the encoder plans greedily without a strategy,
+					// reusing its plan instead of allocating one per frame
+					strategy = nil
+				case "min-bytes":
+					strategy = hid.Memoize(hid.PickMinBytes)
+				case "min-reports":
+					strategy = hid.Memoize(hid.PickMinReports)
+				default:
+					return UsageError{fmt.Errorf("unknown report strategy: %s", c.String("report-strategy"))}
+				}
+				reportDefs := hid.DefaultReportDefs
+				if descPath := c.String("report-descriptor"); descPath != "" {
+					if transport != "hid" {
+						return UsageError{fmt.Errorf("report descriptor requires the hid transport")}
+					}
+					defs, err := hid.ReadReportDescriptor(descPath)
+					le := log.WithField("path", descPath)
+					if err != nil {
+						le.WithError(err).Errorf("could not read the report descriptor")
+						return err
+					}
+					le.WithField("reports", len(defs)).Info("report descriptor loaded")
+					reportDefs = defs
+				}
 				f, err := openDevice(path)
 				le := log.WithField("path", path)
 				if err != nil {
 					le.WithError(err).Errorf("could not open the device")
 					return err
 				}
+				defer f.Close()
 				le.Info("device opened")
 
+				if transport == "serial" {
+					baud := c.Int("baud")
+					if err := serial.Configure(f, baud); err != nil {
+						le.WithError(err).Warningf("could not configure the tty")
+					} else {
+						le.WithField("baud", baud).Info("tty configured")
+					}
+				}
+
 				var rw io.ReadWriter = f
 				if tracePath := c.String("write-trace"); tracePath != "" {
 					traceFile, err := newTraceFile(tracePath)
@@ -141,13 +269,23 @@ func main() {
 						le.WithError(err).Errorf("could not create a trace file")
 						return err
 					}
+					defer traceFile.Close()
 					le.Warningf("writing trace")
 					rw = trace.NewTracer(traceFile, f)
 				}
 
-				reportR, reportW := hid.NewReportReader(rw), hid.NewReportWriter(rw)
-				frameTransport := hid.NewTransport(reportR, reportW, hid.DefaultReportDefs)
-				processFrames(frameTransport)
+				var frameTransport ipod.FrameReadWriter
+				switch transport {
+				case "serial":
+					frameTransport = serial.NewTransport(rw)
+				default:
+					reportR, reportW := hid.NewReportReader(rw), hid.NewReportWriter(rw)
+					t := hid.NewTransport(reportR, reportW, reportDefs)
+					t.Dropped = logDropped
+					t.Strategy = strategy
+					frameTransport = t
+				}
+				serve(ctx, frameTransport, flush)
 				return nil
 			},
 		},
@@ -167,13 +305,15 @@ func main() {
 					le.WithError(err).Errorf("could not open the trace file")
 					return err
 				}
+				defer f.Close()
 				le.Warningf("trace file opened")
 
 				tr := trace.NewReader(f)
 				tdr := trace.NewTraceDirReader(tr, trace.DirIn)
 				reportR, reportW := hid.NewReportReader(tdr), hid.NewReportWriter(ioutil.Discard)
 				frameTransport := hid.NewTransport(reportR, reportW, hid.DefaultReportDefs)
-				processFrames(frameTransport)
+				frameTransport.Dropped = logDropped
+				serve(ctx, frameTransport, ipod.FlushPacket)
 				return nil
 			},
 		},
@@ -181,11 +321,21 @@ func main() {
 			Name:    "view",
 			Aliases: []string{"v"},
 			Usage:   "view a trace file",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "json",
+					Usage: "write the decoded commands to stdout as JSON lines",
+				},
+			},
 			Action: func(c *cli.Context) error {
 				path := c.Args().First()
 				if path == "" {
 					return UsageError{cli.NewExitError("trace file path is missing", 1)}
 				}
+				if c.Bool("json") {
+					// keep stdout for the commands
+					log.Out = os.Stderr
+				}
 
 				f, err := openTraceFile(path)
 				le := log.WithField("path", path)
@@ -193,48 +343,90 @@ func main() {
 					le.WithError(err).Errorf("could not open the trace file")
 					return err
 				}
+				defer f.Close()
 				le.Warningf("trace file opened")
 				tr := trace.NewReader(f)
-				dumpTrace(tr)
+				var jsonOut *json.Encoder
+				if c.Bool("json") {
+					jsonOut = json.NewEncoder(os.Stdout)
+					jsonOut.SetEscapeHTML(false)
+				}
+				dumpTrace(tr, jsonOut)
 				return nil
 			},
 		},
 		{
-			Name: "send",
+			Name:      "send",
+			ArgsUsage: "<dev> [trace]",
 			Flags: []cli.Flag{
 				cli.StringFlag{
 					Name:  "write-trace, w",
 					Usage: "Write trace to a `file`",
 				},
+				cli.StringSliceFlag{
+					Name:  "command, c",
+					Usage: "Send a `command` i.e. \"extremote.PlayControl Cmd=Toggle\"",
+				},
+				cli.StringFlag{
+					Name:  "script, s",
+					Usage: "Send the commands of a script `file`, a command per line, - reads the terminal",
+				},
 			},
-			Usage: "acc mode / send accessory requests from a trace file",
+			Usage: "acc mode / send accessory requests from a trace file or commands",
 			Action: func(c *cli.Context) error {
 				path := c.Args().Get(0)
 				if path == "" {
 					return UsageError{fmt.Errorf("device path is missing")}
 				}
+
+				var cmds []*ipod.Command
+				for _, text := range c.StringSlice("command") {
+					cmd, err := ipod.ParseCommand(text)
+					if err != nil {
+						return UsageError{err}
+					}
+					cmds = append(cmds, cmd)
+				}
+				script := c.String("script")
+				if script != "" && script != "-" {
+					sf, err := os.Open(script)
+					if err != nil {
+						return err
+					}
+					scriptCmds, err := parseScript(sf)
+					sf.Close()
+					if err != nil {
+						return fmt.Errorf("script %s: %v", script, err)
+					}
+					cmds = append(cmds, scriptCmds...)
+				}
+				tpath := c.Args().Get(1)
+				if tpath == "" && len(cmds) == 0 && script != "-" {
+					return UsageError{cli.NewExitError("trace file path or commands are missing", 1)}
+				}
+
 				f, err := openDevice(path)
 				le := log.WithField("path", path)
 				if err != nil {
 					le.WithError(err).Errorf("could not open the device")
 					return err
 				}
+				defer f.Close()
 				le.Info("device opened")
 
-				tpath := c.Args().Get(1)
-				if tpath == "" {
-					return UsageError{cli.NewExitError("trace file path is missing", 1)}
-				}
-
-				tf, err := openTraceFile(tpath)
-				tle := log.WithField("path", tpath)
-				if err != nil {
-					tle.WithError(err).Errorf("could not open the trace file")
-					return err
+				var traceR hid.ReportReader
+				if tpath != "" {
+					tf, err := openTraceFile(tpath)
+					tle := log.WithField("path", tpath)
+					if err != nil {
+						tle.WithError(err).Errorf("could not open the trace file")
+						return err
+					}
+					defer tf.Close()
+					tle.Warningf("trace file opened")
+					tr := trace.NewReader(tf)
+					traceR = hid.NewReportReader(trace.NewTraceDirReader(tr, trace.DirIn))
 				}
-				tle.Warningf("trace file opened")
-				tr := trace.NewReader(tf)
-				tdr := trace.NewTraceDirReader(tr, trace.DirIn)
 
 				var rw io.ReadWriter = f
 				if tracePath := c.String("write-trace"); tracePath != "" {
@@ -244,18 +436,34 @@ func main() {
 						le.WithError(err).Errorf("could not create a trace file")
 						return err
 					}
+					defer traceFile.Close()
 					le.Warningf("writing trace")
 					rw = trace.NewTracer(traceFile, f)
 				}
 				reportR, reportW := hid.NewReportReader(rw), hid.NewReportWriter(rw)
 				dummyW := hid.NewReportWriter(ioutil.Discard)
-				traceR := hid.NewReportReader(tdr)
 
 				frameTransport := hid.NewTransport(reportR, dummyW, hid.DefaultReportDefs)
+				frameTransport.Dropped = logDropped
+
+				done := make(chan struct{})
+				go func() {
+					serve(ctx, frameTransport, ipod.FlushPacket)
+					close(done)
+				}()
+
+				// pause waits between the requests,
+				// it returns false once the context is done
+				pause := func() bool {
+					select {
+					case <-ctx.Done():
+						return false
+					case <-time.After(1000 * time.Millisecond):
+						return true
+					}
+				}
 
-				go processFrames(frameTransport)
-
-				for {
+				for traceR != nil {
 					report, err := traceR.ReadReport()
 					if err != nil {
 						break
@@ -263,12 +471,36 @@ func main() {
 
 					reportW.WriteReport(report)
 					log.Infof("writing report\n%s", spew.Sdump(report))
+					if !pause() {
+						break
+					}
+				}
 
-					time.Sleep(1000 * time.Millisecond)
+				sender := newCommandSender(reportW)
+				for _, cmd := range cmds {
+					logCmd(cmd, sender.send(cmd), "SEND CMD")
+					if !pause() {
+						break
+					}
 				}
 
-				select {}
+				if script == "-" {
+					typed := readCommands(ctx, os.Stdin)
+				interactive:
+					for {
+						select {
+						case <-ctx.Done():
+							break interactive
+						case cmd, ok := <-typed:
+							if !ok {
+								break interactive
+							}
+							logCmd(cmd, sender.send(cmd), "SEND CMD")
+						}
+					}
+				}
 
+				<-done
 				return nil
 			},
 		},
@@ -292,6 +524,10 @@ func logFrame(frame []byte, err error, msg string) {
 
 }
 
+func logDropped(partial []byte, err error) {
+	log.WithField("len", len(partial)).WithError(err).Warn("dropped a partial frame")
+}
+
 func logPacket(pkt []byte, err error, msg string) {
 	//le := PacketLogEntry(logrus.NewEntry(log), frame)
 	le := log.WithField("len", len(pkt))
@@ -313,90 +549,85 @@ func logCmd(cmd *ipod.Command, err error, msg string) {
 	}
 	le.Infof(msg)
 	if log.Level == logrus.DebugLevel {
-		spew.Fdump(log.Out, cmd)
+		fmt.Fprintln(log.Out, ipod.FormatPayload(cmd.Payload))
 	}
 
 }
 
-func processFrames(frameTransport ipod.FrameReadWriter) {
-	outFrameBuf := bytes.Buffer{}
-	outFrameBuf.Grow(1024)
-	for {
-		inFrame, err := frameTransport.ReadFrame()
-		if err == io.EOF {
-			break
-		}
-		logFrame(inFrame, err, "<< FRAME")
-		if err != nil {
-			continue
-		}
-
-		packetReader := ipod.NewPacketReader(bytes.NewReader(inFrame))
-		inCmdBuf := ipod.CmdBuffer{}
-		for {
-			inPacket, err := packetReader.ReadPacket()
-			if err == io.EOF {
-				break
-			}
-			logPacket(inPacket, err, "<< PACKET")
-			if err != nil {
-				continue
-			}
-
-			var inCmd ipod.Command
-			inCmdErr := inCmd.UnmarshalBinary(inPacket)
-			logCmd(&inCmd, inCmdErr, "<< CMD")
-			inCmdBuf.WriteCommand(&inCmd)
-		}
-
-		outCmdBuf := ipod.CmdBuffer{}
-		for i := range inCmdBuf.Commands {
-			//todo: check return error
-			handlePacket(&outCmdBuf, inCmdBuf.Commands[i])
-		}
-
-		for i := range outCmdBuf.Commands {
-			outCmd := outCmdBuf.Commands[i]
-			logCmd(outCmd, nil, ">> CMD")
-
-			outPacket, err := outCmd.MarshalBinary()
-			logPacket(outPacket, err, ">> PACKET")
-
-			outFrameBuf.Reset()
-			packetWriter := ipod.NewPacketWriter(&outFrameBuf)
-			packetWriter.WritePacket(outPacket)
-			outFrame := outFrameBuf.Bytes()
-			outFrameErr := frameTransport.WriteFrame(outFrame)
-			logFrame(outFrame, outFrameErr, ">> FRAME")
-		}
-
+func logDirPrefix(dir ipod.Dir, text string) string {
+	switch dir {
+	case ipod.DirIn:
+		return "<< " + text
+	case ipod.DirOut:
+		return ">> " + text
+	default:
+		return "?? " + text
 	}
-	log.Warnf("EOF")
 }
 
-var devGeneral = &DevGeneral{}
+func newSession(frameTransport ipod.FrameReadWriter) *ipod.Session {
+	s := ipod.NewSession(frameTransport)
+	s.Hooks = ipod.Hooks{
+		Frame: func(dir ipod.Dir, frame []byte, err error) {
+			logFrame(frame, err, logDirPrefix(dir, "FRAME"))
+		},
+		Packet: func(dir ipod.Dir, pkt []byte, err error) {
+			logPacket(pkt, err, logDirPrefix(dir, "PACKET"))
+		},
+		Command: func(dir ipod.Dir, cmd *ipod.Command, err error) {
+			logCmd(cmd, err, logDirPrefix(dir, "CMD"))
+		},
+		HandlerError: func(cmd *ipod.Command, err error) {
+			CommandLogEntry(logrus.NewEntry(log), cmd).WithError(err).Warn("handler failed")
+		},
+		TransactionError: func(cmd *ipod.Command, err error) {
+			CommandLogEntry(logrus.NewEntry(log), cmd).WithError(err).Warn("bad transaction")
+		},
+		TeardownError: func(lingoID uint8, err error) {
+			log.WithField("lingo", lingoID).WithError(err).Warn("teardown failed")
+		},
+	}
 
-func handlePacket(cmdWriter ipod.CommandWriter, cmd *ipod.Command) {
-	switch cmd.ID.LingoID() {
-	case general.LingoGeneralID:
+	devGeneral := &DevGeneral{}
+	s.LingoVersion = devGeneral.LingoProtocolVersion
+	s.HandleFunc(general.LingoGeneralID, func(cmd *ipod.Command, w ipod.CommandWriter) error {
 		if auth, ok := cmd.Payload.(*general.RetDevAuthenticationInfo); ok {
 			if auth.Major >= 2 && auth.CertCurrentSection == auth.CertMaxSection || auth.Major < 2 {
-				audio.Start(cmdWriter)
+				audio.Start(w)
 			}
 		}
-		general.HandleGeneral(cmd, cmdWriter, devGeneral)
-
-	case simpleremote.LingoSimpleRemotelID:
+		return general.HandleGeneral(cmd, w, devGeneral)
+	})
+	s.HandleTeardown(general.LingoGeneralID, func(w ipod.CommandWriter) error {
+		return general.Teardown(w, devGeneral)
+	})
+	s.HandleFunc(simpleremote.LingoSimpleRemotelID, func(cmd *ipod.Command, w ipod.CommandWriter) error {
 		//todo
 		log.Warn("Lingo SimpleRemote is not supported yet")
-	case dispremote.LingoDisplayRemoteID:
-		dispremote.HandleDispRemote(cmd, cmdWriter, nil)
-	case extremote.LingoExtRemotelID:
-		extremote.HandleExtRemote(cmd, cmdWriter, nil)
-	case audio.LingoAudioID:
-		audio.HandleAudio(cmd, cmdWriter, nil)
+		return nil
+	})
+	s.HandleFunc(dispremote.LingoDisplayRemoteID, func(cmd *ipod.Command, w ipod.CommandWriter) error {
+		return dispremote.HandleDispRemote(cmd, w, nil)
+	})
+	s.HandleFunc(extremote.LingoExtRemotelID, func(cmd *ipod.Command, w ipod.CommandWriter) error {
+		return extremote.HandleExtRemote(cmd, w, nil)
+	})
+	s.HandleFunc(audio.LingoAudioID, func(cmd *ipod.Command, w ipod.CommandWriter) error {
+		return audio.HandleAudio(cmd, w, nil)
+	})
+	return s
+}
+
+func serve(ctx context.Context, frameTransport ipod.FrameReadWriter, flush ipod.FlushPolicy) {
+	s := newSession(frameTransport)
+	s.FlushPolicy = flush
+	if err := s.ServeContext(ctx); err != nil {
+		PacketStatsLogEntry(logrus.NewEntry(log), s.Stats()).WithError(err).Warnf("stopped")
+		return
 	}
+	PacketStatsLogEntry(logrus.NewEntry(log), s.Stats()).Warnf("EOF")
 }
+
 func dirPrefix(dir trace.Dir, text string) string {
 	switch dir {
 	case trace.DirIn:
@@ -407,7 +638,15 @@ func dirPrefix(dir trace.Dir, text string) string {
 		return "?? " + text
 	}
 }
-func dumpTrace(tr *trace.Reader) {
+
+// jsonCommand is a line of the JSON output of view
+type jsonCommand struct {
+	Dir trace.Dir     `json:"dir"`
+	Cmd *ipod.Command `json:"cmd"`
+}
+
+func dumpTrace(tr *trace.Reader, jsonOut *json.Encoder) {
+	var stats ipod.PacketStats
 	q := trace.Queue{}
 	for {
 		var msg trace.Msg
@@ -429,6 +668,7 @@ func dumpTrace(tr *trace.Reader) {
 		dir := head.Dir
 		tdr := trace.NewQueueDirReader(&q, dir)
 		d := hid.NewDecoderDefault(hid.NewReportReader(tdr))
+		d.Dropped = logDropped
 
 		frame, err := d.ReadFrame()
 		if err == io.EOF {
@@ -453,7 +693,13 @@ func dumpTrace(tr *trace.Reader) {
 			var cmd ipod.Command
 			cmdErr := cmd.UnmarshalBinary(packet)
 			logCmd(&cmd, cmdErr, dirPrefix(dir, "CMD"))
+			if jsonOut != nil && cmdErr == nil {
+				if err := jsonOut.Encode(jsonCommand{dir, &cmd}); err != nil {
+					log.WithError(err).Error("could not encode the command")
+				}
+			}
 		}
+		stats.Add(packetReader.Stats())
 	}
-	log.Warnf("EOF")
+	PacketStatsLogEntry(logrus.NewEntry(log), stats).Warnf("EOF")
 }
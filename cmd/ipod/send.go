@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/oandrew/ipod"
+	"github.com/oandrew/ipod/hid"
+)
+
+// parseScript parses a command per line in the text syntax of ipod.ParseCommand,
+// empty lines and # comments are skipped
+func parseScript(r io.Reader) ([]*ipod.Command, error) {
+	var cmds []*ipod.Command
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		if isBlankLine(sc.Text()) {
+			continue
+		}
+		cmd, err := ipod.ParseCommand(sc.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		cmds = append(cmds, cmd)
+	}
+	return cmds, sc.Err()
+}
+
+func isBlankLine(line string) bool {
+	line = strings.TrimSpace(line)
+	return line == "" || strings.HasPrefix(line, "#")
+}
+
+// readCommands parses the lines typed in a terminal until EOF
+// or the context is done, lines that fail to parse are logged and skipped
+func readCommands(ctx context.Context, r io.Reader) <-chan *ipod.Command {
+	cmds := make(chan *ipod.Command)
+	go func() {
+		defer close(cmds)
+		sc := bufio.NewScanner(r)
+		for sc.Scan() {
+			if isBlankLine(sc.Text()) {
+				continue
+			}
+			cmd, err := ipod.ParseCommand(sc.Text())
+			if err != nil {
+				log.WithError(err).Error("could not parse the command")
+				continue
+			}
+			select {
+			case cmds <- cmd:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return cmds
+}
+
+// commandSender sends commands as an accessory
+// through the packet and HID encoders, reusing its buffers
+type commandSender struct {
+	enc   *hid.Encoder
+	pkt   []byte
+	frame bytes.Buffer
+	pw    *ipod.PacketWriter
+}
+
+func newCommandSender(w hid.ReportWriter) *commandSender {
+	s := &commandSender{
+		enc: hid.NewEncoderDir(w, hid.DefaultReportDefs, hid.ReportDirAccOut),
+	}
+	s.pw = ipod.NewPacketWriter(&s.frame)
+	return s
+}
+
+func (s *commandSender) send(cmd *ipod.Command) error {
+	pkt, err := cmd.AppendBinary(s.pkt[:0])
+	if err != nil {
+		return err
+	}
+	s.pkt = pkt
+	s.frame.Reset()
+	if err := s.pw.WritePacket(pkt); err != nil {
+		return err
+	}
+	return s.enc.WriteFrame(s.frame.Bytes())
+}
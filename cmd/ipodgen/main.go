@@ -0,0 +1,430 @@
+// Command ipodgen generates reflection-free MarshalBinary/AppendBinary/UnmarshalBinary
+// methods and a static payload table for the commands of a lingo package.
+//
+// It reads the struct that lists the lingo commands, i.e.
+//
+//	var Lingos struct {
+//		ACK          `id:"0x00"`
+//		RequestThing `id:"0x01"`
+//	}
+//
+// and for every fixed-size payload type without its own
+// MarshalBinary/UnmarshalBinary emits the encoder and decoder.
+// Variable-size payloads keep the reflection based encoding.
+// The table is registered with ipod.RegisterPayloads.
+//
+// Several comma-separated vars can be given, i.e. the layouts
+// of a later protocol version, each gets a table of its own
+// named after the var, i.e. lingosV1_04Payloads for LingosV1_04.
+//
+// Usage:
+//
+//	//go:generate go run ../cmd/ipodgen
+//	//go:generate go run ../cmd/ipodgen -lingos=Lingos,LingosV1_04
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	lingosVars = flag.String("lingos", "Lingos", "comma-separated names of the vars listing the lingo commands")
+	output     = flag.String("output", "lingos_binary.go", "output file name")
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("ipodgen: ")
+	flag.Parse()
+
+	dir := "."
+	if args := flag.Args(); len(args) > 0 {
+		dir = args[0]
+	}
+
+	src, err := generate(dir, strings.Split(*lingosVars, ","), filepath.Base(*output))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, *output), src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func generate(dir string, lingosVars []string, output string) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		name := fi.Name()
+		return !strings.HasSuffix(name, "_test.go") && name != output
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, found %d", dir, len(pkgs))
+	}
+	var pkg *ast.Package
+	for _, p := range pkgs {
+		pkg = p
+	}
+
+	g := newGenerator(pkg)
+	var tables []table
+	for _, lingosVar := range lingosVars {
+		cmds, err := g.lingoCommands(lingosVar)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, table{lingosVar: lingosVar, cmds: cmds})
+	}
+	return g.generate(tables)
+}
+
+// table is the payload table of a var listing lingo commands
+type table struct {
+	lingosVar string
+	cmds      []command
+}
+
+type command struct {
+	id       string
+	when     string
+	typeName string
+}
+
+type generator struct {
+	pkgName string
+	types   map[string]ast.Expr
+	methods map[string]map[string]bool
+	vars    map[string]*ast.ValueSpec
+
+	imports map[string]bool
+	buf     bytes.Buffer
+}
+
+func newGenerator(pkg *ast.Package) *generator {
+	g := &generator{
+		pkgName: pkg.Name,
+		types:   make(map[string]ast.Expr),
+		methods: make(map[string]map[string]bool),
+		vars:    make(map[string]*ast.ValueSpec),
+		imports: make(map[string]bool),
+	}
+	for _, f := range pkg.Files {
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						g.types[s.Name.Name] = s.Type
+					case *ast.ValueSpec:
+						for _, name := range s.Names {
+							g.vars[name.Name] = s
+						}
+					}
+				}
+			case *ast.FuncDecl:
+				if d.Recv == nil || len(d.Recv.List) != 1 {
+					continue
+				}
+				recv := d.Recv.List[0].Type
+				if star, ok := recv.(*ast.StarExpr); ok {
+					recv = star.X
+				}
+				if id, ok := recv.(*ast.Ident); ok {
+					if g.methods[id.Name] == nil {
+						g.methods[id.Name] = make(map[string]bool)
+					}
+					g.methods[id.Name][d.Name.Name] = true
+				}
+			}
+		}
+	}
+	return g
+}
+
+func (g *generator) lingoCommands(lingosVar string) ([]command, error) {
+	spec, ok := g.vars[lingosVar]
+	if !ok {
+		return nil, fmt.Errorf("var %s not found", lingosVar)
+	}
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return nil, fmt.Errorf("var %s is not a struct", lingosVar)
+	}
+	var cmds []command
+	for _, f := range st.Fields.List {
+		id, ok := f.Type.(*ast.Ident)
+		if len(f.Names) != 0 || !ok || f.Tag == nil {
+			return nil, fmt.Errorf("%s: fields must be embedded payload types with an id tag", lingosVar)
+		}
+		tag, err := strconv.Unquote(f.Tag.Value)
+		if err != nil {
+			return nil, err
+		}
+		cmdID := reflect.StructTag(tag).Get("id")
+		if _, err := strconv.ParseUint(cmdID, 0, 16); err != nil {
+			return nil, fmt.Errorf("%s.%s: parse id tag err: %v", lingosVar, id.Name, err)
+		}
+		when := reflect.StructTag(tag).Get("when")
+		cmds = append(cmds, command{id: cmdID, when: when, typeName: id.Name})
+	}
+	return cmds, nil
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) generate(tables []table) ([]byte, error) {
+	body := bytes.Buffer{}
+	sizes := make(map[string]int)
+	for _, t := range tables {
+		for _, cmd := range t.cmds {
+			if _, ok := sizes[cmd.typeName]; ok {
+				continue
+			}
+			sizes[cmd.typeName] = -1
+			if m := g.methods[cmd.typeName]; m["MarshalBinary"] || m["UnmarshalBinary"] {
+				continue
+			}
+			c, ok := g.codec(cmd.typeName)
+			if !ok {
+				continue
+			}
+			sizes[cmd.typeName] = c.size
+			c.write(&body, cmd.typeName)
+			for imp := range c.imports {
+				g.imports[imp] = true
+			}
+		}
+	}
+
+	g.printf("// Code generated by \"ipodgen\"; DO NOT EDIT.\n\n")
+	g.printf("package %s\n\n", g.pkgName)
+	var imports []string
+	for imp := range g.imports {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	g.printf("import (\n")
+	for _, imp := range imports {
+		g.printf("\t%q\n", imp)
+	}
+	g.printf("\n\t\"github.com/oandrew/ipod\"\n)\n\n")
+
+	for _, t := range tables {
+		tableName := strings.ToLower(t.lingosVar[:1]) + t.lingosVar[1:] + "Payloads"
+		g.printf("// %s is the static payload table of %s\n", tableName, t.lingosVar)
+		g.printf("var %s = []ipod.PayloadDef{\n", tableName)
+		for _, cmd := range t.cmds {
+			g.printf("\t{ID: %s, Size: %d, New: func() interface{} { return &%s{} }", cmd.id, sizes[cmd.typeName], cmd.typeName)
+			if cmd.when != "" {
+				g.printf(", When: %q", cmd.when)
+			}
+			g.printf("},\n")
+		}
+		g.printf("}\n\n")
+	}
+	g.buf.Write(body.Bytes())
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format: %v\n%s", err, g.buf.Bytes())
+	}
+	return src, nil
+}
+
+// codec accumulates the statements of the encoder and the decoder
+// of a fixed-size type
+type codec struct {
+	g       *generator
+	size    int
+	enc     []string
+	dec     []string
+	imports map[string]bool
+}
+
+func (g *generator) codec(typeName string) (*codec, bool) {
+	c := &codec{g: g, imports: make(map[string]bool)}
+	path := "p"
+	if _, ok := g.types[typeName].(*ast.StructType); !ok {
+		path = "(*p)"
+	}
+	if !c.field(path, ast.NewIdent(typeName), 0) {
+		return nil, false
+	}
+	return c, true
+}
+
+var basicSizes = map[string]int{
+	"bool":   1,
+	"byte":   1,
+	"uint8":  1,
+	"int8":   1,
+	"uint16": 2,
+	"int16":  2,
+	"uint32": 4,
+	"int32":  4,
+	"uint64": 8,
+	"int64":  8,
+}
+
+// field adds the code for the value at path of type typ
+func (c *codec) field(path string, typ ast.Expr, depth int) bool {
+	if depth > 8 {
+		return false
+	}
+	switch t := typ.(type) {
+	case *ast.Ident:
+		if _, ok := basicSizes[t.Name]; ok {
+			c.basic(path, t.Name, t.Name)
+			return true
+		}
+		underlying, ok := c.g.types[t.Name]
+		if !ok {
+			return false
+		}
+		if u, ok := underlying.(*ast.Ident); ok {
+			if _, ok := basicSizes[u.Name]; ok {
+				c.basic(path, t.Name, u.Name)
+				return true
+			}
+		}
+		return c.field(path, underlying, depth+1)
+	case *ast.StructType:
+		for _, f := range t.Fields.List {
+			if len(f.Names) == 0 {
+				return false
+			}
+			// tagged fields are left to the ipod codec
+			if f.Tag != nil && strings.Contains(f.Tag.Value, "ipod:") {
+				return false
+			}
+			for _, name := range f.Names {
+				if name.Name == "_" {
+					n, ok := c.g.size(f.Type, 0)
+					if !ok {
+						return false
+					}
+					c.size += n
+					continue
+				}
+				if !c.field(path+"."+name.Name, f.Type, depth+1) {
+					return false
+				}
+			}
+		}
+		return true
+	case *ast.ArrayType:
+		n, ok := arrayLen(t)
+		if !ok {
+			return false
+		}
+		if elem, ok := t.Elt.(*ast.Ident); ok && (elem.Name == "byte" || elem.Name == "uint8") {
+			c.enc = append(c.enc, fmt.Sprintf("copy(b[%d:%d], %s[:])", c.size, c.size+n, path))
+			c.dec = append(c.dec, fmt.Sprintf("copy(%s[:], data[%d:%d])", path, c.size, c.size+n))
+			c.size += n
+			return true
+		}
+		for i := 0; i < n; i++ {
+			if !c.field(fmt.Sprintf("%s[%d]", path, i), t.Elt, depth+1) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *codec) basic(path, typeName, basicName string) {
+	off := c.size
+	c.size += basicSizes[basicName]
+
+	if typeName == "uint8" {
+		typeName = "byte"
+	}
+	switch basicName {
+	case "bool":
+		c.enc = append(c.enc, fmt.Sprintf("if %s {\nb[%d] = 1\n}", path, off))
+		c.dec = append(c.dec, fmt.Sprintf("%s = %s", path, convert(typeName, "bool", fmt.Sprintf("data[%d] != 0", off))))
+	case "byte", "uint8", "int8":
+		c.enc = append(c.enc, fmt.Sprintf("b[%d] = %s", off, convert("byte", typeName, path)))
+		c.dec = append(c.dec, fmt.Sprintf("%s = %s", path, convert(typeName, "byte", fmt.Sprintf("data[%d]", off))))
+	default:
+		wire := "uint" + strconv.Itoa(basicSizes[basicName]*8)
+		fn := strings.Title(wire)
+		c.imports["encoding/binary"] = true
+		c.enc = append(c.enc, fmt.Sprintf("binary.BigEndian.Put%s(b[%d:], %s)", fn, off, convert(wire, typeName, path)))
+		c.dec = append(c.dec, fmt.Sprintf("%s = %s", path, convert(typeName, wire, fmt.Sprintf("binary.BigEndian.%s(data[%d:])", fn, off))))
+	}
+}
+
+// convert returns the expression x of type from converted to the type to
+func convert(to, from, x string) string {
+	if to == from {
+		return x
+	}
+	return to + "(" + x + ")"
+}
+
+// size returns the wire size of a type without generating code
+func (g *generator) size(typ ast.Expr, depth int) (int, bool) {
+	c := &codec{g: g, imports: make(map[string]bool)}
+	if !c.field("_", typ, depth) {
+		return 0, false
+	}
+	return c.size, true
+}
+
+func arrayLen(t *ast.ArrayType) (int, bool) {
+	lit, ok := t.Len.(*ast.BasicLit)
+	if !ok || lit.Kind != token.INT {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(lit.Value, 0, 32)
+	return int(n), err == nil
+}
+
+func (c *codec) write(w *bytes.Buffer, typeName string) {
+	if c.size == 0 {
+		fmt.Fprintf(w, "func (*%s) MarshalBinary() ([]byte, error) {\nreturn nil, nil\n}\n\n", typeName)
+		fmt.Fprintf(w, "func (*%s) AppendBinary(dst []byte) ([]byte, error) {\nreturn dst, nil\n}\n\n", typeName)
+		fmt.Fprintf(w, "func (*%s) UnmarshalBinary(data []byte) error {\nreturn nil\n}\n\n", typeName)
+		return
+	}
+	c.imports["io"] = true
+
+	fmt.Fprintf(w, "func (p *%s) MarshalBinary() ([]byte, error) {\n", typeName)
+	fmt.Fprintf(w, "return p.AppendBinary(make([]byte, 0, %d))\n}\n\n", c.size)
+
+	fmt.Fprintf(w, "func (p *%s) AppendBinary(dst []byte) ([]byte, error) {\n", typeName)
+	fmt.Fprintf(w, "n := len(dst)\ndst = append(dst, make([]byte, %d)...)\nb := dst[n:]\n", c.size)
+	for _, s := range c.enc {
+		fmt.Fprintln(w, s)
+	}
+	fmt.Fprintf(w, "return dst, nil\n}\n\n")
+
+	fmt.Fprintf(w, "func (p *%s) UnmarshalBinary(data []byte) error {\n", typeName)
+	fmt.Fprintf(w, "if len(data) < %d {\nreturn io.ErrUnexpectedEOF\n}\n", c.size)
+	for _, s := range c.dec {
+		fmt.Fprintln(w, s)
+	}
+	fmt.Fprintf(w, "return nil\n}\n\n")
+}
@@ -2,12 +2,18 @@ package ipod_test
 
 import (
 	"bytes"
+	"encoding"
 	"encoding/binary"
+	"math/rand"
 	"reflect"
 	"testing"
 
 	"github.com/oandrew/ipod"
 	"github.com/oandrew/ipod/lingo-audio"
+	"github.com/oandrew/ipod/lingo-dispremote"
+	"github.com/oandrew/ipod/lingo-extremote"
+	"github.com/oandrew/ipod/lingo-general"
+	"github.com/oandrew/ipod/lingo-simpleremote"
 )
 
 type CustomPayload struct {
@@ -56,24 +62,25 @@ func TestCommand_MarshalBinary(t *testing.T) {
 	}
 }
 
+var commandUnmarshalTests = []struct {
+	name    string
+	data    []byte
+	want    ipod.Command
+	wantErr bool
+}{
+	{"no-tr-unknown-payload", []byte{0xee, 0x01}, ipod.Command{ipod.NewLingoCmdID(0xee, 0x01), nil, ipod.UnknownPayload{}}, true},
+	{"with-tr-unknown-payload", []byte{0xee, 0x01, 0x00, 0x03}, ipod.Command{ipod.NewLingoCmdID(0xee, 0x01), nil, ipod.UnknownPayload{0x00, 0x03}}, true},
+	{"no-tr-known-payload", []byte{0xaa, 0x01, 0x00, 0x00, 0x00, 0x03}, ipod.Command{ipod.NewLingoCmdID(0xaa, 0x01), nil, &CustomPayload{V: 0x03}}, false},
+	{"with-tr-known-payload", []byte{0xaa, 0x01, 0x00, 0x02, 0x00, 0x00, 0x00, 0x03}, ipod.Command{ipod.NewLingoCmdID(0xaa, 0x01), ipod.NewTransaction(0x02), &CustomPayload{V: 0x03}}, false},
+
+	{"no-tr-known-payload-short", []byte{0xaa, 0x01, 0x00, 0x00, 0x03}, ipod.Command{ipod.NewLingoCmdID(0xaa, 0x01), ipod.NewTransaction(0x00), nil}, true},
+	{"with-tr-known-payload-short", []byte{0xaa, 0x01, 0x00, 0x02, 0x00, 0x00, 0x03}, ipod.Command{ipod.NewLingoCmdID(0xaa, 0x01), ipod.NewTransaction(0x02), nil}, true},
+}
+
 func TestCommand_UnmarshalBinary(t *testing.T) {
 	ipod.RegisterLingos(TestLingoID, TestLingos)
 
-	tests := []struct {
-		name    string
-		data    []byte
-		want    ipod.Command
-		wantErr bool
-	}{
-		{"no-tr-unknown-payload", []byte{0xee, 0x01}, ipod.Command{ipod.NewLingoCmdID(0xee, 0x01), nil, ipod.UnknownPayload{}}, true},
-		{"with-tr-unknown-payload", []byte{0xee, 0x01, 0x00, 0x03}, ipod.Command{ipod.NewLingoCmdID(0xee, 0x01), nil, ipod.UnknownPayload{0x00, 0x03}}, true},
-		{"no-tr-known-payload", []byte{0xaa, 0x01, 0x00, 0x00, 0x00, 0x03}, ipod.Command{ipod.NewLingoCmdID(0xaa, 0x01), nil, &CustomPayload{V: 0x03}}, false},
-		{"with-tr-known-payload", []byte{0xaa, 0x01, 0x00, 0x02, 0x00, 0x00, 0x00, 0x03}, ipod.Command{ipod.NewLingoCmdID(0xaa, 0x01), ipod.NewTransaction(0x02), &CustomPayload{V: 0x03}}, false},
-
-		{"no-tr-known-payload-short", []byte{0xaa, 0x01, 0x00, 0x00, 0x03}, ipod.Command{ipod.NewLingoCmdID(0xaa, 0x01), ipod.NewTransaction(0x00), nil}, true},
-		{"with-tr-known-payload-short", []byte{0xaa, 0x01, 0x00, 0x02, 0x00, 0x00, 0x03}, ipod.Command{ipod.NewLingoCmdID(0xaa, 0x01), ipod.NewTransaction(0x02), nil}, true},
-	}
-	for _, tt := range tests {
+	for _, tt := range commandUnmarshalTests {
 		t.Run(tt.name, func(t *testing.T) {
 			var got ipod.Command
 			if err := got.UnmarshalBinary(tt.data); (err != nil) != tt.wantErr {
@@ -115,3 +122,117 @@ func BenchmarkCommand_UnmarshalBinary(b *testing.B) {
 		cmd.UnmarshalBinary(packet)
 	}
 }
+
+var generatedLingos = []interface{}{
+	audio.Lingos,
+	dispremote.Lingos,
+	extremote.Lingos,
+	general.Lingos,
+	simpleremote.Lingos,
+}
+
+// TestGeneratedCodecs checks the generated encoders
+// against the reflection based ones
+func TestGeneratedCodecs(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, lingos := range generatedLingos {
+		lt := reflect.TypeOf(lingos)
+		for i := 0; i < lt.NumField(); i++ {
+			pt := lt.Field(i).Type
+			size := binary.Size(reflect.Zero(pt).Interface())
+			if size < 0 {
+				continue
+			}
+			t.Run(pt.String(), func(t *testing.T) {
+				data := make([]byte, size)
+				rnd.Read(data)
+
+				want := reflect.New(pt).Interface()
+				if err := binary.Read(bytes.NewReader(data), binary.BigEndian, want); err != nil {
+					t.Fatal(err)
+				}
+				got := reflect.New(pt).Interface()
+				if err := got.(encoding.BinaryUnmarshaler).UnmarshalBinary(data); err != nil {
+					t.Fatalf("UnmarshalBinary() error = %v", err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("UnmarshalBinary() = %#v, want %#v", got, want)
+				}
+
+				wantData := bytes.Buffer{}
+				binary.Write(&wantData, binary.BigEndian, want)
+				gotData, err := got.(encoding.BinaryMarshaler).MarshalBinary()
+				if err != nil {
+					t.Fatalf("MarshalBinary() error = %v", err)
+				}
+				if !bytes.Equal(gotData, wantData.Bytes()) {
+					t.Errorf("MarshalBinary() = %x, want %x", gotData, wantData.Bytes())
+				}
+				prefix := []byte{0xff}
+				appended, err := got.(ipod.BinaryAppender).AppendBinary(prefix)
+				if err != nil {
+					t.Fatalf("AppendBinary() error = %v", err)
+				}
+				if want := append([]byte{0xff}, wantData.Bytes()...); !bytes.Equal(appended, want) {
+					t.Errorf("AppendBinary() = %x, want %x", appended, want)
+				}
+			})
+		}
+	}
+}
+
+// reflectReturnPlayStatus has the layout of extremote.ReturnPlayStatus
+// but none of its methods, so it goes through the reflection path
+type reflectReturnPlayStatus extremote.ReturnPlayStatus
+
+func benchmarkRegistries(b *testing.B) map[string]*ipod.Registry {
+	reflectRegistry := ipod.NewRegistry()
+	err := reflectRegistry.RegisterLingos(extremote.LingoExtRemotelID, struct {
+		reflectReturnPlayStatus `id:"0x001D"`
+	}{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	return map[string]*ipod.Registry{
+		"generated": ipod.DefaultRegistry,
+		"reflect":   reflectRegistry,
+	}
+}
+
+func BenchmarkCommand_MarshalBinary_Codec(b *testing.B) {
+	payloads := map[string]interface{}{
+		"generated": &extremote.ReturnPlayStatus{TrackLength: 180000, TrackPosition: 1000, State: extremote.PlayerStatePlaying},
+		"reflect":   &reflectReturnPlayStatus{TrackLength: 180000, TrackPosition: 1000, State: extremote.PlayerStatePlaying},
+	}
+	for name, r := range benchmarkRegistries(b) {
+		b.Run(name, func(b *testing.B) {
+			cmd, err := r.BuildCommand(payloads[name])
+			if err != nil {
+				b.Fatal(err)
+			}
+			cmd.Transaction = ipod.NewTransaction(0x03e7)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				cmd.MarshalBinary()
+			}
+		})
+	}
+}
+
+func BenchmarkCommand_UnmarshalBinary_Codec(b *testing.B) {
+	packet := []byte{
+		0x04, 0x00, 0x1d, 0x03, 0xe7,
+		0x00, 0x02, 0xbf, 0x20, 0x00, 0x00, 0x03, 0xe8, 0x01,
+	}
+	for name, r := range benchmarkRegistries(b) {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var cmd ipod.Command
+				if err := r.UnmarshalCommand(&cmd, packet, nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package ipod
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Payload struct fields can be tagged to encode variable-length data
+// that binary.Write cannot handle:
+//
+//	ipod:"cstring"      null-terminated string, the field is a string or []byte
+//	ipod:"rest"         the rest of the payload, a string or a slice
+//	ipod:"lenprefix=N"  a string or slice prefixed by its length
+//	                    (number of elements) as a N byte integer, N is 1, 2 or 4
+//	ipod:"optional"     the field may be missing at the end of the payload,
+//	                    a nil pointer or slice is not encoded
+//
+// Options can be combined, i.e. ipod:"cstring,optional".
+// Untagged fields must have a fixed size.
+type fieldOpts struct {
+	cstring   bool
+	rest      bool
+	lenPrefix int
+	optional  bool
+}
+
+func parseFieldTag(tag string) (fieldOpts, error) {
+	var opts fieldOpts
+	if tag == "" {
+		return opts, nil
+	}
+	for _, opt := range strings.Split(tag, ",") {
+		switch {
+		case opt == "cstring":
+			opts.cstring = true
+		case opt == "rest":
+			opts.rest = true
+		case opt == "optional":
+			opts.optional = true
+		case strings.HasPrefix(opt, "lenprefix="):
+			n, err := strconv.Atoi(strings.TrimPrefix(opt, "lenprefix="))
+			if err != nil || (n != 1 && n != 2 && n != 4) {
+				return opts, fmt.Errorf("bad length prefix size: %q", opt)
+			}
+			opts.lenPrefix = n
+		default:
+			return opts, fmt.Errorf("unknown option: %q", opt)
+		}
+	}
+	return opts, nil
+}
+
+var taggedTypes sync.Map
+
+// isTagged reports whether t is a struct with ipod tagged fields
+func isTagged(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	if v, ok := taggedTypes.Load(t); ok {
+		return v.(bool)
+	}
+	tagged := false
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if _, ok := f.Tag.Lookup("ipod"); ok || isTagged(f.Type) {
+			tagged = true
+			break
+		}
+	}
+	taggedTypes.Store(t, tagged)
+	return tagged
+}
+
+// taggedPayload returns the struct value of a payload with tagged fields
+func taggedPayload(payload interface{}) (reflect.Value, bool) {
+	v := reflect.ValueOf(payload)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, isTagged(v.Type())
+}
+
+func marshalTagged(w *bytes.Buffer, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		opts, err := parseFieldTag(f.Tag.Get("ipod"))
+		if err != nil {
+			return fmt.Errorf("field %s: %v", f.Name, err)
+		}
+		if f.Name == "_" {
+			n := binary.Size(reflect.Zero(f.Type).Interface())
+			if n < 0 {
+				return fmt.Errorf("field %s: variable size padding: %v", f.Name, f.Type)
+			}
+			w.Write(make([]byte, n))
+			continue
+		}
+		if f.PkgPath != "" {
+			return fmt.Errorf("field %s: unexported", f.Name)
+		}
+		fv := v.Field(i)
+		switch fv.Kind() {
+		case reflect.Ptr:
+			if fv.IsNil() {
+				if opts.optional {
+					continue
+				}
+				return fmt.Errorf("field %s: nil", f.Name)
+			}
+			fv = fv.Elem()
+		case reflect.Slice:
+			if fv.IsNil() && opts.optional {
+				continue
+			}
+		}
+		if err := marshalField(w, fv, opts); err != nil {
+			return fmt.Errorf("field %s: %v", f.Name, err)
+		}
+	}
+	return nil
+}
+
+func marshalField(w *bytes.Buffer, v reflect.Value, opts fieldOpts) error {
+	switch {
+	case opts.cstring:
+		b, ok := bytesOf(v)
+		if !ok {
+			return fmt.Errorf("cstring: not a string or []byte: %v", v.Type())
+		}
+		if bytes.IndexByte(b, 0x00) >= 0 {
+			return fmt.Errorf("cstring: contains null byte")
+		}
+		w.Write(b)
+		w.WriteByte(0x00)
+		return nil
+	case opts.lenPrefix > 0:
+		if v.Kind() != reflect.String && v.Kind() != reflect.Slice {
+			return fmt.Errorf("lenprefix: not a string or slice: %v", v.Type())
+		}
+		n := v.Len()
+		if uint64(n) >= 1<<(8*uint(opts.lenPrefix)) {
+			return fmt.Errorf("lenprefix: length %d overflows %d bytes", n, opts.lenPrefix)
+		}
+		prefix := make([]byte, 4)
+		binary.BigEndian.PutUint32(prefix, uint32(n))
+		w.Write(prefix[4-opts.lenPrefix:])
+		return marshalSeq(w, v)
+	case opts.rest:
+		return marshalSeq(w, v)
+	case isTagged(v.Type()):
+		return marshalTagged(w, v)
+	default:
+		return binary.Write(w, binary.BigEndian, v.Interface())
+	}
+}
+
+func marshalSeq(w *bytes.Buffer, v reflect.Value) error {
+	if b, ok := bytesOf(v); ok {
+		w.Write(b)
+		return nil
+	}
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("not a string or slice: %v", v.Type())
+	}
+	for i := 0; i < v.Len(); i++ {
+		if err := marshalField(w, v.Index(i), fieldOpts{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func bytesOf(v reflect.Value) ([]byte, bool) {
+	switch {
+	case v.Kind() == reflect.String:
+		return []byte(v.String()), true
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		return v.Bytes(), true
+	}
+	return nil, false
+}
+
+func setBytes(v reflect.Value, b []byte) {
+	if v.Kind() == reflect.String {
+		v.SetString(string(b))
+		return
+	}
+	v.SetBytes(append(make([]byte, 0, len(b)), b...))
+}
+
+// unmarshalTagged decodes data into the addressable struct v
+// and returns the data left
+func unmarshalTagged(data []byte, v reflect.Value) ([]byte, error) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		opts, err := parseFieldTag(f.Tag.Get("ipod"))
+		if err != nil {
+			return data, fmt.Errorf("field %s: %v", f.Name, err)
+		}
+		if len(data) == 0 && opts.optional {
+			continue
+		}
+		if f.Name == "_" {
+			n := binary.Size(reflect.Zero(f.Type).Interface())
+			if n < 0 {
+				return data, fmt.Errorf("field %s: variable size padding: %v", f.Name, f.Type)
+			}
+			if len(data) < n {
+				return data, io.ErrUnexpectedEOF
+			}
+			data = data[n:]
+			continue
+		}
+		if f.PkgPath != "" {
+			return data, fmt.Errorf("field %s: unexported", f.Name)
+		}
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Ptr {
+			fv.Set(reflect.New(f.Type.Elem()))
+			fv = fv.Elem()
+		}
+		if data, err = unmarshalField(data, fv, opts); err != nil {
+			return data, fmt.Errorf("field %s: %v", f.Name, err)
+		}
+	}
+	return data, nil
+}
+
+func unmarshalField(data []byte, v reflect.Value, opts fieldOpts) ([]byte, error) {
+	switch {
+	case opts.cstring:
+		if _, ok := bytesOf(v); !ok {
+			return data, fmt.Errorf("cstring: not a string or []byte: %v", v.Type())
+		}
+		// a missing terminator at the end of the payload is tolerated
+		n := bytes.IndexByte(data, 0x00)
+		if n < 0 {
+			setBytes(v, data)
+			return nil, nil
+		}
+		setBytes(v, data[:n])
+		return data[n+1:], nil
+	case opts.lenPrefix > 0:
+		if len(data) < opts.lenPrefix {
+			return data, io.ErrUnexpectedEOF
+		}
+		prefix := make([]byte, 4)
+		copy(prefix[4-opts.lenPrefix:], data[:opts.lenPrefix])
+		data = data[opts.lenPrefix:]
+		// every element takes at least a byte
+		n := binary.BigEndian.Uint32(prefix)
+		if uint64(n) > uint64(len(data)) {
+			return data, io.ErrUnexpectedEOF
+		}
+		return unmarshalSeq(data, v, int(n))
+	case opts.rest:
+		return unmarshalSeq(data, v, -1)
+	case isTagged(v.Type()):
+		return unmarshalTagged(data, v)
+	default:
+		n := binary.Size(v.Interface())
+		if n < 0 {
+			return data, fmt.Errorf("variable size type without a tag: %v", v.Type())
+		}
+		if len(data) < n {
+			return data, io.ErrUnexpectedEOF
+		}
+		if err := binary.Read(bytes.NewReader(data[:n]), binary.BigEndian, v.Addr().Interface()); err != nil {
+			return data, err
+		}
+		return data[n:], nil
+	}
+}
+
+// unmarshalSeq decodes count elements or all of data if count is negative
+func unmarshalSeq(data []byte, v reflect.Value, count int) ([]byte, error) {
+	if _, ok := bytesOf(v); ok {
+		if count < 0 {
+			count = len(data)
+		}
+		if len(data) < count {
+			return data, io.ErrUnexpectedEOF
+		}
+		setBytes(v, data[:count])
+		return data[count:], nil
+	}
+	if v.Kind() != reflect.Slice {
+		return data, fmt.Errorf("not a string or slice: %v", v.Type())
+	}
+	elems := reflect.MakeSlice(v.Type(), 0, 0)
+	for i := 0; count < 0 && len(data) > 0 || i < count; i++ {
+		elem := reflect.New(v.Type().Elem()).Elem()
+		left, err := unmarshalField(data, elem, fieldOpts{})
+		if err != nil {
+			return left, err
+		}
+		// an element that takes no data would never end the rest of the payload
+		if count < 0 && len(left) == len(data) {
+			return data, fmt.Errorf("zero size element: %v", elem.Type())
+		}
+		data = left
+		elems = reflect.Append(elems, elem)
+	}
+	v.Set(elems)
+	return data, nil
+}
@@ -0,0 +1,134 @@
+package ipod_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/oandrew/ipod"
+	"github.com/oandrew/ipod/lingo-dispremote"
+	"github.com/oandrew/ipod/lingo-general"
+)
+
+type TaggedItem struct {
+	A uint8
+	B uint16
+}
+
+type TaggedPayload struct {
+	Kind  uint8
+	Name  string       `ipod:"cstring"`
+	Items []TaggedItem `ipod:"lenprefix=1"`
+	Raw   []byte       `ipod:"lenprefix=2"`
+	Extra *uint16      `ipod:"optional"`
+	Tail  []byte       `ipod:"rest,optional"`
+}
+
+type ZeroSizeItemsPayload struct {
+	Items []struct{} `ipod:"rest"`
+}
+
+type VariablePaddingPayload struct {
+	_ string
+	A *uint8 `ipod:"optional"`
+}
+
+var testCodecLingos struct {
+	TaggedPayload          `id:"0x01"`
+	ZeroSizeItemsPayload   `id:"0x02"`
+	VariablePaddingPayload `id:"0x03"`
+}
+
+func init() {
+	ipod.RegisterLingos(0xac, testCodecLingos)
+}
+
+func TestCommand_TaggedPayload(t *testing.T) {
+	extra := uint16(0x0102)
+	tests := []struct {
+		name    string
+		payload interface{}
+		data    []byte
+	}{
+		{"all", &TaggedPayload{
+			Kind:  0x01,
+			Name:  "ab",
+			Items: []TaggedItem{{0x01, 0x0203}, {0x04, 0x0506}},
+			Raw:   []byte{0xee},
+			Extra: &extra,
+			Tail:  []byte{0xaa, 0xbb},
+		}, []byte{
+			0xac, 0x01, 0x00, 0x07,
+			0x01,
+			'a', 'b', 0x00,
+			0x02, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06,
+			0x00, 0x01, 0xee,
+			0x01, 0x02,
+			0xaa, 0xbb,
+		}},
+		{"no-optional", &TaggedPayload{
+			Kind:  0x01,
+			Items: []TaggedItem{},
+			Raw:   []byte{},
+		}, []byte{
+			0xac, 0x01, 0x00, 0x07,
+			0x01,
+			0x00,
+			0x00,
+			0x00, 0x00,
+		}},
+		{"cstring", &general.RetNowPlayingFocusApp{AppID: []byte("com.app")}, []byte{
+			0x00, 0x66, 0x00, 0x07,
+			'c', 'o', 'm', '.', 'a', 'p', 'p', 0x00,
+		}},
+		{"rest", &dispremote.RetArtworkFormats{
+			Formats: []dispremote.ArtworkFormat{{FormatID: 1, PixelFormat: 2, ImageWidth: 3, ImageHeight: 4}},
+		}, []byte{
+			0x03, 0x17, 0x00, 0x07,
+			0x00, 0x01, 0x02, 0x00, 0x03, 0x00, 0x04,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := ipod.BuildCommand(tt.payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cmd.Transaction = ipod.NewTransaction(0x07)
+			data, err := cmd.MarshalBinary()
+			if err != nil {
+				t.Fatalf("Command.MarshalBinary() error = %v", err)
+			}
+			if !reflect.DeepEqual(data, tt.data) {
+				t.Errorf("Command.MarshalBinary() = %x, want %x", data, tt.data)
+			}
+
+			var got ipod.Command
+			if err := got.UnmarshalBinary(tt.data); err != nil {
+				t.Fatalf("Command.UnmarshalBinary() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Payload, tt.payload) {
+				t.Errorf("Command.UnmarshalBinary() = %#v, want %#v", got.Payload, tt.payload)
+			}
+		})
+	}
+}
+
+func TestCommand_TaggedPayloadShort(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"items", []byte{0xac, 0x01, 0x00, 0x07, 0x01, 0x00, 0x02, 0x01, 0x02, 0x03}},
+		{"raw", []byte{0xac, 0x01, 0x00, 0x07, 0x01, 0x00, 0x00, 0x00, 0x05, 0xee}},
+		{"zero-size-items", []byte{0xac, 0x02, 0x00, 0x07, 0x01}},
+		{"variable-padding", []byte{0xac, 0x03, 0x00, 0x07, 0x01}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ipod.Command
+			if err := got.UnmarshalBinary(tt.data); err == nil {
+				t.Errorf("Command.UnmarshalBinary() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package ipod
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxFormatBytes is the number of bytes FormatPayload shows
+// of a byte slice that is not a string
+const maxFormatBytes = 32
+
+// PayloadName returns the name of the payload type qualified
+// with the lingo package, i.e. "general.RequestIdentify"
+func PayloadName(payload interface{}) string {
+	if payload == nil {
+		return "nil"
+	}
+	t := reflect.TypeOf(payload)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.String()
+}
+
+// FormatCommand returns a single line description of cmd:
+// the IDs, the transaction and the formatted payload.
+func FormatCommand(cmd *Command) string {
+	if cmd == nil {
+		return "nil"
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "[%v", cmd.ID)
+	if cmd.Transaction != nil {
+		fmt.Fprintf(&b, " trx %v", *cmd.Transaction)
+	}
+	b.WriteString("] ")
+	b.WriteString(FormatPayload(cmd.Payload))
+	return b.String()
+}
+
+// FormatPayload returns a human-readable form of a payload
+// with the names of the fields.
+// Values implementing fmt.Stringer, i.e. the enums and masks of the lingos,
+// are shown with their String method, NUL-terminated byte strings
+// are shown quoted and other bytes in hex.
+func FormatPayload(payload interface{}) string {
+	if payload == nil {
+		return "nil"
+	}
+	var b bytes.Buffer
+	b.WriteString(PayloadName(payload))
+	v := reflect.Indirect(reflect.ValueOf(payload))
+	if v.Kind() != reflect.Struct {
+		b.WriteByte('(')
+		formatValue(&b, v)
+		b.WriteByte(')')
+		return b.String()
+	}
+	formatStruct(&b, v)
+	return b.String()
+}
+
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+func formatValue(b *bytes.Buffer, v reflect.Value) {
+	if !v.IsValid() {
+		b.WriteString("nil")
+		return
+	}
+	if v.Type().Implements(stringerType) && v.CanInterface() {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		b.WriteString(v.Interface().(fmt.Stringer).String())
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		elem := v.Elem()
+		if v.Kind() == reflect.Interface && reflect.Indirect(elem).Kind() == reflect.Struct {
+			b.WriteString(FormatPayload(elem.Interface()))
+			return
+		}
+		formatValue(b, elem)
+	case reflect.Struct:
+		formatStruct(b, v)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			formatBytes(b, v)
+			return
+		}
+		b.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			formatValue(b, v.Index(i))
+		}
+		b.WriteByte(']')
+	default:
+		fmt.Fprintf(b, "%v", v.Interface())
+	}
+}
+
+func formatStruct(b *bytes.Buffer, v reflect.Value) {
+	t := v.Type()
+	b.WriteByte('{')
+	n := 0
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Name == "_" {
+			continue
+		}
+		if n > 0 {
+			b.WriteString(", ")
+		}
+		n++
+		b.WriteString(f.Name)
+		b.WriteString(": ")
+		formatValue(b, v.Field(i))
+	}
+	b.WriteByte('}')
+}
+
+func formatBytes(b *bytes.Buffer, v reflect.Value) {
+	data := make([]byte, v.Len())
+	reflect.Copy(reflect.ValueOf(data), v)
+	if s, ok := cString(data); ok {
+		fmt.Fprintf(b, "%q", s)
+		return
+	}
+	if len(data) > maxFormatBytes {
+		fmt.Fprintf(b, "%x... (%d bytes)", data[:maxFormatBytes], len(data))
+		return
+	}
+	fmt.Fprintf(b, "%x", data)
+}
+
+// cString decodes a NUL-terminated UTF-8 string,
+// the padding NULs of fixed-size strings are allowed
+func cString(data []byte) (string, bool) {
+	end := bytes.IndexByte(data, 0)
+	if end < 0 || strings.Trim(string(data[end:]), "\x00") != "" {
+		return "", false
+	}
+	// zeroed fixed-size fields are not strings
+	if end == 0 && len(data) > 1 {
+		return "", false
+	}
+	s := string(data[:end])
+	if !utf8.ValidString(s) {
+		return "", false
+	}
+	for _, r := range s {
+		if !unicode.IsPrint(r) {
+			return "", false
+		}
+	}
+	return s, true
+}
+
+// FormatMask returns the names of the bits set in m joined with "|",
+// name returns the name of a single bit
+func FormatMask(m uint64, name func(bit uint64) string) string {
+	if m == 0 {
+		return "0"
+	}
+	var names []string
+	for bit := uint64(1); bit != 0; bit <<= 1 {
+		if m&bit != 0 {
+			names = append(names, name(bit))
+		}
+	}
+	return strings.Join(names, "|")
+}
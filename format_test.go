@@ -0,0 +1,51 @@
+package ipod_test
+
+import (
+	"testing"
+
+	"github.com/oandrew/ipod"
+	"github.com/oandrew/ipod/lingo-extremote"
+	"github.com/oandrew/ipod/lingo-general"
+)
+
+func TestFormatPayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload interface{}
+		want    string
+	}{
+		{"empty", &general.RequestIdentify{}, "general.RequestIdentify{}"},
+		{"enum", &general.ACK{Status: general.ACKStatusBadParameter, CmdID: 0x05}, "general.ACK{Status: ACKStatusBadParameter, CmdID: 5}"},
+		{"unknown-enum", &extremote.PlayControl{Cmd: 0x42}, "extremote.PlayControl{Cmd: PlayControlCmd(66)}"},
+		{"cstring", &general.ReturniPodName{Name: []byte("ipod\x00")}, `general.ReturniPodName{Name: "ipod"}`},
+		{"padded-cstring", &extremote.ReturnCategorizedDatabaseRecord{RecordCategoryIndex: 1, String: [16]byte{'a', 'b'}}, `extremote.ReturnCategorizedDatabaseRecord{RecordCategoryIndex: 1, String: "ab"}`},
+		{"bytes", &general.GetDevAuthenticationSignatureV1{Counter: 1}, "general.GetDevAuthenticationSignatureV1{Challenge: 00000000000000000000000000000000, Counter: 1}"},
+		{"long-bytes", &general.RetDevAuthenticationSignature{Signature: make([]byte, 40)}, "general.RetDevAuthenticationSignature{Signature: 0000000000000000000000000000000000000000000000000000000000000000... (40 bytes)}"},
+		{"mask", &general.SetEventNotification{EventMask: general.EventMask(general.EventFlowControl | general.EventDatabaseChanged | 1<<30)}, "general.SetEventNotification{EventMask: EventFlowControl|EventDatabaseChanged|EventBit(1073741824)}"},
+		{"zero-mask", &extremote.SetPlayStatusChangeNotification{}, "extremote.SetPlayStatusChangeNotification{EventMask: 0}"},
+		{"nested", &general.SetFIDTokenValues{NumFIDTokenValues: 1, FIDTokenValues: []general.FIDTokenValue{
+			{FIDType: 0x00, FIDSubtype: 0x01, Token: &general.FIDAccCapsToken{AccCapsBitmask: general.AccCapMask(general.AccCapAnalogLineOut | general.AccCapAppComm)}},
+		}}, "general.SetFIDTokenValues{NumFIDTokenValues: 1, FIDTokenValues: [{Len: 0, FIDType: 0, FIDSubtype: 1, Token: general.FIDAccCapsToken{AccCapsBitmask: AccCapAnalogLineOut|AccCapAppComm}}]}"},
+		{"unknown", ipod.UnknownPayload{0x01, 0x02}, "ipod.UnknownPayload(0102)"},
+		{"nil", nil, "nil"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ipod.FormatPayload(tt.payload); got != tt.want {
+				t.Errorf("FormatPayload() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatCommand(t *testing.T) {
+	cmd := &ipod.Command{
+		ID:          ipod.NewLingoCmdID(0x04, 0x0029),
+		Transaction: ipod.NewTransaction(3),
+		Payload:     &extremote.PlayControl{Cmd: extremote.PlayControlNextTrack},
+	}
+	want := "[0x04,0x0029 trx 0x0003] extremote.PlayControl{Cmd: PlayControlNextTrack}"
+	if got := ipod.FormatCommand(cmd); got != want {
+		t.Errorf("FormatCommand() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,62 @@
+//go:build go1.18
+// +build go1.18
+
+package ipod_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/oandrew/ipod"
+	"github.com/oandrew/ipod/lingo-general"
+)
+
+func FuzzPacketReader(f *testing.F) {
+	for _, tt := range packetReaderTests {
+		f.Add(tt.data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		pr := ipod.NewPacketReader(bytes.NewReader(data))
+		// every call either returns a packet or skips a byte
+		for i := 0; i <= len(data)+1; i++ {
+			pkt, err := pr.ReadPacket()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				continue
+			}
+			var buf bytes.Buffer
+			if err := ipod.NewPacketWriter(&buf).WritePacket(pkt); err != nil {
+				t.Fatalf("PacketWriter.WritePacket() error = %v", err)
+			}
+			got, err := ipod.NewPacketReader(&buf).ReadPacket()
+			if err != nil || !bytes.Equal(got, pkt) {
+				t.Fatalf("PacketReader.ReadPacket() = %v, %v, want %v", got, err, pkt)
+			}
+		}
+		t.Fatalf("PacketReader.ReadPacket() made no progress")
+	})
+}
+
+func FuzzCommandUnmarshal(f *testing.F) {
+	for _, tt := range commandUnmarshalTests {
+		f.Add(tt.data, uint8(ipod.TransactionModeUnknown))
+	}
+	// SetFIDTokenValues with a token length shorter than its header
+	f.Add([]byte{general.LingoGeneralID, 0x39, 0x00, 0x01, 0x01, 0x00, 0x00, 0x00}, uint8(ipod.TransactionModeOn))
+	// RetDevAuthenticationInfo with a short certificate header
+	f.Add([]byte{general.LingoGeneralID, 0x15, 0x00, 0x01, 0x02, 0x00}, uint8(ipod.TransactionModeOn))
+	f.Fuzz(func(t *testing.T, data []byte, mode uint8) {
+		ctx := func(uint8) ipod.LookupContext {
+			return ipod.LookupContext{TransactionMode: ipod.TransactionMode(mode % 3)}
+		}
+		var cmd ipod.Command
+		if err := ipod.DefaultRegistry.UnmarshalCommand(&cmd, data, ctx); err != nil {
+			return
+		}
+		// decoded commands don't always encode, but must not panic
+		cmd.MarshalBinary()
+	})
+}
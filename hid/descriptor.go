@@ -0,0 +1,198 @@
+package hid
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+)
+
+// item types of the short items of a report descriptor
+const (
+	itemTypeMain   = 0
+	itemTypeGlobal = 1
+)
+
+// item tags of a report descriptor used to find the reports
+const (
+	tagInput       = 0x8
+	tagOutput      = 0x9
+	tagReportSize  = 0x7
+	tagReportID    = 0x8
+	tagReportCount = 0x9
+	tagPush        = 0xA
+	tagPop         = 0xB
+
+	longItemPrefix = 0xFE
+)
+
+// reportGlobals is the part of the global item state
+// that defines the size of the reports
+type reportGlobals struct {
+	size, count, id uint32
+}
+
+type reportKey struct {
+	id  uint32
+	dir ReportDir
+}
+
+// ParseReportDescriptor extracts the input and output reports
+// of a USB HID report descriptor.
+// Input reports are sent by the ipod (ReportDirAccIn),
+// output reports by the accessory (ReportDirAccOut), feature reports are ignored.
+// The ReportDefs are ordered by direction and size like DefaultReportDefs.
+func ParseReportDescriptor(desc []byte) (ReportDefs, error) {
+	var g reportGlobals
+	var stack []reportGlobals
+	bits := make(map[reportKey]uint32)
+	var keys []reportKey
+
+	for i := 0; i < len(desc); {
+		prefix := desc[i]
+		if prefix == longItemPrefix {
+			if i+1 >= len(desc) {
+				return nil, fmt.Errorf("hid descriptor: truncated long item at %d", i)
+			}
+			i += 3 + int(desc[i+1])
+			if i > len(desc) {
+				return nil, fmt.Errorf("hid descriptor: truncated long item")
+			}
+			continue
+		}
+		size := int(prefix & 0x03)
+		if size == 3 {
+			size = 4
+		}
+		typ, tag := (prefix>>2)&0x03, prefix>>4
+		if i+1+size > len(desc) {
+			return nil, fmt.Errorf("hid descriptor: truncated item %#02x at %d", prefix, i)
+		}
+		var data uint32
+		for j := size - 1; j >= 0; j-- {
+			data = data<<8 | uint32(desc[i+1+j])
+		}
+		i += 1 + size
+
+		switch typ {
+		case itemTypeGlobal:
+			switch tag {
+			case tagReportSize:
+				g.size = data
+			case tagReportID:
+				if data == 0 || data > 0xff {
+					return nil, fmt.Errorf("hid descriptor: invalid report id %d", data)
+				}
+				g.id = data
+			case tagReportCount:
+				g.count = data
+			case tagPush:
+				stack = append(stack, g)
+			case tagPop:
+				if len(stack) == 0 {
+					return nil, fmt.Errorf("hid descriptor: pop without push")
+				}
+				g, stack = stack[len(stack)-1], stack[:len(stack)-1]
+			}
+		case itemTypeMain:
+			var dir ReportDir
+			switch tag {
+			case tagInput:
+				dir = ReportDirAccIn
+			case tagOutput:
+				dir = ReportDirAccOut
+			default:
+				continue
+			}
+			if g.id == 0 {
+				return nil, fmt.Errorf("hid descriptor: report without a report id")
+			}
+			k := reportKey{id: g.id, dir: dir}
+			if _, ok := bits[k]; !ok {
+				keys = append(keys, k)
+			}
+			bits[k] += g.size * g.count
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("hid descriptor: no input or output reports")
+	}
+	defs := make(ReportDefs, 0, len(keys))
+	for _, k := range keys {
+		def := ReportDef{ID: int(k.id), Len: int(bits[k]+7) / 8, Dir: k.dir}
+		if def.MaxPayload() < 1 {
+			return nil, fmt.Errorf("hid descriptor: report %#02x is too short: %d bytes", def.ID, def.Len)
+		}
+		defs = append(defs, def)
+	}
+	sort.SliceStable(defs, func(i, j int) bool {
+		if defs[i].Dir != defs[j].Dir {
+			return defs[i].Dir < defs[j].Dir
+		}
+		return defs[i].Len < defs[j].Len
+	})
+	return defs, nil
+}
+
+// ReadReportDescriptor parses the report descriptor stored in a file
+// i.e. the report_descriptor of a device in sysfs
+//
+//	/sys/class/hidraw/hidraw0/device/report_descriptor
+func ReadReportDescriptor(path string) (ReportDefs, error) {
+	desc, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseReportDescriptor(desc)
+}
+
+// appendItem appends a short item with the smallest data size that fits v
+func appendItem(desc []byte, typ, tag byte, v uint32) []byte {
+	prefix := tag<<4 | typ<<2
+	switch {
+	case v <= 0xff:
+		return append(desc, prefix|1, byte(v))
+	case v <= 0xffff:
+		return append(desc, prefix|2, byte(v), byte(v>>8))
+	default:
+		return append(desc, prefix|3, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+	}
+}
+
+// ReportDescriptor returns the vendor-defined report descriptor
+// with a byte array report per ReportDef, i.e. the descriptor of ipod-gadget
+// for DefaultReportDefs. ParseReportDescriptor returns the defs back
+// once they are ordered by direction and size.
+func (defs ReportDefs) ReportDescriptor() ([]byte, error) {
+	desc := []byte{
+		0x06, 0x00, 0xFF, // Usage Page (Vendor Defined 0xFF00)
+		0x09, 0x01, // Usage (0x01)
+		0xA1, 0x01, // Collection (Application)
+		0x75, 0x08, // Report Size (8)
+		0x15, 0x00, // Logical Minimum (0)
+		0x26, 0xFF, 0x00, // Logical Maximum (255)
+	}
+	for _, def := range defs {
+		if def.ID < 1 || def.ID > 0xff {
+			return nil, fmt.Errorf("hid descriptor: invalid report id %d", def.ID)
+		}
+		if def.MaxPayload() < 1 {
+			return nil, fmt.Errorf("hid descriptor: report %#02x is too short: %d bytes", def.ID, def.Len)
+		}
+		var main byte
+		switch def.Dir {
+		case ReportDirAccIn:
+			main = tagInput
+		case ReportDirAccOut:
+			main = tagOutput
+		default:
+			return nil, fmt.Errorf("hid descriptor: report %#02x: unknown direction %d", def.ID, def.Dir)
+		}
+		desc = append(desc, 0x09, 0x01) // Usage (0x01)
+		desc = appendItem(desc, itemTypeGlobal, tagReportID, uint32(def.ID))
+		desc = appendItem(desc, itemTypeGlobal, tagReportCount, uint32(def.Len))
+		// Data,Var,Abs,Buffered Bytes
+		desc = appendItem(desc, itemTypeMain, main, 0x0102)
+	}
+	return append(desc, 0xC0), nil // End Collection
+}
@@ -0,0 +1,181 @@
+package hid_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/oandrew/ipod/hid"
+)
+
+// testGadgetDescriptor builds a descriptor in the layout of ipod-gadget
+// with a report per def
+func testGadgetDescriptor(defs hid.ReportDefs) []byte {
+	desc := []byte{
+		0x06, 0x00, 0xFF, // Usage Page (Vendor Defined 0xFF00)
+		0x09, 0x01, // Usage (0x01)
+		0xA1, 0x01, // Collection (Application)
+		0x75, 0x08, // Report Size (8)
+		0x26, 0x80, 0x00, // Logical Maximum (128)
+		0x15, 0x00, // Logical Minimum (0)
+	}
+	for _, def := range defs {
+		main := byte(0x82) // Input
+		if def.Dir == hid.ReportDirAccOut {
+			main = 0x92 // Output
+		}
+		desc = append(desc,
+			0x09, 0x01, // Usage (0x01)
+			0x85, byte(def.ID), // Report ID
+			0x96, byte(def.Len), byte(def.Len>>8), // Report Count
+			main, 0x02, 0x01,
+		)
+	}
+	return append(desc, 0xC0) // End Collection
+}
+
+func TestParseReportDescriptor(t *testing.T) {
+	tests := []struct {
+		name string
+		desc []byte
+		want hid.ReportDefs
+	}{
+		{"gadget", testGadgetDescriptor(hid.DefaultReportDefs), hid.DefaultReportDefs},
+		{"sorted", []byte{
+			0x75, 0x08, // Report Size (8)
+			0x85, 0x03, 0x95, 0x10, 0x91, 0x02, // Report ID (3), Report Count (16), Output
+			0x85, 0x02, 0x95, 0x08, 0x81, 0x02, // Report ID (2), Report Count (8), Input
+			0x85, 0x01, 0x95, 0x04, 0x81, 0x02, // Report ID (1), Report Count (4), Input
+		}, hid.ReportDefs{
+			{ID: 0x01, Len: 4, Dir: hid.ReportDirAccIn},
+			{ID: 0x02, Len: 8, Dir: hid.ReportDirAccIn},
+			{ID: 0x03, Len: 16, Dir: hid.ReportDirAccOut},
+		}},
+		{"bits-push-pop-feature", []byte{
+			0x85, 0x01, // Report ID (1)
+			0x75, 0x01, 0x95, 0x04, 0x81, 0x02, // 4 bits Input
+			0xA4,                   // Push
+			0x75, 0x08, 0x95, 0x08, // Report Size (8), Report Count (8)
+			0xB1, 0x02, // Feature
+			0xB4,       // Pop
+			0x81, 0x02, // 4 bits Input
+			0xFE, 0x02, 0x10, 0xAA, 0xBB, // long item
+			0x75, 0x08, 0x95, 0x03, 0x81, 0x02, // 3 bytes Input
+		}, hid.ReportDefs{
+			{ID: 0x01, Len: 4, Dir: hid.ReportDirAccIn},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := hid.ParseReportDescriptor(tt.desc)
+			if err != nil {
+				t.Fatalf("ParseReportDescriptor() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseReportDescriptor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseReportDescriptor_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		desc []byte
+	}{
+		{"empty", nil},
+		{"no-reports", []byte{0xA1, 0x01, 0xC0}},
+		{"no-report-id", []byte{0x75, 0x08, 0x95, 0x04, 0x81, 0x02}},
+		{"too-short", []byte{0x85, 0x01, 0x75, 0x08, 0x95, 0x01, 0x81, 0x02}},
+		{"truncated", []byte{0x85, 0x01, 0x96, 0x04}},
+		{"truncated-long", []byte{0xFE, 0x04, 0x10, 0x00}},
+		{"pop", []byte{0xB4}},
+		{"report-id-zero", []byte{0x85, 0x00}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := hid.ParseReportDescriptor(tt.desc); err == nil {
+				t.Errorf("ParseReportDescriptor(% x) error = nil, want an error", tt.desc)
+			}
+		})
+	}
+}
+
+func TestReadReportDescriptor(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "report_descriptor")
+	if err := ioutil.WriteFile(path, testGadgetDescriptor(hid.DefaultReportDefs), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := hid.ReadReportDescriptor(path)
+	if err != nil {
+		t.Fatalf("ReadReportDescriptor() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, hid.DefaultReportDefs) {
+		t.Errorf("ReadReportDescriptor() = %+v, want %+v", got, hid.DefaultReportDefs)
+	}
+	if _, err := hid.ReadReportDescriptor(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("ReadReportDescriptor() error = nil, want an error")
+	}
+}
+
+func TestReportDefs_ReportDescriptor(t *testing.T) {
+	defs := hid.ReportDefs{
+		{ID: 0x01, Len: 5, Dir: hid.ReportDirAccIn},
+		{ID: 0x0C, Len: 767, Dir: hid.ReportDirAccIn},
+		{ID: 0x0D, Len: 5, Dir: hid.ReportDirAccOut},
+	}
+	want := []byte{
+		0x06, 0x00, 0xFF, 0x09, 0x01, 0xA1, 0x01, 0x75, 0x08, 0x15, 0x00, 0x26, 0xFF, 0x00,
+		0x09, 0x01, 0x85, 0x01, 0x95, 0x05, 0x82, 0x02, 0x01,
+		0x09, 0x01, 0x85, 0x0C, 0x96, 0xFF, 0x02, 0x82, 0x02, 0x01,
+		0x09, 0x01, 0x85, 0x0D, 0x95, 0x05, 0x92, 0x02, 0x01,
+		0xC0,
+	}
+	got, err := defs.ReportDescriptor()
+	if err != nil {
+		t.Fatalf("ReportDefs.ReportDescriptor() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReportDefs.ReportDescriptor() = % x, want % x", got, want)
+	}
+}
+
+func TestReportDefs_ReportDescriptorRoundTrip(t *testing.T) {
+	desc, err := hid.DefaultReportDefs.ReportDescriptor()
+	if err != nil {
+		t.Fatalf("ReportDefs.ReportDescriptor() error = %v", err)
+	}
+	got, err := hid.ParseReportDescriptor(desc)
+	if err != nil {
+		t.Fatalf("ParseReportDescriptor() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, hid.DefaultReportDefs) {
+		t.Errorf("ParseReportDescriptor() = %+v, want %+v", got, hid.DefaultReportDefs)
+	}
+}
+
+func TestReportDefs_ReportDescriptorErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		defs hid.ReportDefs
+	}{
+		{"id-zero", hid.ReportDefs{{ID: 0x00, Len: 5, Dir: hid.ReportDirAccIn}}},
+		{"id-large", hid.ReportDefs{{ID: 0x100, Len: 5, Dir: hid.ReportDirAccIn}}},
+		{"too-short", hid.ReportDefs{{ID: 0x01, Len: 1, Dir: hid.ReportDirAccIn}}},
+		{"dir", hid.ReportDefs{{ID: 0x01, Len: 5, Dir: 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.defs.ReportDescriptor(); err == nil {
+				t.Errorf("ReportDefs.ReportDescriptor() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+//go:build go1.18
+// +build go1.18
+
+package hid_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/oandrew/ipod/hid"
+)
+
+var fuzzReportDefs = []hid.ReportDefs{
+	hid.DefaultReportDefs,
+	testReportDefs1,
+	testReportDefs2,
+	testReportDefs3,
+}
+
+// marshalReports encodes reports as id, link control, data length, data
+func marshalReports(reports []hid.Report) []byte {
+	var buf bytes.Buffer
+	for _, r := range reports {
+		buf.WriteByte(r.ID)
+		buf.WriteByte(byte(r.LinkControl))
+		buf.WriteByte(byte(len(r.Data)))
+		buf.Write(r.Data)
+	}
+	return buf.Bytes()
+}
+
+func unmarshalReports(data []byte) []hid.Report {
+	var reports []hid.Report
+	for len(data) >= 3 {
+		n := int(data[2])
+		if len(data[3:]) < n {
+			n = len(data[3:])
+		}
+		reports = append(reports, hid.Report{
+			ID:          data[0],
+			LinkControl: hid.LinkControl(data[1]),
+			Data:        data[3 : 3+n],
+		})
+		data = data[3+n:]
+	}
+	return reports
+}
+
+func FuzzDecoder(f *testing.F) {
+	for _, tt := range decoderTests {
+		for i, defs := range fuzzReportDefs {
+			if reflect.DeepEqual(defs, tt.reportDefs) {
+				f.Add(uint8(i), marshalReports(tt.reports))
+				break
+			}
+		}
+	}
+	f.Fuzz(func(t *testing.T, defs uint8, data []byte) {
+		reports := unmarshalReports(data)
+		rr := &testReportReader{reports: reports}
+		dec := hid.NewDecoder(rr, fuzzReportDefs[int(defs)%len(fuzzReportDefs)])
+		// every frame takes at least a report
+		for i := 0; i <= len(reports); i++ {
+			if _, err := dec.ReadFrame(); err != nil {
+				return
+			}
+		}
+		t.Fatalf("ReadFrame() returned more frames than reports")
+	})
+}
+
+func FuzzReportReader(f *testing.F) {
+	for _, tt := range decoderTests {
+		for _, r := range tt.reports {
+			f.Add(append([]byte{r.ID, byte(r.LinkControl)}, r.Data...))
+		}
+	}
+	f.Add([]byte{})
+	f.Add([]byte{0x01})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if r, err := hid.SingleReport(data).ReadReport(); err == nil && len(r.Data) != len(data)-2 {
+			t.Fatalf("SingleReport.ReadReport() data len = %d, want %d", len(r.Data), len(data)-2)
+		}
+		hid.NewReportReader(bytes.NewReader(data)).ReadReport()
+	})
+}
@@ -3,6 +3,8 @@ package hid
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 )
 
 type Report struct {
@@ -19,9 +21,19 @@ const (
 	LinkControlMoreToFollow LinkControl = 0x02
 )
 
+// Encoder splits frames into reports. The Data of the reports passed
+// to the ReportWriter is only valid during WriteReport,
+// the buffer is reused for the next report.
 type Encoder struct {
 	reportDefs ReportDefs
+	dir        ReportDir
 	w          ReportWriter
+	buf        []byte
+	plan       []ReportDef
+
+	// Strategy chooses the reports of a frame. nil plans like PickGreedy
+	// in a reused plan, PickGreedy itself allocates one per frame.
+	Strategy ReportStrategy
 }
 
 func min(a, b int) int {
@@ -31,28 +43,45 @@ func min(a, b int) int {
 	return b
 }
 
+// WriteFrame writes the frame in the reports chosen by the Strategy,
+// data is not retained
 func (e *Encoder) WriteFrame(data []byte) error {
+	var plan []ReportDef
+	var err error
+	if e.Strategy == nil {
+		e.plan, err = appendGreedy(e.plan[:0], e.reportDefs, e.dir, len(data))
+		plan = e.plan
+	} else {
+		plan, err = e.Strategy(e.reportDefs, e.dir, len(data))
+	}
+	if err != nil {
+		return err
+	}
 	offset := 0
-	bytesLeft := len(data)
-	for bytesLeft > 0 {
-		reportDef, err := e.reportDefs.Pick(bytesLeft, ReportDirAccIn)
-		if err != nil {
-			return err
+	for i, reportDef := range plan {
+		payloadLen := min(len(data)-offset, reportDef.MaxPayload())
+		if payloadLen <= 0 {
+			return fmt.Errorf("report plan too long for the frame")
 		}
-		payloadLen := bytesLeft
 		linkControl := LinkControlDone
-		if bytesLeft > reportDef.MaxPayload() {
-			payloadLen = reportDef.MaxPayload()
-			if offset == 0 {
-				linkControl = LinkControlMoreToFollow
-			} else {
-				linkControl = LinkControlContinue | LinkControlMoreToFollow
-			}
-		} else if offset > 0 {
+		last := i == len(plan)-1
+		switch {
+		case offset == 0 && !last:
+			linkControl = LinkControlMoreToFollow
+		case offset > 0 && !last:
+			linkControl = LinkControlContinue | LinkControlMoreToFollow
+		case offset > 0:
 			linkControl = LinkControlContinue
 		}
-		reportData := make([]byte, reportDef.MaxPayload())
-		copy(reportData, data[offset:offset+payloadLen])
+		if cap(e.buf) < reportDef.MaxPayload() {
+			e.buf = make([]byte, reportDef.MaxPayload())
+		}
+		reportData := e.buf[:reportDef.MaxPayload()]
+		n := copy(reportData, data[offset:offset+payloadLen])
+		// zero the padding of the last report
+		for i := n; i < len(reportData); i++ {
+			reportData[i] = 0
+		}
 		report := Report{
 			ID:          byte(reportDef.ID),
 			LinkControl: linkControl,
@@ -61,16 +90,30 @@ func (e *Encoder) WriteFrame(data []byte) error {
 		if err := e.w.WriteReport(report); err != nil {
 			return err
 		}
-		bytesLeft -= payloadLen
 		offset += payloadLen
 	}
+	if offset < len(data) {
+		return fmt.Errorf("report plan too short for the frame")
+	}
 	return nil
 
 }
 
+// MaxFrameSize returns the largest frame that fits in a single report
+func (e *Encoder) MaxFrameSize() int {
+	return e.reportDefs.MaxPayload(e.dir)
+}
+
 func NewEncoder(w ReportWriter, defs ReportDefs) *Encoder {
+	return NewEncoderDir(w, defs, ReportDirAccIn)
+}
+
+// NewEncoderDir creates an Encoder that writes the reports of the direction dir,
+// i.e. ReportDirAccOut to send frames as an accessory
+func NewEncoderDir(w ReportWriter, defs ReportDefs, dir ReportDir) *Encoder {
 	return &Encoder{
 		reportDefs: defs,
+		dir:        dir,
 		w:          w,
 	}
 }
@@ -79,44 +122,137 @@ func NewEncoderDefault(w ReportWriter) *Encoder {
 	return NewEncoder(w, DefaultReportDefs)
 }
 
+// Errors of the reports that break the link control sequence,
+// ReadFrame returns them wrapped in a *ReportError
+var (
+	ErrUnknownReport      = errors.New("unknown report id")
+	ErrUnknownLinkControl = errors.New("unknown link control")
+	ErrUnexpectedContinue = errors.New("continue without a started frame")
+	ErrFrameTooLarge      = errors.New("frame too large")
+)
+
+// ErrFrameInterrupted is passed to Decoder.Dropped when a partial frame
+// is discarded because a new frame starts
+var ErrFrameInterrupted = errors.New("frame interrupted by a new frame")
+
+// ReportError is a report that the Decoder could not assemble into a frame
+type ReportError struct {
+	ID          byte
+	LinkControl LinkControl
+	Err         error
+}
+
+func (e *ReportError) Error() string {
+	return fmt.Sprintf("hid: report %#02x link control %#02x: %v", e.ID, byte(e.LinkControl), e.Err)
+}
+
+func (e *ReportError) Unwrap() error {
+	return e.Err
+}
+
+// decoderState is the frame assembly state of a Decoder
+type decoderState uint8
+
+const (
+	// waiting for a Done or MoreToFollow report
+	stateIdle decoderState = iota
+	// a frame is started, waiting for Continue reports
+	stateAssembling
+	// skipping the Continue reports of a broken frame
+	stateSkipping
+)
+
+// Decoder assembles frames from reports. A frame is either a single Done report
+// or a MoreToFollow report, Continue|MoreToFollow reports and a final Continue report.
+// Reports that break the sequence make ReadFrame return a *ReportError,
+// the rest of a broken frame is skipped.
 type Decoder struct {
 	reportDefs ReportDefs
 	r          ReportReader
 	buf        bytes.Buffer
+	state      decoderState
+
+	// MaxFrameLen limits the size of the assembled frames, 0 means no limit
+	MaxFrameLen int
+	// Dropped is called with the data of a partial frame
+	// when it is discarded because of err
+	Dropped func(partial []byte, err error)
+}
+
+// drop discards the partial frame
+func (e *Decoder) drop(err error) {
+	if e.state == stateAssembling && e.buf.Len() > 0 && e.Dropped != nil {
+		e.Dropped(e.buf.Bytes(), err)
+	}
+	e.buf.Reset()
+	e.state = stateIdle
 }
 
+// ReadFrame reads reports until a frame is complete.
+// The frame is valid until the next call to ReadFrame.
+// Errors of the ReportReader leave the partial frame in place.
 func (e *Decoder) ReadFrame() ([]byte, error) {
-	buf := &e.buf
-	buf.Reset()
-	done := false
-	for !done {
+	for {
 		report, err := e.r.ReadReport()
 		if err != nil {
 			return nil, err
 		}
+		reportErr := func(err error) error {
+			return &ReportError{ID: report.ID, LinkControl: report.LinkControl, Err: err}
+		}
 		reportDef, err := e.reportDefs.Find(int(report.ID))
 		if err != nil {
-			return nil, err
+			e.drop(ErrUnknownReport)
+			return nil, reportErr(ErrUnknownReport)
 		}
 
 		n := min(len(report.Data), reportDef.MaxPayload())
+		if n < 0 {
+			n = 0
+		}
 		reportData := report.Data[:n]
+
+		var done bool
 		switch report.LinkControl {
-		case LinkControlDone:
-			buf.Reset()
-			buf.Write(reportData)
-			done = true
-		case LinkControlMoreToFollow:
-			buf.Reset()
-			buf.Write(reportData)
-		case LinkControlContinue | LinkControlMoreToFollow:
-			buf.Write(reportData)
-		case LinkControlContinue:
-			buf.Write(reportData)
-			done = true
+		case LinkControlDone, LinkControlMoreToFollow:
+			if e.state == stateAssembling {
+				e.drop(ErrFrameInterrupted)
+			}
+			e.buf.Reset()
+			e.state = stateAssembling
+			done = report.LinkControl == LinkControlDone
+		case LinkControlContinue | LinkControlMoreToFollow, LinkControlContinue:
+			done = report.LinkControl == LinkControlContinue
+			switch e.state {
+			case stateIdle:
+				if !done {
+					e.state = stateSkipping
+				}
+				return nil, reportErr(ErrUnexpectedContinue)
+			case stateSkipping:
+				if done {
+					e.state = stateIdle
+				}
+				continue
+			}
+		default:
+			e.drop(ErrUnknownLinkControl)
+			return nil, reportErr(ErrUnknownLinkControl)
+		}
+
+		if e.MaxFrameLen > 0 && e.buf.Len()+len(reportData) > e.MaxFrameLen {
+			e.drop(ErrFrameTooLarge)
+			if !done {
+				e.state = stateSkipping
+			}
+			return nil, reportErr(ErrFrameTooLarge)
+		}
+		e.buf.Write(reportData)
+		if done {
+			e.state = stateIdle
+			return e.buf.Bytes(), nil
 		}
 	}
-	return buf.Bytes(), nil
 }
 
 func NewDecoder(r ReportReader, defs ReportDefs) *Decoder {
@@ -1,6 +1,7 @@
 package hid_test
 
 import (
+	"errors"
 	"io"
 	"io/ioutil"
 	"reflect"
@@ -14,6 +15,7 @@ type testReportWriter struct {
 }
 
 func (rw *testReportWriter) WriteReport(report hid.Report) error {
+	report.Data = append([]byte(nil), report.Data...)
 	rw.reports = append(rw.reports, report)
 	return nil
 }
@@ -96,44 +98,81 @@ func TestEncoder(t *testing.T) {
 	}
 }
 
-func TestHidDecoder(t *testing.T) {
+func TestEncoder_MaxFrameSize(t *testing.T) {
 	tests := []struct {
 		name       string
 		reportDefs hid.ReportDefs
-		want       []byte
-		reports    []hid.Report
-
-		wantErr bool
+		want       int
 	}{
-		{"report-1-pkt-1", testReportDefs1, []byte{0x01}, []hid.Report{
-			{ID: 0x01, LinkControl: hid.LinkControlDone, Data: []byte{0x01}},
-		}, false},
-		{"report-1-pkt-2", testReportDefs1, []byte{0x01, 0x02}, []hid.Report{
-			{ID: 0x01, LinkControl: hid.LinkControlMoreToFollow, Data: []byte{0x01}},
-			{ID: 0x01, LinkControl: hid.LinkControlContinue, Data: []byte{0x02}},
-		}, false},
-		{"report-1-pkt-3", testReportDefs1, []byte{0x01, 0x02, 0x03}, []hid.Report{
-			{ID: 0x01, LinkControl: hid.LinkControlMoreToFollow, Data: []byte{0x01}},
-			{ID: 0x01, LinkControl: hid.LinkControlContinue | hid.LinkControlMoreToFollow, Data: []byte{0x02}},
-			{ID: 0x01, LinkControl: hid.LinkControlContinue, Data: []byte{0x03}},
-		}, false},
-
-		{"report-2-pkt-1", testReportDefs2, []byte{0x01}, []hid.Report{
-			{ID: 0x01, LinkControl: hid.LinkControlDone, Data: []byte{0x01}},
-		}, false},
-		{"report-2-pkt-2", testReportDefs2, []byte{0x01, 0x02}, []hid.Report{
-			{ID: 0x02, LinkControl: hid.LinkControlDone, Data: []byte{0x01, 0x02}},
-		}, false},
-		{"report-2-pkt-3", testReportDefs2, []byte{0x01, 0x02, 0x03}, []hid.Report{
-			{ID: 0x02, LinkControl: hid.LinkControlMoreToFollow, Data: []byte{0x01, 0x02}},
-			{ID: 0x01, LinkControl: hid.LinkControlContinue, Data: []byte{0x03}},
-		}, false},
-
-		{"report-3-pkt-1", testReportDefs3, []byte{0x01, 0x02, 0x00}, []hid.Report{
-			{ID: 0x01, LinkControl: hid.LinkControlDone, Data: []byte{0x01, 0x02, 0x00}},
-		}, false},
+		{"default", hid.DefaultReportDefs, 766},
+		{"report-1", testReportDefs1, 1},
+		{"report-2", testReportDefs2, 2},
+		{"no-in-reports", hid.ReportDefs{{ID: 0x01, Len: 9, Dir: hid.ReportDirAccOut}}, 0},
 	}
 	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := hid.NewEncoder(&testReportWriter{}, tt.reportDefs)
+			if got := e.MaxFrameSize(); got != tt.want {
+				t.Errorf("Encoder.MaxFrameSize() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncoderDir(t *testing.T) {
+	rw := &testReportWriter{}
+	e := hid.NewEncoderDir(rw, hid.DefaultReportDefs, hid.ReportDirAccOut)
+	if got := e.MaxFrameSize(); got != 254 {
+		t.Errorf("Encoder.MaxFrameSize() = %d, want %d", got, 254)
+	}
+	if err := e.WriteFrame([]byte{0x01, 0x02}); err != nil {
+		t.Fatalf("Encoder.WriteFrame() error = %v", err)
+	}
+	want := []hid.Report{{ID: 0x0D, LinkControl: hid.LinkControlDone, Data: []byte{0x01, 0x02, 0x00, 0x00}}}
+	if !reflect.DeepEqual(rw.reports, want) {
+		t.Errorf("Encoder.WriteFrame() = [%+v], want [%+v]", rw.reports, want)
+	}
+}
+
+var decoderTests = []struct {
+	name       string
+	reportDefs hid.ReportDefs
+	want       []byte
+	reports    []hid.Report
+
+	wantErr bool
+}{
+	{"report-1-pkt-1", testReportDefs1, []byte{0x01}, []hid.Report{
+		{ID: 0x01, LinkControl: hid.LinkControlDone, Data: []byte{0x01}},
+	}, false},
+	{"report-1-pkt-2", testReportDefs1, []byte{0x01, 0x02}, []hid.Report{
+		{ID: 0x01, LinkControl: hid.LinkControlMoreToFollow, Data: []byte{0x01}},
+		{ID: 0x01, LinkControl: hid.LinkControlContinue, Data: []byte{0x02}},
+	}, false},
+	{"report-1-pkt-3", testReportDefs1, []byte{0x01, 0x02, 0x03}, []hid.Report{
+		{ID: 0x01, LinkControl: hid.LinkControlMoreToFollow, Data: []byte{0x01}},
+		{ID: 0x01, LinkControl: hid.LinkControlContinue | hid.LinkControlMoreToFollow, Data: []byte{0x02}},
+		{ID: 0x01, LinkControl: hid.LinkControlContinue, Data: []byte{0x03}},
+	}, false},
+
+	{"report-2-pkt-1", testReportDefs2, []byte{0x01}, []hid.Report{
+		{ID: 0x01, LinkControl: hid.LinkControlDone, Data: []byte{0x01}},
+	}, false},
+	{"report-2-pkt-2", testReportDefs2, []byte{0x01, 0x02}, []hid.Report{
+		{ID: 0x02, LinkControl: hid.LinkControlDone, Data: []byte{0x01, 0x02}},
+	}, false},
+	{"report-2-pkt-3", testReportDefs2, []byte{0x01, 0x02, 0x03}, []hid.Report{
+		{ID: 0x02, LinkControl: hid.LinkControlMoreToFollow, Data: []byte{0x01, 0x02}},
+		{ID: 0x01, LinkControl: hid.LinkControlContinue, Data: []byte{0x03}},
+	}, false},
+
+	{"report-3-pkt-1", testReportDefs3, []byte{0x01, 0x02, 0x00}, []hid.Report{
+		{ID: 0x01, LinkControl: hid.LinkControlDone, Data: []byte{0x01, 0x02, 0x00}},
+	}, false},
+}
+
+func TestHidDecoder(t *testing.T) {
+	for _, tt := range decoderTests {
 		t.Run(tt.name, func(t *testing.T) {
 
 			rr := &testReportReader{
@@ -152,6 +191,104 @@ func TestHidDecoder(t *testing.T) {
 	}
 }
 
+func TestDecoder_LinkControl(t *testing.T) {
+	const (
+		done     = hid.LinkControlDone
+		more     = hid.LinkControlMoreToFollow
+		cont     = hid.LinkControlContinue
+		contMore = hid.LinkControlContinue | hid.LinkControlMoreToFollow
+	)
+	report := func(lc hid.LinkControl, data ...byte) hid.Report {
+		return hid.Report{ID: 0x01, LinkControl: lc, Data: data}
+	}
+	type result struct {
+		frame []byte
+		err   error
+	}
+	type dropped struct {
+		partial []byte
+		err     error
+	}
+	tests := []struct {
+		name        string
+		maxFrameLen int
+		reports     []hid.Report
+		want        []result
+		wantDropped []dropped
+	}{
+		{"lone-continue", 0, []hid.Report{
+			report(cont, 0x01),
+			report(done, 0x02),
+		}, []result{{err: hid.ErrUnexpectedContinue}, {frame: []byte{0x02}}}, nil},
+		{"orphan-frame-skipped", 0, []hid.Report{
+			report(contMore, 0x01),
+			report(contMore, 0x02),
+			report(cont, 0x03),
+			report(done, 0x04),
+		}, []result{{err: hid.ErrUnexpectedContinue}, {frame: []byte{0x04}}}, nil},
+		{"interrupted-by-more", 0, []hid.Report{
+			report(more, 0x01),
+			report(more, 0x02),
+			report(cont, 0x03),
+		}, []result{{frame: []byte{0x02, 0x03}}}, []dropped{{[]byte{0x01}, hid.ErrFrameInterrupted}}},
+		{"interrupted-by-done", 0, []hid.Report{
+			report(more, 0x01),
+			report(contMore, 0x02),
+			report(done, 0x03),
+		}, []result{{frame: []byte{0x03}}}, []dropped{{[]byte{0x01, 0x02}, hid.ErrFrameInterrupted}}},
+		{"unknown-link-control", 0, []hid.Report{
+			report(more, 0x01),
+			report(0x04, 0x02),
+			report(cont, 0x03),
+			report(done, 0x04),
+		}, []result{{err: hid.ErrUnknownLinkControl}, {err: hid.ErrUnexpectedContinue}, {frame: []byte{0x04}}},
+			[]dropped{{[]byte{0x01}, hid.ErrUnknownLinkControl}}},
+		{"unknown-report", 0, []hid.Report{
+			report(more, 0x01),
+			{ID: 0x09, LinkControl: cont, Data: []byte{0x02}},
+			report(done, 0x03),
+		}, []result{{err: hid.ErrUnknownReport}, {frame: []byte{0x03}}}, []dropped{{[]byte{0x01}, hid.ErrUnknownReport}}},
+		{"max-frame-len", 2, []hid.Report{
+			report(more, 0x01),
+			report(contMore, 0x02),
+			report(contMore, 0x03),
+			report(cont, 0x04),
+			report(more, 0x05),
+			report(cont, 0x06),
+		}, []result{{err: hid.ErrFrameTooLarge}, {frame: []byte{0x05, 0x06}}}, []dropped{{[]byte{0x01, 0x02}, hid.ErrFrameTooLarge}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := &testReportReader{reports: tt.reports}
+			dec := hid.NewDecoder(rr, testReportDefs2)
+			dec.MaxFrameLen = tt.maxFrameLen
+			var gotDropped []dropped
+			dec.Dropped = func(partial []byte, err error) {
+				gotDropped = append(gotDropped, dropped{append([]byte(nil), partial...), err})
+			}
+			for i, want := range tt.want {
+				frame, err := dec.ReadFrame()
+				if want.err != nil {
+					var reportErr *hid.ReportError
+					if !errors.As(err, &reportErr) || !errors.Is(err, want.err) {
+						t.Errorf("ReadFrame() #%d error = %v, want %v", i, err, want.err)
+					}
+					continue
+				}
+				if err != nil || !reflect.DeepEqual(frame, want.frame) {
+					t.Errorf("ReadFrame() #%d = %v, %v, want %v", i, frame, err, want.frame)
+				}
+			}
+			if _, err := dec.ReadFrame(); err != io.EOF {
+				t.Errorf("ReadFrame() error = %v, want EOF", err)
+			}
+			if !reflect.DeepEqual(gotDropped, tt.wantDropped) {
+				t.Errorf("Decoder.Dropped = %v, want %v", gotDropped, tt.wantDropped)
+			}
+		})
+	}
+}
+
 func BenchmarkDecoder(b *testing.B) {
 	report := []byte{
 		0x12, 0x00, 0x55, 0x28, 0x0a, 0x03, 0x03, 0xe7,
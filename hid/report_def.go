@@ -1,7 +1,6 @@
 package hid
 
 import (
-	"errors"
 	"fmt"
 )
 
@@ -74,12 +73,23 @@ func (defs ReportDefs) Pick(payloadSize int, dir ReportDir) (ReportDef, error) {
 	}
 
 	if def == nil {
-		return ReportDef{}, errors.New("no matching report found")
+		return ReportDef{}, errNoReports
 	} else {
 		return *def, nil
 	}
 }
 
+// MaxPayload returns the largest payload of the reports in the direction
+func (defs ReportDefs) MaxPayload(dir ReportDir) int {
+	max := 0
+	for i := range defs {
+		if defs[i].Dir == dir && defs[i].MaxPayload() > max {
+			max = defs[i].MaxPayload()
+		}
+	}
+	return max
+}
+
 // Find finds the report type based on id
 func (defs ReportDefs) Find(id int) (ReportDef, error) {
 	for i := range defs {
@@ -16,6 +16,9 @@ type ReportWriter interface {
 type SingleReport []byte
 
 func (s SingleReport) ReadReport() (Report, error) {
+	if len(s) < 2 {
+		return Report{}, io.ErrUnexpectedEOF
+	}
 	return Report{
 		ID:          s[0],
 		LinkControl: LinkControl(s[1]),
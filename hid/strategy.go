@@ -0,0 +1,154 @@
+package hid
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ReportStrategy chooses the reports of the direction dir that carry
+// a frame of frameLen bytes and returns them in the order they are sent.
+// Every report but the last is filled up to its MaxPayload.
+type ReportStrategy func(defs ReportDefs, dir ReportDir, frameLen int) ([]ReportDef, error)
+
+// reportWireSize is the number of bytes a report takes on the wire:
+// the report id, the link control and the data
+func reportWireSize(def ReportDef) int {
+	return 1 + def.Len
+}
+
+// PickGreedy takes the first report that fits the remaining bytes
+// or else the largest one, see ReportDefs.Pick.
+// It is the strategy of an Encoder without one.
+func PickGreedy(defs ReportDefs, dir ReportDir, frameLen int) ([]ReportDef, error) {
+	return appendGreedy(nil, defs, dir, frameLen)
+}
+
+// appendGreedy appends the greedy plan to plan,
+// so that the Encoder can reuse it
+func appendGreedy(plan []ReportDef, defs ReportDefs, dir ReportDir, frameLen int) ([]ReportDef, error) {
+	for left := frameLen; left > 0; {
+		def, err := defs.Pick(left, dir)
+		if err != nil {
+			return nil, err
+		}
+		if def.MaxPayload() < 1 {
+			return nil, fmt.Errorf("report %#02x has no room for data", def.ID)
+		}
+		plan = append(plan, def)
+		left -= def.MaxPayload()
+	}
+	return plan, nil
+}
+
+// PickMinBytes minimizes the bytes on the wire, i.e. the padding
+// of the last report, then the number of reports
+func PickMinBytes(defs ReportDefs, dir ReportDir, frameLen int) ([]ReportDef, error) {
+	return pickOptimal(defs, dir, frameLen, func(a, b planCost) bool {
+		if a.bytes != b.bytes {
+			return a.bytes < b.bytes
+		}
+		return a.reports < b.reports
+	})
+}
+
+// PickMinReports minimizes the number of reports, i.e. USB transfers,
+// then the bytes on the wire
+func PickMinReports(defs ReportDefs, dir ReportDir, frameLen int) ([]ReportDef, error) {
+	return pickOptimal(defs, dir, frameLen, func(a, b planCost) bool {
+		if a.reports != b.reports {
+			return a.reports < b.reports
+		}
+		return a.bytes < b.bytes
+	})
+}
+
+type planCost struct {
+	bytes, reports int
+}
+
+func (c planCost) add(def ReportDef) planCost {
+	return planCost{bytes: c.bytes + reportWireSize(def), reports: c.reports + 1}
+}
+
+var errNoReports = errors.New("no matching report found")
+
+// pickOptimal finds the cheapest plan by dynamic programming over
+// the number of bytes left: best[n] is the cheapest plan for n bytes
+// and first[n] its first report.
+func pickOptimal(defs ReportDefs, dir ReportDir, frameLen int, less func(a, b planCost) bool) ([]ReportDef, error) {
+	var candidates []ReportDef
+	for _, def := range defs {
+		if def.Dir == dir && def.MaxPayload() > 0 {
+			candidates = append(candidates, def)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, errNoReports
+	}
+	if frameLen <= 0 {
+		return nil, nil
+	}
+
+	best := make([]planCost, frameLen+1)
+	first := make([]int, frameLen+1)
+	for n := 1; n <= frameLen; n++ {
+		for i, def := range candidates {
+			rest := n - def.MaxPayload()
+			if rest < 0 {
+				rest = 0
+			}
+			c := best[rest].add(def)
+			if i == 0 || less(c, best[n]) {
+				best[n], first[n] = c, i
+			}
+		}
+	}
+
+	plan := make([]ReportDef, 0, best[frameLen].reports)
+	for n := frameLen; n > 0; n -= candidates[first[n]].MaxPayload() {
+		plan = append(plan, candidates[first[n]])
+	}
+	return plan, nil
+}
+
+// Memoize caches the plans of s by direction and frame length,
+// so that an optimizer runs once per frame length.
+// Only the frames that fit in the largest report are cached, so the cache
+// holds at most one plan per length up to ReportDefs.MaxPayload,
+// longer frames are planned every time.
+// The cache assumes the ReportDefs do not change,
+// use a Memoize per Encoder. The plans are shared and must not be modified.
+func Memoize(s ReportStrategy) ReportStrategy {
+	var mu sync.Mutex
+	// the plans by direction and frame length
+	var plans [2][][]ReportDef
+	return func(defs ReportDefs, dir ReportDir, frameLen int) ([]ReportDef, error) {
+		if int(dir) >= len(plans) {
+			return s(defs, dir, frameLen)
+		}
+		mu.Lock()
+		if plans[dir] == nil {
+			plans[dir] = make([][]ReportDef, defs.MaxPayload(dir)+1)
+		}
+		cache := plans[dir]
+		var plan []ReportDef
+		if frameLen < len(cache) {
+			plan = cache[frameLen]
+		}
+		mu.Unlock()
+		if plan != nil {
+			return plan, nil
+		}
+		plan, err := s(defs, dir, frameLen)
+		if err != nil {
+			return nil, err
+		}
+		if frameLen < len(cache) {
+			mu.Lock()
+			cache[frameLen] = plan
+			mu.Unlock()
+		}
+		return plan, nil
+	}
+}
@@ -0,0 +1,203 @@
+package hid_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/oandrew/ipod/hid"
+)
+
+var testStrategies = []struct {
+	name     string
+	strategy hid.ReportStrategy
+}{
+	{"greedy", hid.PickGreedy},
+	{"min-bytes", hid.PickMinBytes},
+	{"min-reports", hid.PickMinReports},
+}
+
+func reportIDs(plan []hid.ReportDef) []int {
+	ids := []int{}
+	for _, def := range plan {
+		ids = append(ids, def.ID)
+	}
+	return ids
+}
+
+// planCost returns the bytes on the wire and the number of reports of a plan
+func planCost(plan []hid.ReportDef) (bytes, reports int) {
+	for _, def := range plan {
+		bytes += 1 + def.Len
+	}
+	return bytes, len(plan)
+}
+
+func TestReportStrategy(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy hid.ReportStrategy
+		frameLen int
+		want     []int
+	}{
+		{"greedy-empty", hid.PickGreedy, 0, []int{}},
+		{"greedy-small", hid.PickGreedy, 3, []int{0x01}},
+		{"greedy-260", hid.PickGreedy, 260, []int{0x0A}},
+		{"greedy-1000", hid.PickGreedy, 1000, []int{0x0C, 0x09}},
+		{"min-bytes-empty", hid.PickMinBytes, 0, []int{}},
+		{"min-bytes-small", hid.PickMinBytes, 3, []int{0x01}},
+		{"min-bytes-260", hid.PickMinBytes, 260, []int{0x01, 0x09}},
+		{"min-bytes-1000", hid.PickMinBytes, 1000, []int{0x03, 0x07, 0x0A, 0x0B}},
+		{"min-reports-260", hid.PickMinReports, 260, []int{0x0A}},
+		{"min-reports-1000", hid.PickMinReports, 1000, []int{0x09, 0x0C}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := tt.strategy(hid.DefaultReportDefs, hid.ReportDirAccIn, tt.frameLen)
+			if err != nil {
+				t.Fatalf("ReportStrategy() error = %v", err)
+			}
+			if got := reportIDs(plan); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReportStrategy() = %#02x, want %#02x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReportStrategy_Optimal(t *testing.T) {
+	for _, dir := range []hid.ReportDir{hid.ReportDirAccIn, hid.ReportDirAccOut} {
+		for n := 1; n <= 2000; n++ {
+			plans := make(map[string][]hid.ReportDef)
+			for _, s := range testStrategies {
+				plan, err := s.strategy(hid.DefaultReportDefs, dir, n)
+				if err != nil {
+					t.Fatalf("%s(%d) error = %v", s.name, n, err)
+				}
+				// every report but the last is full
+				carried := 0
+				for i, def := range plan {
+					if def.Dir != dir {
+						t.Fatalf("%s(%d) picked report %#02x of the wrong direction", s.name, n, def.ID)
+					}
+					if i < len(plan)-1 && carried+def.MaxPayload() >= n {
+						t.Fatalf("%s(%d) = %#02x, the plan is too long", s.name, n, reportIDs(plan))
+					}
+					carried += def.MaxPayload()
+				}
+				if carried < n {
+					t.Fatalf("%s(%d) = %#02x, the plan is too short", s.name, n, reportIDs(plan))
+				}
+				plans[s.name] = plan
+			}
+			greedyBytes, greedyReports := planCost(plans["greedy"])
+			if bytes, _ := planCost(plans["min-bytes"]); bytes > greedyBytes {
+				t.Errorf("PickMinBytes(%d) = %d bytes, greedy %d bytes", n, bytes, greedyBytes)
+			}
+			if _, reports := planCost(plans["min-reports"]); reports > greedyReports {
+				t.Errorf("PickMinReports(%d) = %d reports, greedy %d reports", n, reports, greedyReports)
+			}
+		}
+	}
+}
+
+func TestReportStrategy_Errors(t *testing.T) {
+	defs := hid.ReportDefs{{ID: 0x01, Len: 9, Dir: hid.ReportDirAccOut}}
+	for _, s := range testStrategies {
+		t.Run(s.name, func(t *testing.T) {
+			if _, err := s.strategy(defs, hid.ReportDirAccIn, 10); err == nil {
+				t.Errorf("ReportStrategy() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestMemoize(t *testing.T) {
+	calls := 0
+	s := hid.Memoize(func(defs hid.ReportDefs, dir hid.ReportDir, frameLen int) ([]hid.ReportDef, error) {
+		calls++
+		return hid.PickMinBytes(defs, dir, frameLen)
+	})
+	for i := 0; i < 2; i++ {
+		for _, dir := range []hid.ReportDir{hid.ReportDirAccIn, hid.ReportDirAccOut} {
+			plan, err := s(hid.DefaultReportDefs, dir, 200)
+			if err != nil {
+				t.Fatalf("Memoize() error = %v", err)
+			}
+			want, _ := hid.PickMinBytes(hid.DefaultReportDefs, dir, 200)
+			if !reflect.DeepEqual(plan, want) {
+				t.Errorf("Memoize() = %#02x, want %#02x", reportIDs(plan), reportIDs(want))
+			}
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Memoize() called the strategy %d times, want 2", calls)
+	}
+
+	// frames longer than the largest report are not cached
+	long := hid.DefaultReportDefs.MaxPayload(hid.ReportDirAccIn) + 1
+	for i := 0; i < 2; i++ {
+		if _, err := s(hid.DefaultReportDefs, hid.ReportDirAccIn, long); err != nil {
+			t.Fatalf("Memoize() error = %v", err)
+		}
+	}
+	if calls != 4 {
+		t.Errorf("Memoize() called the strategy %d times for long frames, want 4", calls)
+	}
+}
+
+func TestEncoder_Strategy(t *testing.T) {
+	data := make([]byte, 260)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	rw := &testReportWriter{}
+	e := hid.NewEncoderDefault(rw)
+	e.Strategy = hid.PickMinBytes
+	if err := e.WriteFrame(data); err != nil {
+		t.Fatalf("Encoder.WriteFrame() error = %v", err)
+	}
+	if got, want := len(rw.reports), 2; got != want {
+		t.Fatalf("Encoder.WriteFrame() wrote %d reports, want %d", got, want)
+	}
+	rr := &testReportReader{reports: rw.reports}
+	frame, err := hid.NewDecoderDefault(rr).ReadFrame()
+	if err != nil {
+		t.Fatalf("Decoder.ReadFrame() error = %v", err)
+	}
+	if !reflect.DeepEqual(frame, data) {
+		t.Errorf("Decoder.ReadFrame() = %x, want %x", frame, data)
+	}
+}
+
+// countingReportWriter counts the reports and the bytes on the wire
+type countingReportWriter struct {
+	reports, bytes int
+}
+
+func (w *countingReportWriter) WriteReport(report hid.Report) error {
+	w.reports++
+	w.bytes += 2 + len(report.Data)
+	return nil
+}
+
+func BenchmarkEncoderStrategy(b *testing.B) {
+	for _, frameLen := range []int{47, 260, 1000, 4096} {
+		frame := make([]byte, frameLen)
+		strategies := append(testStrategies, struct {
+			name     string
+			strategy hid.ReportStrategy
+		}{"memo-min-bytes", hid.Memoize(hid.PickMinBytes)})
+		for _, s := range strategies {
+			b.Run(fmt.Sprintf("%s/%d", s.name, frameLen), func(b *testing.B) {
+				w := &countingReportWriter{}
+				e := hid.NewEncoderDefault(w)
+				e.Strategy = s.strategy
+				for i := 0; i < b.N; i++ {
+					e.WriteFrame(frame)
+				}
+				b.ReportMetric(float64(w.bytes)/float64(b.N), "wire-bytes/frame")
+				b.ReportMetric(float64(w.reports)/float64(b.N), "reports/frame")
+			})
+		}
+	}
+}
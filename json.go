@@ -0,0 +1,95 @@
+package ipod
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// commandJSON is the JSON form of a Command.
+// The lingo and command names are the parts of the PayloadName,
+// they are empty for an UnknownPayload.
+type commandJSON struct {
+	Lingo       string          `json:"lingo,omitempty"`
+	Command     string          `json:"command,omitempty"`
+	LingoID     uint16          `json:"lingo_id"`
+	CmdID       uint16          `json:"cmd_id"`
+	Transaction *Transaction    `json:"transaction,omitempty"`
+	Payload     json.RawMessage `json:"payload"`
+}
+
+// MarshalJSON encodes the command with the names of its lingo and payload,
+// i.e.
+//
+//	{"lingo":"general","command":"ACK","lingo_id":0,"cmd_id":2,"transaction":1,"payload":{"Status":0,"CmdID":5}}
+func (cmd *Command) MarshalJSON() ([]byte, error) {
+	payload, err := json.Marshal(cmd.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("ipod.Command marshal json: %v", err)
+	}
+	v := commandJSON{
+		LingoID:     cmd.ID.LingoID(),
+		CmdID:       cmd.ID.CmdID(),
+		Transaction: cmd.Transaction.Copy(),
+		Payload:     payload,
+	}
+	if _, ok := cmd.Payload.(UnknownPayload); !ok && cmd.Payload != nil {
+		v.Lingo, v.Command = splitPayloadName(PayloadName(cmd.Payload))
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes the command using the DefaultRegistry
+func (cmd *Command) UnmarshalJSON(data []byte) error {
+	return DefaultRegistry.UnmarshalCommandJSON(cmd, data)
+}
+
+// UnmarshalCommandJSON decodes the JSON form of a command into cmd.
+// The payload type is found by the lingo and command names,
+// without them the IDs are used and the payload is an UnknownPayload.
+func (r *Registry) UnmarshalCommandJSON(cmd *Command, data []byte) error {
+	var v commandJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("ipod.Command unmarshal json: %v", err)
+	}
+	cmd.Transaction = v.Transaction
+	if v.Command == "" {
+		var payload UnknownPayload
+		if err := unmarshalJSONPayload(v.Payload, &payload); err != nil {
+			return err
+		}
+		cmd.ID = NewLingoCmdID(v.LingoID, v.CmdID)
+		cmd.Payload = payload
+		return nil
+	}
+
+	name := v.Lingo + "." + v.Command
+	id, payload, ok := r.LookupName(name)
+	if !ok {
+		return fmt.Errorf("ipod.Command unmarshal json: unknown command %s", name)
+	}
+	if err := unmarshalJSONPayload(v.Payload, payload); err != nil {
+		return err
+	}
+	cmd.ID = id
+	cmd.Payload = payload
+	return nil
+}
+
+func unmarshalJSONPayload(data json.RawMessage, payload interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, payload); err != nil {
+		return fmt.Errorf("ipod.Command unmarshal json: payload: %v", err)
+	}
+	return nil
+}
+
+func splitPayloadName(name string) (lingo, command string) {
+	i := strings.LastIndex(name, ".")
+	if i < 0 {
+		return "", name
+	}
+	return name[:i], name[i+1:]
+}
@@ -0,0 +1,114 @@
+package ipod_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/oandrew/ipod"
+	"github.com/oandrew/ipod/lingo-extremote"
+	"github.com/oandrew/ipod/lingo-general"
+)
+
+func testJSONRoundTrip(t *testing.T, cmd *ipod.Command) {
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var got ipod.Command
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal(%s) error = %v", data, err)
+	}
+	if !reflect.DeepEqual(&got, cmd) {
+		t.Errorf("json round trip of %s = %#v, want %#v", data, &got, cmd)
+	}
+}
+
+func TestCommand_JSONRegistered(t *testing.T) {
+	for _, name := range ipod.DefaultRegistry.Names() {
+		t.Run(name, func(t *testing.T) {
+			id, payload, ok := ipod.DefaultRegistry.LookupName(name)
+			if !ok {
+				t.Fatalf("Registry.LookupName(%s) not found", name)
+			}
+			testJSONRoundTrip(t, &ipod.Command{
+				ID:          id,
+				Transaction: ipod.NewTransaction(1),
+				Payload:     payload,
+			})
+		})
+	}
+}
+
+func TestCommand_JSON(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  *ipod.Command
+	}{
+		{"no-transaction", &ipod.Command{
+			ID:      ipod.NewLingoCmdID(0x00, 0x02),
+			Payload: &general.ACK{Status: general.ACKStatusPending, CmdID: 0x05},
+		}},
+		{"unknown", &ipod.Command{
+			ID:          ipod.NewLingoCmdID(0xee, 0x01),
+			Transaction: ipod.NewTransaction(2),
+			Payload:     ipod.UnknownPayload{0x01, 0x02},
+		}},
+		{"track-info", &ipod.Command{
+			ID:          ipod.NewLingoCmdID(0x04, 0x000D),
+			Transaction: ipod.NewTransaction(3),
+			Payload: &extremote.ReturnIndexedPlayingTrackInfo{
+				InfoType: extremote.TrackInfoCaps,
+				Info:     &extremote.TrackCaps{Caps: 1, TrackLength: 180000, ChapterCount: 2},
+			},
+		}},
+		{"fid-tokens", &ipod.Command{
+			ID:          ipod.NewLingoCmdID(0x00, 0x39),
+			Transaction: ipod.NewTransaction(4),
+			Payload: &general.SetFIDTokenValues{
+				NumFIDTokenValues: 4,
+				FIDTokenValues: []general.FIDTokenValue{
+					{Len: 12, FIDType: 0x00, FIDSubtype: 0x00, Token: &general.FIDIdentifyToken{
+						NumLingoes: 2, AccLingoes: []byte{0x00, 0x04}, DeviceOptions: 2, DeviceID: 0x200,
+					}},
+					{Len: 10, FIDType: 0x00, FIDSubtype: 0x01, Token: &general.FIDAccCapsToken{
+						AccCapsBitmask: general.AccCapMask(general.AccCapAnalogLineOut),
+					}},
+					{Len: 7, FIDType: 0x00, FIDSubtype: 0x02, Token: &general.FIDAccInfoToken{
+						AccInfoType: general.AccInfoName, Value: []byte("acc\x00"),
+					}},
+					{Len: 4, FIDType: 0x02, FIDSubtype: 0x00, Token: []byte{0x01, 0x02}},
+				},
+			},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testJSONRoundTrip(t, tt.cmd)
+		})
+	}
+}
+
+func TestCommand_MarshalJSON(t *testing.T) {
+	cmd := &ipod.Command{
+		ID:          ipod.NewLingoCmdID(0x00, 0x02),
+		Transaction: ipod.NewTransaction(1),
+		Payload:     &general.ACK{Status: general.ACKStatusSuccess, CmdID: 0x05},
+	}
+	want := `{"lingo":"general","command":"ACK","lingo_id":0,"cmd_id":2,"transaction":1,"payload":{"Status":0,"CmdID":5}}`
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+}
+
+func TestCommand_UnmarshalJSONUnknownCommand(t *testing.T) {
+	var cmd ipod.Command
+	err := json.Unmarshal([]byte(`{"lingo":"general","command":"NoSuchCommand","payload":{}}`), &cmd)
+	if err == nil {
+		t.Errorf("json.Unmarshal() error = nil, want an error")
+	}
+}
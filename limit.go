@@ -0,0 +1,73 @@
+package ipod
+
+import (
+	"fmt"
+)
+
+// DefaultMaxPayload is the largest incoming packet payload
+// a Session accepts unless told otherwise,
+// the size advertised by ReturnTransportMaxPayloadSize
+const DefaultMaxPayload = 0xffff
+
+// PayloadSizeError is returned when a packet payload
+// exceeds the limit of its direction
+type PayloadSizeError struct {
+	ID   LingoCmdID
+	Dir  Dir
+	Size int
+	Max  int
+}
+
+func (e *PayloadSizeError) Error() string {
+	return fmt.Sprintf("ipod: cmd %v: %s payload size %d exceeds %d", e.ID, e.Dir, e.Size, e.Max)
+}
+
+// Splitter is implemented by payloads that have a multi-packet form
+type Splitter interface {
+	// Split returns the payloads to send instead,
+	// each encoding to at most max bytes
+	Split(max int) ([]interface{}, error)
+}
+
+// PayloadLimiter is implemented by CommandWriters that enforce
+// the negotiated payload sizes, i.e. a Session
+type PayloadLimiter interface {
+	CommandWriter
+	SetMaxPayload(dir Dir, n int)
+}
+
+// SetMaxPayload sets the payload size limit of the direction
+// if w is a PayloadLimiter, zero removes the limit
+func SetMaxPayload(w CommandWriter, dir Dir, n int) {
+	if pl, ok := w.(PayloadLimiter); ok {
+		pl.SetMaxPayload(dir, n)
+	}
+}
+
+// splitCommand splits cmd into commands whose packets are at most max bytes
+func splitCommand(cmd *Command, max int) ([]*Command, error) {
+	s, ok := cmd.Payload.(Splitter)
+	if !ok {
+		return nil, fmt.Errorf("payload %T can't be split", cmd.Payload)
+	}
+	header := cmd.ID.len()
+	if cmd.Transaction != nil {
+		header += 2
+	}
+	if max <= header {
+		return nil, fmt.Errorf("no room for the payload")
+	}
+	payloads, err := s.Split(max - header)
+	if err != nil {
+		return nil, err
+	}
+	cmds := make([]*Command, len(payloads))
+	for i, p := range payloads {
+		cmds[i] = &Command{
+			ID:          cmd.ID,
+			Transaction: cmd.Transaction.Copy(),
+			Payload:     p,
+		}
+	}
+	return cmds, nil
+}
@@ -0,0 +1,39 @@
+package ipod_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/oandrew/ipod"
+	"github.com/oandrew/ipod/lingo-general"
+)
+
+func TestSplitRetiPodAuthenticationInfo(t *testing.T) {
+	cert := bytes.Repeat([]byte{0xce}, 1000)
+	info := &general.RetiPodAuthenticationInfo{Major: 2, CertData: cert}
+	var s ipod.Splitter = info
+	parts, err := s.Split(404)
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	if len(parts) != 3 {
+		t.Fatalf("Split() = %d parts, want 3", len(parts))
+	}
+	var data []byte
+	for i, p := range parts {
+		part := p.(*general.RetiPodAuthenticationInfo)
+		if int(part.CertCurrentSection) != i || part.CertMaxSection != 2 || part.Major != 2 {
+			t.Errorf("Split() part %d section %d/%d", i, part.CertCurrentSection, part.CertMaxSection)
+		}
+		if 4+len(part.CertData) > 404 {
+			t.Errorf("Split() part %d size %d exceeds the max", i, 4+len(part.CertData))
+		}
+		data = append(data, part.CertData...)
+	}
+	if !bytes.Equal(data, cert) {
+		t.Errorf("Split() parts don't add up to the certificate")
+	}
+	if _, err := s.Split(4); err == nil {
+		t.Errorf("Split() error = nil for no room")
+	}
+}
@@ -9,11 +9,15 @@ import (
 )
 
 func init() {
-	ipod.RegisterLingos(LingoAudioID, Lingos)
+	ipod.RegisterPayloads(LingoAudioID, lingosPayloads)
+	ipod.RegisterACK(LingoAudioID, func(req *ipod.Command, status ipod.ACKStatus) interface{} {
+		return &iPodAck{Status: ACKStatus(status), CmdID: uint8(req.ID.CmdID())}
+	})
 }
 
 const LingoAudioID = 0x0a
 
+//go:generate go run ../cmd/ipodgen
 var Lingos struct {
 	AccAck                  `id:"0x00"`
 	iPodAck                 `id:"0x01"`
@@ -23,20 +27,40 @@ var Lingos struct {
 	SetVideoDelay           `id:"0x05"`
 }
 
+//go:generate stringer -type=ACKStatus
 type ACKStatus uint8
 
 const (
-	ACKStatusSuccess ACKStatus = 0x00
+	ACKStatusSuccess      ACKStatus = 0x00
+	ACKStatusBadParameter ACKStatus = 0x04
 )
 
 type AccAck struct {
 	Status ACKStatus
 	CmdID  uint8
 }
+
+func (s *AccAck) ACKStatus() ipod.ACKStatus {
+	return ipod.ACKStatus(s.Status)
+}
+
+func (s *AccAck) ACKCmdID() uint16 {
+	return uint16(s.CmdID)
+}
+
 type iPodAck struct {
 	Status ACKStatus
 	CmdID  uint8
 }
+
+func (s *iPodAck) ACKStatus() ipod.ACKStatus {
+	return ipod.ACKStatus(s.Status)
+}
+
+func (s *iPodAck) ACKCmdID() uint16 {
+	return uint16(s.CmdID)
+}
+
 type GetAccSampleRateCaps struct {
 }
 type RetAccSampleRateCaps struct {
@@ -0,0 +1,120 @@
+// Code generated by "ipodgen"; DO NOT EDIT.
+
+package audio
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/oandrew/ipod"
+)
+
+// lingosPayloads is the static payload table of Lingos
+var lingosPayloads = []ipod.PayloadDef{
+	{ID: 0x00, Size: 2, New: func() interface{} { return &AccAck{} }},
+	{ID: 0x01, Size: 2, New: func() interface{} { return &iPodAck{} }},
+	{ID: 0x02, Size: 0, New: func() interface{} { return &GetAccSampleRateCaps{} }},
+	{ID: 0x03, Size: -1, New: func() interface{} { return &RetAccSampleRateCaps{} }},
+	{ID: 0x04, Size: 12, New: func() interface{} { return &TrackNewAudioAttributes{} }},
+	{ID: 0x05, Size: 4, New: func() interface{} { return &SetVideoDelay{} }},
+}
+
+func (p *AccAck) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 2))
+}
+
+func (p *AccAck) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 2)...)
+	b := dst[n:]
+	b[0] = byte(p.Status)
+	b[1] = p.CmdID
+	return dst, nil
+}
+
+func (p *AccAck) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Status = ACKStatus(data[0])
+	p.CmdID = data[1]
+	return nil
+}
+
+func (p *iPodAck) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 2))
+}
+
+func (p *iPodAck) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 2)...)
+	b := dst[n:]
+	b[0] = byte(p.Status)
+	b[1] = p.CmdID
+	return dst, nil
+}
+
+func (p *iPodAck) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Status = ACKStatus(data[0])
+	p.CmdID = data[1]
+	return nil
+}
+
+func (*GetAccSampleRateCaps) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetAccSampleRateCaps) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetAccSampleRateCaps) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *TrackNewAudioAttributes) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 12))
+}
+
+func (p *TrackNewAudioAttributes) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 12)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], p.SampleRate)
+	binary.BigEndian.PutUint32(b[4:], p.SoundCheckValue)
+	binary.BigEndian.PutUint32(b[8:], p.VolumeAdjustment)
+	return dst, nil
+}
+
+func (p *TrackNewAudioAttributes) UnmarshalBinary(data []byte) error {
+	if len(data) < 12 {
+		return io.ErrUnexpectedEOF
+	}
+	p.SampleRate = binary.BigEndian.Uint32(data[0:])
+	p.SoundCheckValue = binary.BigEndian.Uint32(data[4:])
+	p.VolumeAdjustment = binary.BigEndian.Uint32(data[8:])
+	return nil
+}
+
+func (p *SetVideoDelay) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 4))
+}
+
+func (p *SetVideoDelay) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 4)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], p.Delay)
+	return dst, nil
+}
+
+func (p *SetVideoDelay) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Delay = binary.BigEndian.Uint32(data[0:])
+	return nil
+}
@@ -5,11 +5,15 @@ import (
 )
 
 func init() {
-	ipod.RegisterLingos(LingoDisplayRemoteID, Lingos)
+	ipod.RegisterPayloads(LingoDisplayRemoteID, lingosPayloads)
+	ipod.RegisterACK(LingoDisplayRemoteID, func(req *ipod.Command, status ipod.ACKStatus) interface{} {
+		return &ACK{Status: ACKStatus(status), CmdID: uint8(req.ID.CmdID())}
+	})
 }
 
 const LingoDisplayRemoteID = 0x03
 
+//go:generate go run ../cmd/ipodgen
 var Lingos struct {
 	ACK                        `id:"0x00"`
 	GetCurrentEQProfileIndex   `id:"0x01"`
@@ -46,17 +50,28 @@ var Lingos struct {
 	RetTrackArtworkTimes       `id:"0x20"`
 }
 
+//go:generate stringer -type=ACKStatus
 type ACKStatus uint8
 
 const (
-	ACKStatusSuccess ACKStatus = 0x00
-	ACKStatusPending ACKStatus = 0x06
+	ACKStatusSuccess      ACKStatus = 0x00
+	ACKStatusBadParameter ACKStatus = 0x04
+	ACKStatusPending      ACKStatus = 0x06
 )
 
 type ACK struct {
 	Status ACKStatus
 	CmdID  uint8
 }
+
+func (s *ACK) ACKStatus() ipod.ACKStatus {
+	return ipod.ACKStatus(s.Status)
+}
+
+func (s *ACK) ACKCmdID() uint16 {
+	return uint16(s.CmdID)
+}
+
 type GetCurrentEQProfileIndex struct {
 }
 type RetCurrentEQProfileIndex struct {
@@ -75,14 +90,14 @@ type GetIndexedEQProfileName struct {
 	EQProfileIndex uint32
 }
 type RetIndexedEQProfileName struct {
-	EQProfileName []byte
+	EQProfileName string `ipod:"cstring"`
 }
 type SetRemoteEventNotification struct {
 	EventMask uint32
 }
 type RemoteEventNotification struct {
 	EventNum  byte
-	EventData []byte
+	EventData []byte `ipod:"rest"`
 }
 type GetRemoteEventStatus struct {
 }
@@ -94,7 +109,7 @@ type GetiPodStateInfo struct {
 }
 type RetiPodStateInfo struct {
 	InfoType byte
-	InfoData []byte
+	InfoData []byte `ipod:"rest"`
 }
 type SetiPodStateInfo struct {
 	InfoType byte
@@ -135,7 +150,7 @@ type ArtworkFormat struct {
 	ImageHeight uint16
 }
 type RetArtworkFormats struct {
-	Formats []ArtworkFormat
+	Formats []ArtworkFormat `ipod:"rest"`
 }
 type GetTrackArtworkData struct {
 	TrackIndex uint32
@@ -167,5 +182,5 @@ type GetTrackArtworkTimes struct {
 	ArtworkCount uint16
 }
 type RetTrackArtworkTimes struct {
-	TimeOffset []uint32
+	TimeOffset []uint32 `ipod:"rest"`
 }
@@ -28,7 +28,7 @@ func HandleDispRemote(req *ipod.Command, tr ipod.CommandWriter, dev DeviceDispRe
 		})
 	case *GetIndexedEQProfileName:
 		ipod.Respond(req, tr, &RetIndexedEQProfileName{
-			EQProfileName: ipod.StringToBytes("Default"),
+			EQProfileName: "Default",
 		})
 	case *SetRemoteEventNotification:
 		ipod.Respond(req, tr, ackSuccess(req))
@@ -0,0 +1,575 @@
+// Code generated by "ipodgen"; DO NOT EDIT.
+
+package dispremote
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/oandrew/ipod"
+)
+
+// lingosPayloads is the static payload table of Lingos
+var lingosPayloads = []ipod.PayloadDef{
+	{ID: 0x00, Size: 2, New: func() interface{} { return &ACK{} }},
+	{ID: 0x01, Size: 0, New: func() interface{} { return &GetCurrentEQProfileIndex{} }},
+	{ID: 0x02, Size: 4, New: func() interface{} { return &RetCurrentEQProfileIndex{} }},
+	{ID: 0x03, Size: 5, New: func() interface{} { return &SetCurrentEQProfileIndex{} }},
+	{ID: 0x04, Size: 0, New: func() interface{} { return &GetNumEQProfiles{} }},
+	{ID: 0x05, Size: 4, New: func() interface{} { return &RetNumEQProfiles{} }},
+	{ID: 0x06, Size: 4, New: func() interface{} { return &GetIndexedEQProfileName{} }},
+	{ID: 0x07, Size: -1, New: func() interface{} { return &RetIndexedEQProfileName{} }},
+	{ID: 0x08, Size: 4, New: func() interface{} { return &SetRemoteEventNotification{} }},
+	{ID: 0x09, Size: -1, New: func() interface{} { return &RemoteEventNotification{} }},
+	{ID: 0x0A, Size: 0, New: func() interface{} { return &GetRemoteEventStatus{} }},
+	{ID: 0x0B, Size: 4, New: func() interface{} { return &RetRemoteEventStatus{} }},
+	{ID: 0x0C, Size: 1, New: func() interface{} { return &GetiPodStateInfo{} }},
+	{ID: 0x0D, Size: -1, New: func() interface{} { return &RetiPodStateInfo{} }},
+	{ID: 0x0E, Size: 2, New: func() interface{} { return &SetiPodStateInfo{} }},
+	{ID: 0x0F, Size: 0, New: func() interface{} { return &GetPlayStatus{} }},
+	{ID: 0x10, Size: 13, New: func() interface{} { return &RetPlayStatus{} }},
+	{ID: 0x11, Size: 4, New: func() interface{} { return &SetCurrentPlayingTrack{} }},
+	{ID: 0x12, Size: 7, New: func() interface{} { return &GetIndexedPlayingTrackInfo{} }},
+	{ID: 0x13, Size: 2, New: func() interface{} { return &RetIndexedPlayingTrackInfo{} }},
+	{ID: 0x14, Size: 0, New: func() interface{} { return &GetNumPlayingTracks{} }},
+	{ID: 0x15, Size: 4, New: func() interface{} { return &RetNumPlayingTracks{} }},
+	{ID: 0x16, Size: 0, New: func() interface{} { return &GetArtworkFormats{} }},
+	{ID: 0x17, Size: -1, New: func() interface{} { return &RetArtworkFormats{} }},
+	{ID: 0x18, Size: 10, New: func() interface{} { return &GetTrackArtworkData{} }},
+	{ID: 0x19, Size: 0, New: func() interface{} { return &RetTrackArtworkData{} }},
+	{ID: 0x1A, Size: 0, New: func() interface{} { return &GetPowerBatteryState{} }},
+	{ID: 0x1B, Size: 2, New: func() interface{} { return &RetPowerBatteryState{} }},
+	{ID: 0x1C, Size: 0, New: func() interface{} { return &GetSoundCheckState{} }},
+	{ID: 0x1D, Size: 1, New: func() interface{} { return &RetSoundCheckState{} }},
+	{ID: 0x1E, Size: 2, New: func() interface{} { return &SetSoundCheckState{} }},
+	{ID: 0x1F, Size: 10, New: func() interface{} { return &GetTrackArtworkTimes{} }},
+	{ID: 0x20, Size: -1, New: func() interface{} { return &RetTrackArtworkTimes{} }},
+}
+
+func (p *ACK) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 2))
+}
+
+func (p *ACK) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 2)...)
+	b := dst[n:]
+	b[0] = byte(p.Status)
+	b[1] = p.CmdID
+	return dst, nil
+}
+
+func (p *ACK) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Status = ACKStatus(data[0])
+	p.CmdID = data[1]
+	return nil
+}
+
+func (*GetCurrentEQProfileIndex) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetCurrentEQProfileIndex) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetCurrentEQProfileIndex) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *RetCurrentEQProfileIndex) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 4))
+}
+
+func (p *RetCurrentEQProfileIndex) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 4)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], p.CurrentEQIndex)
+	return dst, nil
+}
+
+func (p *RetCurrentEQProfileIndex) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	p.CurrentEQIndex = binary.BigEndian.Uint32(data[0:])
+	return nil
+}
+
+func (p *SetCurrentEQProfileIndex) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 5))
+}
+
+func (p *SetCurrentEQProfileIndex) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 5)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], p.CurrentEQIndex)
+	if p.RestoreOnExit {
+		b[4] = 1
+	}
+	return dst, nil
+}
+
+func (p *SetCurrentEQProfileIndex) UnmarshalBinary(data []byte) error {
+	if len(data) < 5 {
+		return io.ErrUnexpectedEOF
+	}
+	p.CurrentEQIndex = binary.BigEndian.Uint32(data[0:])
+	p.RestoreOnExit = data[4] != 0
+	return nil
+}
+
+func (*GetNumEQProfiles) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetNumEQProfiles) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetNumEQProfiles) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *RetNumEQProfiles) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 4))
+}
+
+func (p *RetNumEQProfiles) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 4)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], p.NumEQProfiles)
+	return dst, nil
+}
+
+func (p *RetNumEQProfiles) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	p.NumEQProfiles = binary.BigEndian.Uint32(data[0:])
+	return nil
+}
+
+func (p *GetIndexedEQProfileName) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 4))
+}
+
+func (p *GetIndexedEQProfileName) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 4)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], p.EQProfileIndex)
+	return dst, nil
+}
+
+func (p *GetIndexedEQProfileName) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	p.EQProfileIndex = binary.BigEndian.Uint32(data[0:])
+	return nil
+}
+
+func (p *SetRemoteEventNotification) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 4))
+}
+
+func (p *SetRemoteEventNotification) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 4)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], p.EventMask)
+	return dst, nil
+}
+
+func (p *SetRemoteEventNotification) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	p.EventMask = binary.BigEndian.Uint32(data[0:])
+	return nil
+}
+
+func (*GetRemoteEventStatus) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetRemoteEventStatus) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetRemoteEventStatus) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *RetRemoteEventStatus) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 4))
+}
+
+func (p *RetRemoteEventStatus) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 4)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], p.EventStatus)
+	return dst, nil
+}
+
+func (p *RetRemoteEventStatus) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	p.EventStatus = binary.BigEndian.Uint32(data[0:])
+	return nil
+}
+
+func (p *GetiPodStateInfo) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *GetiPodStateInfo) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = p.InfoType
+	return dst, nil
+}
+
+func (p *GetiPodStateInfo) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.InfoType = data[0]
+	return nil
+}
+
+func (p *SetiPodStateInfo) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 2))
+}
+
+func (p *SetiPodStateInfo) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 2)...)
+	b := dst[n:]
+	b[0] = p.InfoType
+	b[1] = p.InfoData
+	return dst, nil
+}
+
+func (p *SetiPodStateInfo) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return io.ErrUnexpectedEOF
+	}
+	p.InfoType = data[0]
+	p.InfoData = data[1]
+	return nil
+}
+
+func (*GetPlayStatus) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetPlayStatus) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetPlayStatus) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *RetPlayStatus) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 13))
+}
+
+func (p *RetPlayStatus) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 13)...)
+	b := dst[n:]
+	b[0] = p.PlayState
+	binary.BigEndian.PutUint32(b[1:], p.TrackIndex)
+	binary.BigEndian.PutUint32(b[5:], p.TrackLength)
+	binary.BigEndian.PutUint32(b[9:], p.TrackPos)
+	return dst, nil
+}
+
+func (p *RetPlayStatus) UnmarshalBinary(data []byte) error {
+	if len(data) < 13 {
+		return io.ErrUnexpectedEOF
+	}
+	p.PlayState = data[0]
+	p.TrackIndex = binary.BigEndian.Uint32(data[1:])
+	p.TrackLength = binary.BigEndian.Uint32(data[5:])
+	p.TrackPos = binary.BigEndian.Uint32(data[9:])
+	return nil
+}
+
+func (p *SetCurrentPlayingTrack) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 4))
+}
+
+func (p *SetCurrentPlayingTrack) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 4)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], p.TrackIndex)
+	return dst, nil
+}
+
+func (p *SetCurrentPlayingTrack) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	p.TrackIndex = binary.BigEndian.Uint32(data[0:])
+	return nil
+}
+
+func (p *GetIndexedPlayingTrackInfo) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 7))
+}
+
+func (p *GetIndexedPlayingTrackInfo) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 7)...)
+	b := dst[n:]
+	b[0] = p.InfoType
+	binary.BigEndian.PutUint32(b[1:], p.TrackIndex)
+	binary.BigEndian.PutUint16(b[5:], p.ChapterIndex)
+	return dst, nil
+}
+
+func (p *GetIndexedPlayingTrackInfo) UnmarshalBinary(data []byte) error {
+	if len(data) < 7 {
+		return io.ErrUnexpectedEOF
+	}
+	p.InfoType = data[0]
+	p.TrackIndex = binary.BigEndian.Uint32(data[1:])
+	p.ChapterIndex = binary.BigEndian.Uint16(data[5:])
+	return nil
+}
+
+func (p *RetIndexedPlayingTrackInfo) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 2))
+}
+
+func (p *RetIndexedPlayingTrackInfo) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 2)...)
+	b := dst[n:]
+	b[0] = p.InfoType
+	b[1] = p.InfoData
+	return dst, nil
+}
+
+func (p *RetIndexedPlayingTrackInfo) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return io.ErrUnexpectedEOF
+	}
+	p.InfoType = data[0]
+	p.InfoData = data[1]
+	return nil
+}
+
+func (*GetNumPlayingTracks) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetNumPlayingTracks) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetNumPlayingTracks) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *RetNumPlayingTracks) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 4))
+}
+
+func (p *RetNumPlayingTracks) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 4)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], p.NumPlayTracks)
+	return dst, nil
+}
+
+func (p *RetNumPlayingTracks) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	p.NumPlayTracks = binary.BigEndian.Uint32(data[0:])
+	return nil
+}
+
+func (*GetArtworkFormats) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetArtworkFormats) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetArtworkFormats) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *GetTrackArtworkData) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 10))
+}
+
+func (p *GetTrackArtworkData) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 10)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], p.TrackIndex)
+	binary.BigEndian.PutUint16(b[4:], p.FormatID)
+	binary.BigEndian.PutUint32(b[6:], p.TimeOffset)
+	return dst, nil
+}
+
+func (p *GetTrackArtworkData) UnmarshalBinary(data []byte) error {
+	if len(data) < 10 {
+		return io.ErrUnexpectedEOF
+	}
+	p.TrackIndex = binary.BigEndian.Uint32(data[0:])
+	p.FormatID = binary.BigEndian.Uint16(data[4:])
+	p.TimeOffset = binary.BigEndian.Uint32(data[6:])
+	return nil
+}
+
+func (*RetTrackArtworkData) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*RetTrackArtworkData) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*RetTrackArtworkData) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*GetPowerBatteryState) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetPowerBatteryState) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetPowerBatteryState) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *RetPowerBatteryState) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 2))
+}
+
+func (p *RetPowerBatteryState) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 2)...)
+	b := dst[n:]
+	b[0] = p.PowerState
+	b[1] = p.BatteryLevel
+	return dst, nil
+}
+
+func (p *RetPowerBatteryState) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return io.ErrUnexpectedEOF
+	}
+	p.PowerState = data[0]
+	p.BatteryLevel = data[1]
+	return nil
+}
+
+func (*GetSoundCheckState) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetSoundCheckState) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetSoundCheckState) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *RetSoundCheckState) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *RetSoundCheckState) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	if p.Enabled {
+		b[0] = 1
+	}
+	return dst, nil
+}
+
+func (p *RetSoundCheckState) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Enabled = data[0] != 0
+	return nil
+}
+
+func (p *SetSoundCheckState) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 2))
+}
+
+func (p *SetSoundCheckState) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 2)...)
+	b := dst[n:]
+	if p.Enabled {
+		b[0] = 1
+	}
+	if p.RestoreOnExit {
+		b[1] = 1
+	}
+	return dst, nil
+}
+
+func (p *SetSoundCheckState) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Enabled = data[0] != 0
+	p.RestoreOnExit = data[1] != 0
+	return nil
+}
+
+func (p *GetTrackArtworkTimes) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 10))
+}
+
+func (p *GetTrackArtworkTimes) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 10)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], p.TrackIndex)
+	binary.BigEndian.PutUint16(b[4:], p.FormatID)
+	binary.BigEndian.PutUint16(b[6:], p.ArtworkIndex)
+	binary.BigEndian.PutUint16(b[8:], p.ArtworkCount)
+	return dst, nil
+}
+
+func (p *GetTrackArtworkTimes) UnmarshalBinary(data []byte) error {
+	if len(data) < 10 {
+		return io.ErrUnexpectedEOF
+	}
+	p.TrackIndex = binary.BigEndian.Uint32(data[0:])
+	p.FormatID = binary.BigEndian.Uint16(data[4:])
+	p.ArtworkIndex = binary.BigEndian.Uint16(data[6:])
+	p.ArtworkCount = binary.BigEndian.Uint16(data[8:])
+	return nil
+}
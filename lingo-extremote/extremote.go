@@ -3,17 +3,22 @@ package extremote
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/json"
 	"io"
 
 	"github.com/oandrew/ipod"
 )
 
 func init() {
-	ipod.RegisterLingos(LingoExtRemotelID, Lingos)
+	ipod.RegisterPayloads(LingoExtRemotelID, lingosPayloads)
+	ipod.RegisterACK(LingoExtRemotelID, func(req *ipod.Command, status ipod.ACKStatus) interface{} {
+		return &ACK{Status: ACKStatus(status), CmdID: req.ID.CmdID()}
+	})
 }
 
 const LingoExtRemotelID = 0x04
 
+//go:generate go run ../cmd/ipodgen
 var Lingos struct {
 	ACK                                        `id:"0x0001"`
 	GetCurrentPlayingTrackChapterInfo          `id:"0x0002"`
@@ -49,7 +54,7 @@ var Lingos struct {
 	GetIndexedPlayingTrackAlbumName            `id:"0x0024"`
 	ReturnIndexedPlayingTrackAlbumName         `id:"0x0025"`
 	SetPlayStatusChangeNotification            `id:"0x0026"`
-	SetPlayStatusChangeNotificationShort       `id:"0x0026"`
+	SetPlayStatusChangeNotificationShort       `id:"0x0026" when:"byte0<=0x01"`
 	PlayStatusChangeNotification               `id:"0x0027"`
 	PlayCurrentSelection                       `id:"0x0028"`
 	PlayControl                                `id:"0x0029"`
@@ -81,17 +86,28 @@ var Lingos struct {
 	RetPBTrackInfo                             `id:"0x0043"`
 }
 
+//go:generate stringer -type=ACKStatus
 type ACKStatus uint8
 
 const (
-	ACKStatusSuccess ACKStatus = 0x00
-	ACKStatusFailed  ACKStatus = 0x02
+	ACKStatusSuccess      ACKStatus = 0x00
+	ACKStatusFailed       ACKStatus = 0x02
+	ACKStatusBadParameter ACKStatus = 0x04
 )
 
 type ACK struct {
 	Status ACKStatus
 	CmdID  uint16
 }
+
+func (s *ACK) ACKStatus() ipod.ACKStatus {
+	return ipod.ACKStatus(s.Status)
+}
+
+func (s *ACK) ACKCmdID() uint16 {
+	return s.CmdID
+}
+
 type GetCurrentPlayingTrackChapterInfo struct {
 }
 type ReturnCurrentPlayingTrackChapterInfo struct {
@@ -112,7 +128,7 @@ type GetCurrentPlayingTrackChapterName struct {
 	ChapterIndex int32
 }
 type ReturnCurrentPlayingTrackChapterName struct {
-	ChapterName []byte
+	ChapterName string `ipod:"cstring"`
 }
 type GetAudiobookSpeed struct {
 }
@@ -124,6 +140,7 @@ type SetAudiobookSpeed struct {
 	Speed byte //add enums
 }
 
+//go:generate stringer -type=TrackInfoType
 type TrackInfoType byte
 
 const (
@@ -174,20 +191,43 @@ func (s ReturnIndexedPlayingTrackInfo) MarshalBinary() ([]byte, error) {
 	return w.Bytes(), nil
 }
 
+// newTrackInfo returns the info of the type
+func newTrackInfo(infoType TrackInfoType) interface{} {
+	switch infoType {
+	case TrackInfoCaps:
+		return &TrackCaps{}
+	case TrackInfoDescription, TrackInfoLyrics:
+		return &TrackLongText{}
+	default:
+		return &struct{}{}
+	}
+}
+
+// UnmarshalJSON decodes the info into the type told by InfoType
+func (s *ReturnIndexedPlayingTrackInfo) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		InfoType TrackInfoType
+		Info     json.RawMessage
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	s.InfoType = raw.InfoType
+	s.Info = nil
+	if len(raw.Info) == 0 || string(raw.Info) == "null" {
+		return nil
+	}
+	s.Info = newTrackInfo(s.InfoType)
+	return json.Unmarshal(raw.Info, s.Info)
+}
+
 func (s *ReturnIndexedPlayingTrackInfo) UnmarshalBinary(data []byte) error {
 	r := bytes.NewReader(data)
 	if err := binary.Read(r, binary.BigEndian, &s.InfoType); err != nil {
 		return err
 	}
 
-	switch s.InfoType {
-	case TrackInfoCaps:
-		s.Info = &TrackCaps{}
-	case TrackInfoDescription, TrackInfoLyrics:
-		s.Info = &TrackLongText{}
-	default:
-		s.Info = &struct{}{}
-	}
+	s.Info = newTrackInfo(s.InfoType)
 	if err := binary.Read(r, binary.BigEndian, s.Info); err != nil {
 		return err
 	}
@@ -248,13 +288,14 @@ type RetTrackArtworkData struct {
 	BottomRightX uint16
 	BottomRightY uint16
 	RowSize      uint32
-	Data         []byte
+	Data         []byte `ipod:"rest"`
 }
 
 //ack
 type ResetDBSelection struct {
 }
 
+//go:generate stringer -type=DBCategoryType
 type DBCategoryType byte
 
 const (
@@ -291,6 +332,7 @@ type ReturnCategorizedDatabaseRecord struct {
 type GetPlayStatus struct {
 }
 
+//go:generate stringer -type=PlayerState
 type PlayerState byte
 
 const (
@@ -362,8 +404,36 @@ func (s *ReturnIndexedPlayingTrackAlbumName) UnmarshalBinary(data []byte) error
 	return nil
 }
 
+//go:generate stringer -type=PlayStatusChangeBit
+type PlayStatusChangeBit uint32
+
+const (
+	PlayStatusChangeBasicPlayState    PlayStatusChangeBit = 1 << 0
+	PlayStatusChangeExtendedPlayState PlayStatusChangeBit = 1 << 1
+	PlayStatusChangeTrackIndex        PlayStatusChangeBit = 1 << 2
+	PlayStatusChangeTrackTimeMs       PlayStatusChangeBit = 1 << 3
+	PlayStatusChangeTrackTimeSec      PlayStatusChangeBit = 1 << 4
+	PlayStatusChangeChapterIndex      PlayStatusChangeBit = 1 << 5
+	PlayStatusChangeChapterTimeMs     PlayStatusChangeBit = 1 << 6
+	PlayStatusChangeChapterTimeSec    PlayStatusChangeBit = 1 << 7
+	PlayStatusChangeTrackUID          PlayStatusChangeBit = 1 << 8
+	PlayStatusChangeTrackMediaType    PlayStatusChangeBit = 1 << 9
+	PlayStatusChangeTrackLyricsReady  PlayStatusChangeBit = 1 << 10
+	PlayStatusChangeTrackCaps         PlayStatusChangeBit = 1 << 11
+	PlayStatusChangeEngineContents    PlayStatusChangeBit = 1 << 12
+)
+
+// PlayStatusChangeMask is a bitmask of PlayStatusChangeBit
+type PlayStatusChangeMask uint32
+
+func (m PlayStatusChangeMask) String() string {
+	return ipod.FormatMask(uint64(m), func(bit uint64) string {
+		return PlayStatusChangeBit(bit).String()
+	})
+}
+
 type SetPlayStatusChangeNotification struct {
-	EventMask uint32
+	EventMask PlayStatusChangeMask
 }
 
 // SetPlayStatusChangeNotificationShort is another possible version of SetPlayStatusChangeNotification,
@@ -378,6 +448,7 @@ type PlayCurrentSelection struct {
 	SelectedTrackIndex int32
 }
 
+//go:generate stringer -type=PlayControlCmd
 type PlayControlCmd byte
 
 const (
@@ -409,6 +480,7 @@ type RetTrackArtworkTimes struct {
 	// empty for now
 }
 
+//go:generate stringer -type=ShuffleMode
 type ShuffleMode byte
 
 const (
@@ -427,6 +499,7 @@ type SetShuffle struct {
 	//restore on exit
 }
 
+//go:generate stringer -type=RepeatMode
 type RepeatMode byte
 
 const (
@@ -1,6 +1,8 @@
 package extremote
 
 import (
+	"context"
+
 	"github.com/oandrew/ipod"
 )
 
@@ -8,6 +10,57 @@ type DeviceExtRemote interface {
 	PlaybackStatus() (trackLength, trackPos uint32, state PlayerState)
 }
 
+// DeviceDatabase is implemented by devices that list their database,
+// the records of a category are numbered from zero.
+// Without it a category has a single empty record.
+type DeviceDatabase interface {
+	NumRecords(category DBCategoryType) int
+	Record(category DBCategoryType, index int) string
+}
+
+func numRecords(dev DeviceExtRemote, category DBCategoryType) int {
+	if db, ok := dev.(DeviceDatabase); ok {
+		return db.NumRecords(category)
+	}
+	return 1
+}
+
+// retrieveRecords sends the requested records, a record per command.
+// The listing stops when the request is cancelled.
+func retrieveRecords(ctx context.Context, req *ipod.Command, tr ipod.CommandWriter, dev DeviceExtRemote, msg *RetrieveCategorizedDatabaseRecords) error {
+	db, _ := dev.(DeviceDatabase)
+	end := numRecords(dev, msg.CategoryType)
+	// a count of -1 lists the records up to the last one
+	if msg.Count >= 0 && int64(msg.Offset)+int64(msg.Count) < int64(end) {
+		end = int(msg.Offset) + int(msg.Count)
+	}
+	for i := int(msg.Offset); i < end; i++ {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+		rec := &ReturnCategorizedDatabaseRecord{RecordCategoryIndex: uint32(i)}
+		if db != nil {
+			// keep the terminating NUL
+			copy(rec.String[:len(rec.String)-1], db.Record(msg.CategoryType, i))
+		}
+		ipod.Respond(req, tr, rec)
+	}
+	return nil
+}
+
+// goRequest runs a long request in a goroutine of its own
+// if tr is a RequestWriter, so that the session keeps reading
+// and the request can be cancelled
+func goRequest(tr ipod.CommandWriter, f func(ctx context.Context, w ipod.CommandWriter) error) error {
+	if rw, ok := tr.(*ipod.RequestWriter); ok {
+		rw.Go(f)
+		return nil
+	}
+	return f(ipod.RequestContext(tr), tr)
+}
+
 func ackSuccess(req *ipod.Command) *ACK {
 	return &ACK{Status: ACKStatusSuccess, CmdID: req.ID.CmdID()}
 }
@@ -34,7 +87,7 @@ func HandleExtRemote(req *ipod.Command, tr ipod.CommandWriter, dev DeviceExtRemo
 		})
 	case *GetCurrentPlayingTrackChapterName:
 		ipod.Respond(req, tr, &ReturnCurrentPlayingTrackChapterName{
-			ChapterName: ipod.StringToBytes("chapter"),
+			ChapterName: "chapter",
 		})
 	case *GetAudiobookSpeed:
 		ipod.Respond(req, tr, &ReturnAudiobookSpeed{
@@ -80,10 +133,12 @@ func HandleExtRemote(req *ipod.Command, tr ipod.CommandWriter, dev DeviceExtRemo
 		ipod.Respond(req, tr, ackSuccess(req))
 	case *GetNumberCategorizedDBRecords:
 		ipod.Respond(req, tr, &ReturnNumberCategorizedDBRecords{
-			RecordCount: 1,
+			RecordCount: int32(numRecords(dev, msg.CategoryType)),
 		})
 	case *RetrieveCategorizedDatabaseRecords:
-		ipod.Respond(req, tr, &ReturnCategorizedDatabaseRecord{})
+		return goRequest(tr, func(ctx context.Context, w ipod.CommandWriter) error {
+			return retrieveRecords(ctx, req, w, dev, msg)
+		})
 	case *GetPlayStatus:
 		ipod.Respond(req, tr, &ReturnPlayStatus{
 			TrackLength:   300 * 1000,
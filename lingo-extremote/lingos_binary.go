@@ -0,0 +1,1096 @@
+// Code generated by "ipodgen"; DO NOT EDIT.
+
+package extremote
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/oandrew/ipod"
+)
+
+// lingosPayloads is the static payload table of Lingos
+var lingosPayloads = []ipod.PayloadDef{
+	{ID: 0x0001, Size: 3, New: func() interface{} { return &ACK{} }},
+	{ID: 0x0002, Size: 0, New: func() interface{} { return &GetCurrentPlayingTrackChapterInfo{} }},
+	{ID: 0x0003, Size: 8, New: func() interface{} { return &ReturnCurrentPlayingTrackChapterInfo{} }},
+	{ID: 0x0004, Size: 4, New: func() interface{} { return &SetCurrentPlayingTrackChapter{} }},
+	{ID: 0x0005, Size: 4, New: func() interface{} { return &GetCurrentPlayingTrackChapterPlayStatus{} }},
+	{ID: 0x0006, Size: 8, New: func() interface{} { return &ReturnCurrentPlayingTrackChapterPlayStatus{} }},
+	{ID: 0x0007, Size: 4, New: func() interface{} { return &GetCurrentPlayingTrackChapterName{} }},
+	{ID: 0x0008, Size: -1, New: func() interface{} { return &ReturnCurrentPlayingTrackChapterName{} }},
+	{ID: 0x0009, Size: 0, New: func() interface{} { return &GetAudiobookSpeed{} }},
+	{ID: 0x000A, Size: 1, New: func() interface{} { return &ReturnAudiobookSpeed{} }},
+	{ID: 0x000B, Size: 1, New: func() interface{} { return &SetAudiobookSpeed{} }},
+	{ID: 0x000C, Size: 7, New: func() interface{} { return &GetIndexedPlayingTrackInfo{} }},
+	{ID: 0x000D, Size: -1, New: func() interface{} { return &ReturnIndexedPlayingTrackInfo{} }},
+	{ID: 0x000E, Size: 0, New: func() interface{} { return &GetArtworkFormats{} }},
+	{ID: 0x000F, Size: -1, New: func() interface{} { return &RetArtworkFormats{} }},
+	{ID: 0x0010, Size: 10, New: func() interface{} { return &GetTrackArtworkData{} }},
+	{ID: 0x0011, Size: -1, New: func() interface{} { return &RetTrackArtworkData{} }},
+	{ID: 0x0016, Size: 0, New: func() interface{} { return &ResetDBSelection{} }},
+	{ID: 0x0017, Size: 5, New: func() interface{} { return &SelectDBRecord{} }},
+	{ID: 0x0018, Size: 1, New: func() interface{} { return &GetNumberCategorizedDBRecords{} }},
+	{ID: 0x0019, Size: 4, New: func() interface{} { return &ReturnNumberCategorizedDBRecords{} }},
+	{ID: 0x001A, Size: 9, New: func() interface{} { return &RetrieveCategorizedDatabaseRecords{} }},
+	{ID: 0x001B, Size: 20, New: func() interface{} { return &ReturnCategorizedDatabaseRecord{} }},
+	{ID: 0x001C, Size: 0, New: func() interface{} { return &GetPlayStatus{} }},
+	{ID: 0x001D, Size: 9, New: func() interface{} { return &ReturnPlayStatus{} }},
+	{ID: 0x001E, Size: 0, New: func() interface{} { return &GetCurrentPlayingTrackIndex{} }},
+	{ID: 0x001F, Size: 4, New: func() interface{} { return &ReturnCurrentPlayingTrackIndex{} }},
+	{ID: 0x0020, Size: 4, New: func() interface{} { return &GetIndexedPlayingTrackTitle{} }},
+	{ID: 0x0021, Size: -1, New: func() interface{} { return &ReturnIndexedPlayingTrackTitle{} }},
+	{ID: 0x0022, Size: 4, New: func() interface{} { return &GetIndexedPlayingTrackArtistName{} }},
+	{ID: 0x0023, Size: -1, New: func() interface{} { return &ReturnIndexedPlayingTrackArtistName{} }},
+	{ID: 0x0024, Size: 4, New: func() interface{} { return &GetIndexedPlayingTrackAlbumName{} }},
+	{ID: 0x0025, Size: -1, New: func() interface{} { return &ReturnIndexedPlayingTrackAlbumName{} }},
+	{ID: 0x0026, Size: 4, New: func() interface{} { return &SetPlayStatusChangeNotification{} }},
+	{ID: 0x0026, Size: 1, New: func() interface{} { return &SetPlayStatusChangeNotificationShort{} }, When: "byte0<=0x01"},
+	{ID: 0x0027, Size: 1, New: func() interface{} { return &PlayStatusChangeNotification{} }},
+	{ID: 0x0028, Size: 4, New: func() interface{} { return &PlayCurrentSelection{} }},
+	{ID: 0x0029, Size: 1, New: func() interface{} { return &PlayControl{} }},
+	{ID: 0x002A, Size: 10, New: func() interface{} { return &GetTrackArtworkTimes{} }},
+	{ID: 0x002B, Size: 0, New: func() interface{} { return &RetTrackArtworkTimes{} }},
+	{ID: 0x002C, Size: 0, New: func() interface{} { return &GetShuffle{} }},
+	{ID: 0x002D, Size: 1, New: func() interface{} { return &ReturnShuffle{} }},
+	{ID: 0x002E, Size: 1, New: func() interface{} { return &SetShuffle{} }},
+	{ID: 0x002F, Size: 0, New: func() interface{} { return &GetRepeat{} }},
+	{ID: 0x0030, Size: 1, New: func() interface{} { return &ReturnRepeat{} }},
+	{ID: 0x0031, Size: 1, New: func() interface{} { return &SetRepeat{} }},
+	{ID: 0x0032, Size: 0, New: func() interface{} { return &SetDisplayImage{} }},
+	{ID: 0x0033, Size: 0, New: func() interface{} { return &GetMonoDisplayImageLimits{} }},
+	{ID: 0x0034, Size: 5, New: func() interface{} { return &ReturnMonoDisplayImageLimits{} }},
+	{ID: 0x0035, Size: 0, New: func() interface{} { return &GetNumPlayingTracks{} }},
+	{ID: 0x0036, Size: 4, New: func() interface{} { return &ReturnNumPlayingTracks{} }},
+	{ID: 0x0037, Size: 4, New: func() interface{} { return &SetCurrentPlayingTrack{} }},
+	{ID: 0x0038, Size: 6, New: func() interface{} { return &SelectSortDBRecord{} }},
+	{ID: 0x0039, Size: 0, New: func() interface{} { return &GetColorDisplayImageLimits{} }},
+	{ID: 0x003A, Size: 5, New: func() interface{} { return &ReturnColorDisplayImageLimits{} }},
+	{ID: 0x003B, Size: 1, New: func() interface{} { return &ResetDBSelectionHierarchy{} }},
+	{ID: 0x003C, Size: 0, New: func() interface{} { return &GetDBiTunesInfo{} }},
+	{ID: 0x003D, Size: 0, New: func() interface{} { return &RetDBiTunesInfo{} }},
+	{ID: 0x003E, Size: 0, New: func() interface{} { return &GetUIDTrackInfo{} }},
+	{ID: 0x003F, Size: 0, New: func() interface{} { return &RetUIDTrackInfo{} }},
+	{ID: 0x0040, Size: 0, New: func() interface{} { return &GetDBTrackInfo{} }},
+	{ID: 0x0041, Size: 0, New: func() interface{} { return &RetDBTrackInfo{} }},
+	{ID: 0x0042, Size: 0, New: func() interface{} { return &GetPBTrackInfo{} }},
+	{ID: 0x0043, Size: 0, New: func() interface{} { return &RetPBTrackInfo{} }},
+}
+
+func (p *ACK) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 3))
+}
+
+func (p *ACK) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 3)...)
+	b := dst[n:]
+	b[0] = byte(p.Status)
+	binary.BigEndian.PutUint16(b[1:], p.CmdID)
+	return dst, nil
+}
+
+func (p *ACK) UnmarshalBinary(data []byte) error {
+	if len(data) < 3 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Status = ACKStatus(data[0])
+	p.CmdID = binary.BigEndian.Uint16(data[1:])
+	return nil
+}
+
+func (*GetCurrentPlayingTrackChapterInfo) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetCurrentPlayingTrackChapterInfo) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetCurrentPlayingTrackChapterInfo) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *ReturnCurrentPlayingTrackChapterInfo) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 8))
+}
+
+func (p *ReturnCurrentPlayingTrackChapterInfo) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 8)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], uint32(p.CurrentChapterIndex))
+	binary.BigEndian.PutUint32(b[4:], uint32(p.ChapterCount))
+	return dst, nil
+}
+
+func (p *ReturnCurrentPlayingTrackChapterInfo) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 {
+		return io.ErrUnexpectedEOF
+	}
+	p.CurrentChapterIndex = int32(binary.BigEndian.Uint32(data[0:]))
+	p.ChapterCount = int32(binary.BigEndian.Uint32(data[4:]))
+	return nil
+}
+
+func (p *SetCurrentPlayingTrackChapter) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 4))
+}
+
+func (p *SetCurrentPlayingTrackChapter) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 4)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], uint32(p.ChapterIndex))
+	return dst, nil
+}
+
+func (p *SetCurrentPlayingTrackChapter) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	p.ChapterIndex = int32(binary.BigEndian.Uint32(data[0:]))
+	return nil
+}
+
+func (p *GetCurrentPlayingTrackChapterPlayStatus) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 4))
+}
+
+func (p *GetCurrentPlayingTrackChapterPlayStatus) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 4)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], uint32(p.CurrentChapterIndex))
+	return dst, nil
+}
+
+func (p *GetCurrentPlayingTrackChapterPlayStatus) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	p.CurrentChapterIndex = int32(binary.BigEndian.Uint32(data[0:]))
+	return nil
+}
+
+func (p *ReturnCurrentPlayingTrackChapterPlayStatus) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 8))
+}
+
+func (p *ReturnCurrentPlayingTrackChapterPlayStatus) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 8)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], p.ChapterLength)
+	binary.BigEndian.PutUint32(b[4:], p.ChapterPosition)
+	return dst, nil
+}
+
+func (p *ReturnCurrentPlayingTrackChapterPlayStatus) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 {
+		return io.ErrUnexpectedEOF
+	}
+	p.ChapterLength = binary.BigEndian.Uint32(data[0:])
+	p.ChapterPosition = binary.BigEndian.Uint32(data[4:])
+	return nil
+}
+
+func (p *GetCurrentPlayingTrackChapterName) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 4))
+}
+
+func (p *GetCurrentPlayingTrackChapterName) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 4)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], uint32(p.ChapterIndex))
+	return dst, nil
+}
+
+func (p *GetCurrentPlayingTrackChapterName) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	p.ChapterIndex = int32(binary.BigEndian.Uint32(data[0:]))
+	return nil
+}
+
+func (*GetAudiobookSpeed) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetAudiobookSpeed) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetAudiobookSpeed) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *ReturnAudiobookSpeed) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *ReturnAudiobookSpeed) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = p.Speed
+	return dst, nil
+}
+
+func (p *ReturnAudiobookSpeed) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Speed = data[0]
+	return nil
+}
+
+func (p *SetAudiobookSpeed) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *SetAudiobookSpeed) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = p.Speed
+	return dst, nil
+}
+
+func (p *SetAudiobookSpeed) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Speed = data[0]
+	return nil
+}
+
+func (p *GetIndexedPlayingTrackInfo) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 7))
+}
+
+func (p *GetIndexedPlayingTrackInfo) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 7)...)
+	b := dst[n:]
+	b[0] = byte(p.InfoType)
+	binary.BigEndian.PutUint32(b[1:], uint32(p.TrackIndex))
+	binary.BigEndian.PutUint16(b[5:], uint16(p.ChapterIndex))
+	return dst, nil
+}
+
+func (p *GetIndexedPlayingTrackInfo) UnmarshalBinary(data []byte) error {
+	if len(data) < 7 {
+		return io.ErrUnexpectedEOF
+	}
+	p.InfoType = TrackInfoType(data[0])
+	p.TrackIndex = int32(binary.BigEndian.Uint32(data[1:]))
+	p.ChapterIndex = int16(binary.BigEndian.Uint16(data[5:]))
+	return nil
+}
+
+func (*GetArtworkFormats) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetArtworkFormats) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetArtworkFormats) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *GetTrackArtworkData) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 10))
+}
+
+func (p *GetTrackArtworkData) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 10)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], uint32(p.TrackIndex))
+	binary.BigEndian.PutUint16(b[4:], p.FormatID)
+	binary.BigEndian.PutUint32(b[6:], p.Offset)
+	return dst, nil
+}
+
+func (p *GetTrackArtworkData) UnmarshalBinary(data []byte) error {
+	if len(data) < 10 {
+		return io.ErrUnexpectedEOF
+	}
+	p.TrackIndex = int32(binary.BigEndian.Uint32(data[0:]))
+	p.FormatID = binary.BigEndian.Uint16(data[4:])
+	p.Offset = binary.BigEndian.Uint32(data[6:])
+	return nil
+}
+
+func (*ResetDBSelection) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*ResetDBSelection) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*ResetDBSelection) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *SelectDBRecord) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 5))
+}
+
+func (p *SelectDBRecord) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 5)...)
+	b := dst[n:]
+	b[0] = byte(p.CategoryType)
+	binary.BigEndian.PutUint32(b[1:], uint32(p.RecordIndex))
+	return dst, nil
+}
+
+func (p *SelectDBRecord) UnmarshalBinary(data []byte) error {
+	if len(data) < 5 {
+		return io.ErrUnexpectedEOF
+	}
+	p.CategoryType = DBCategoryType(data[0])
+	p.RecordIndex = int32(binary.BigEndian.Uint32(data[1:]))
+	return nil
+}
+
+func (p *GetNumberCategorizedDBRecords) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *GetNumberCategorizedDBRecords) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = byte(p.CategoryType)
+	return dst, nil
+}
+
+func (p *GetNumberCategorizedDBRecords) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.CategoryType = DBCategoryType(data[0])
+	return nil
+}
+
+func (p *ReturnNumberCategorizedDBRecords) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 4))
+}
+
+func (p *ReturnNumberCategorizedDBRecords) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 4)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], uint32(p.RecordCount))
+	return dst, nil
+}
+
+func (p *ReturnNumberCategorizedDBRecords) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	p.RecordCount = int32(binary.BigEndian.Uint32(data[0:]))
+	return nil
+}
+
+func (p *RetrieveCategorizedDatabaseRecords) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 9))
+}
+
+func (p *RetrieveCategorizedDatabaseRecords) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 9)...)
+	b := dst[n:]
+	b[0] = byte(p.CategoryType)
+	binary.BigEndian.PutUint32(b[1:], p.Offset)
+	binary.BigEndian.PutUint32(b[5:], uint32(p.Count))
+	return dst, nil
+}
+
+func (p *RetrieveCategorizedDatabaseRecords) UnmarshalBinary(data []byte) error {
+	if len(data) < 9 {
+		return io.ErrUnexpectedEOF
+	}
+	p.CategoryType = DBCategoryType(data[0])
+	p.Offset = binary.BigEndian.Uint32(data[1:])
+	p.Count = int32(binary.BigEndian.Uint32(data[5:]))
+	return nil
+}
+
+func (p *ReturnCategorizedDatabaseRecord) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 20))
+}
+
+func (p *ReturnCategorizedDatabaseRecord) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 20)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], p.RecordCategoryIndex)
+	copy(b[4:20], p.String[:])
+	return dst, nil
+}
+
+func (p *ReturnCategorizedDatabaseRecord) UnmarshalBinary(data []byte) error {
+	if len(data) < 20 {
+		return io.ErrUnexpectedEOF
+	}
+	p.RecordCategoryIndex = binary.BigEndian.Uint32(data[0:])
+	copy(p.String[:], data[4:20])
+	return nil
+}
+
+func (*GetPlayStatus) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetPlayStatus) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetPlayStatus) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *ReturnPlayStatus) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 9))
+}
+
+func (p *ReturnPlayStatus) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 9)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], p.TrackLength)
+	binary.BigEndian.PutUint32(b[4:], p.TrackPosition)
+	b[8] = byte(p.State)
+	return dst, nil
+}
+
+func (p *ReturnPlayStatus) UnmarshalBinary(data []byte) error {
+	if len(data) < 9 {
+		return io.ErrUnexpectedEOF
+	}
+	p.TrackLength = binary.BigEndian.Uint32(data[0:])
+	p.TrackPosition = binary.BigEndian.Uint32(data[4:])
+	p.State = PlayerState(data[8])
+	return nil
+}
+
+func (*GetCurrentPlayingTrackIndex) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetCurrentPlayingTrackIndex) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetCurrentPlayingTrackIndex) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *ReturnCurrentPlayingTrackIndex) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 4))
+}
+
+func (p *ReturnCurrentPlayingTrackIndex) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 4)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], uint32(p.TrackIndex))
+	return dst, nil
+}
+
+func (p *ReturnCurrentPlayingTrackIndex) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	p.TrackIndex = int32(binary.BigEndian.Uint32(data[0:]))
+	return nil
+}
+
+func (p *GetIndexedPlayingTrackTitle) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 4))
+}
+
+func (p *GetIndexedPlayingTrackTitle) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 4)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], uint32(p.TrackIndex))
+	return dst, nil
+}
+
+func (p *GetIndexedPlayingTrackTitle) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	p.TrackIndex = int32(binary.BigEndian.Uint32(data[0:]))
+	return nil
+}
+
+func (p *GetIndexedPlayingTrackArtistName) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 4))
+}
+
+func (p *GetIndexedPlayingTrackArtistName) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 4)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], uint32(p.TrackIndex))
+	return dst, nil
+}
+
+func (p *GetIndexedPlayingTrackArtistName) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	p.TrackIndex = int32(binary.BigEndian.Uint32(data[0:]))
+	return nil
+}
+
+func (p *GetIndexedPlayingTrackAlbumName) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 4))
+}
+
+func (p *GetIndexedPlayingTrackAlbumName) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 4)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], uint32(p.TrackIndex))
+	return dst, nil
+}
+
+func (p *GetIndexedPlayingTrackAlbumName) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	p.TrackIndex = int32(binary.BigEndian.Uint32(data[0:]))
+	return nil
+}
+
+func (p *SetPlayStatusChangeNotification) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 4))
+}
+
+func (p *SetPlayStatusChangeNotification) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 4)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], uint32(p.EventMask))
+	return dst, nil
+}
+
+func (p *SetPlayStatusChangeNotification) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	p.EventMask = PlayStatusChangeMask(binary.BigEndian.Uint32(data[0:]))
+	return nil
+}
+
+func (p *SetPlayStatusChangeNotificationShort) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *SetPlayStatusChangeNotificationShort) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	if p.Enabled {
+		b[0] = 1
+	}
+	return dst, nil
+}
+
+func (p *SetPlayStatusChangeNotificationShort) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Enabled = data[0] != 0
+	return nil
+}
+
+func (p *PlayStatusChangeNotification) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *PlayStatusChangeNotification) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = p.Status
+	return dst, nil
+}
+
+func (p *PlayStatusChangeNotification) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Status = data[0]
+	return nil
+}
+
+func (p *PlayCurrentSelection) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 4))
+}
+
+func (p *PlayCurrentSelection) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 4)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], uint32(p.SelectedTrackIndex))
+	return dst, nil
+}
+
+func (p *PlayCurrentSelection) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	p.SelectedTrackIndex = int32(binary.BigEndian.Uint32(data[0:]))
+	return nil
+}
+
+func (p *PlayControl) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *PlayControl) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = byte(p.Cmd)
+	return dst, nil
+}
+
+func (p *PlayControl) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Cmd = PlayControlCmd(data[0])
+	return nil
+}
+
+func (p *GetTrackArtworkTimes) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 10))
+}
+
+func (p *GetTrackArtworkTimes) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 10)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], uint32(p.TrackIndex))
+	binary.BigEndian.PutUint16(b[4:], p.FormatID)
+	binary.BigEndian.PutUint16(b[6:], p.ArtworkIndex)
+	binary.BigEndian.PutUint16(b[8:], uint16(p.ArtworkCount))
+	return dst, nil
+}
+
+func (p *GetTrackArtworkTimes) UnmarshalBinary(data []byte) error {
+	if len(data) < 10 {
+		return io.ErrUnexpectedEOF
+	}
+	p.TrackIndex = int32(binary.BigEndian.Uint32(data[0:]))
+	p.FormatID = binary.BigEndian.Uint16(data[4:])
+	p.ArtworkIndex = binary.BigEndian.Uint16(data[6:])
+	p.ArtworkCount = int16(binary.BigEndian.Uint16(data[8:]))
+	return nil
+}
+
+func (*RetTrackArtworkTimes) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*RetTrackArtworkTimes) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*RetTrackArtworkTimes) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*GetShuffle) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetShuffle) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetShuffle) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *ReturnShuffle) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *ReturnShuffle) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = byte(p.Mode)
+	return dst, nil
+}
+
+func (p *ReturnShuffle) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Mode = ShuffleMode(data[0])
+	return nil
+}
+
+func (p *SetShuffle) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *SetShuffle) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = byte(p.Mode)
+	return dst, nil
+}
+
+func (p *SetShuffle) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Mode = ShuffleMode(data[0])
+	return nil
+}
+
+func (*GetRepeat) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetRepeat) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetRepeat) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *ReturnRepeat) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *ReturnRepeat) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = byte(p.Mode)
+	return dst, nil
+}
+
+func (p *ReturnRepeat) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Mode = RepeatMode(data[0])
+	return nil
+}
+
+func (p *SetRepeat) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *SetRepeat) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = byte(p.Mode)
+	return dst, nil
+}
+
+func (p *SetRepeat) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Mode = RepeatMode(data[0])
+	return nil
+}
+
+func (*SetDisplayImage) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*SetDisplayImage) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*SetDisplayImage) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*GetMonoDisplayImageLimits) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetMonoDisplayImageLimits) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetMonoDisplayImageLimits) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *ReturnMonoDisplayImageLimits) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 5))
+}
+
+func (p *ReturnMonoDisplayImageLimits) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 5)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint16(b[0:], p.MaxWidth)
+	binary.BigEndian.PutUint16(b[2:], p.MaxHeight)
+	b[4] = p.PixelFormat
+	return dst, nil
+}
+
+func (p *ReturnMonoDisplayImageLimits) UnmarshalBinary(data []byte) error {
+	if len(data) < 5 {
+		return io.ErrUnexpectedEOF
+	}
+	p.MaxWidth = binary.BigEndian.Uint16(data[0:])
+	p.MaxHeight = binary.BigEndian.Uint16(data[2:])
+	p.PixelFormat = data[4]
+	return nil
+}
+
+func (*GetNumPlayingTracks) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetNumPlayingTracks) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetNumPlayingTracks) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *ReturnNumPlayingTracks) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 4))
+}
+
+func (p *ReturnNumPlayingTracks) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 4)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], p.NumTracks)
+	return dst, nil
+}
+
+func (p *ReturnNumPlayingTracks) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	p.NumTracks = binary.BigEndian.Uint32(data[0:])
+	return nil
+}
+
+func (p *SetCurrentPlayingTrack) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 4))
+}
+
+func (p *SetCurrentPlayingTrack) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 4)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], uint32(p.TrackIndex))
+	return dst, nil
+}
+
+func (p *SetCurrentPlayingTrack) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	p.TrackIndex = int32(binary.BigEndian.Uint32(data[0:]))
+	return nil
+}
+
+func (p *SelectSortDBRecord) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 6))
+}
+
+func (p *SelectSortDBRecord) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 6)...)
+	b := dst[n:]
+	b[0] = byte(p.CategoryType)
+	binary.BigEndian.PutUint32(b[1:], uint32(p.RecordIndex))
+	b[5] = p.SortType
+	return dst, nil
+}
+
+func (p *SelectSortDBRecord) UnmarshalBinary(data []byte) error {
+	if len(data) < 6 {
+		return io.ErrUnexpectedEOF
+	}
+	p.CategoryType = DBCategoryType(data[0])
+	p.RecordIndex = int32(binary.BigEndian.Uint32(data[1:]))
+	p.SortType = data[5]
+	return nil
+}
+
+func (*GetColorDisplayImageLimits) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetColorDisplayImageLimits) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetColorDisplayImageLimits) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *ReturnColorDisplayImageLimits) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 5))
+}
+
+func (p *ReturnColorDisplayImageLimits) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 5)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint16(b[0:], p.MaxWidth)
+	binary.BigEndian.PutUint16(b[2:], p.MaxHeight)
+	b[4] = p.PixelFormat
+	return dst, nil
+}
+
+func (p *ReturnColorDisplayImageLimits) UnmarshalBinary(data []byte) error {
+	if len(data) < 5 {
+		return io.ErrUnexpectedEOF
+	}
+	p.MaxWidth = binary.BigEndian.Uint16(data[0:])
+	p.MaxHeight = binary.BigEndian.Uint16(data[2:])
+	p.PixelFormat = data[4]
+	return nil
+}
+
+func (p *ResetDBSelectionHierarchy) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *ResetDBSelectionHierarchy) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = p.Selection
+	return dst, nil
+}
+
+func (p *ResetDBSelectionHierarchy) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Selection = data[0]
+	return nil
+}
+
+func (*GetDBiTunesInfo) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetDBiTunesInfo) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetDBiTunesInfo) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*RetDBiTunesInfo) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*RetDBiTunesInfo) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*RetDBiTunesInfo) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*GetUIDTrackInfo) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetUIDTrackInfo) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetUIDTrackInfo) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*RetUIDTrackInfo) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*RetUIDTrackInfo) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*RetUIDTrackInfo) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*GetDBTrackInfo) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetDBTrackInfo) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetDBTrackInfo) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*RetDBTrackInfo) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*RetDBTrackInfo) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*RetDBTrackInfo) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*GetPBTrackInfo) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetPBTrackInfo) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetPBTrackInfo) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*RetPBTrackInfo) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*RetPBTrackInfo) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*RetPBTrackInfo) UnmarshalBinary(data []byte) error {
+	return nil
+}
@@ -5,22 +5,30 @@ import (
 	"bytes"
 	"encoding"
 	"encoding/binary"
+	"encoding/json"
 	"errors"
+	"io"
+	"time"
 
 	"github.com/oandrew/ipod"
 )
 
 func init() {
-	ipod.RegisterLingos(LingoGeneralID, Lingos)
+	ipod.RegisterPayloads(LingoGeneralID, lingosPayloads)
+	ipod.DefaultRegistry.RegisterPayloadsVersion(LingoGeneralID, ipod.Version{Major: 1, Minor: 4}, lingosV1_04Payloads)
+	ipod.RegisterACK(LingoGeneralID, func(req *ipod.Command, status ipod.ACKStatus) interface{} {
+		return ack(req, ACKStatus(status))
+	})
 }
 
 const LingoGeneralID = 0x00
 
+//go:generate go run ../cmd/ipodgen -lingos=Lingos,LingosV1_04
 var Lingos struct {
 	RequestIdentify                `id:"0x00"`
-	ACK                            `id:"0x02"`
-	ACKPending                     `id:"0x02"`
-	ACKDataDropped                 `id:"0x02"`
+	ACK                            `id:"0x02" when:"byte0!=0x06"`
+	ACKPending                     `id:"0x02" when:"byte0=0x06"`
+	ACKDataDropped                 `id:"0x02" when:"byte0!=0x06,byte1=0x43"`
 	RequestRemoteUIMode            `id:"0x03"`
 	ReturnRemoteUIMode             `id:"0x04"`
 	EnterRemoteUIMode              `id:"0x05"`
@@ -41,7 +49,7 @@ var Lingos struct {
 	//RetDevAuthenticationInfoV2      `id:"0x15"`
 	RetDevAuthenticationInfo        `id:"0x15"`
 	AckDevAuthenticationInfo        `id:"0x16"`
-	GetDevAuthenticationSignatureV1 `id:"0x17"`
+	GetDevAuthenticationSignatureV1 `id:"0x17" when:"notrx"`
 	GetDevAuthenticationSignatureV2 `id:"0x17"`
 	//RetDevAuthenticationSignatureV1 `id:"0x18"`
 	//RetDevAuthenticationSignatureV2 `id:"0x18"`
@@ -92,14 +100,27 @@ var Lingos struct {
 	RetNowPlayingFocusApp          `id:"0x66"`
 }
 
+// LingosV1_04 are the layouts of the accessory info commands
+// that depend on the info type, added in version 1.04
+var LingosV1_04 struct {
+	GetAccessoryInfo2            `id:"0x27" when:"byte0=0x02"`
+	GetAccessoryInfo3            `id:"0x27" when:"byte0=0x03"`
+	RetAccessoryInfoCaps         `id:"0x28" when:"byte0=0x00"`
+	RetAccessoryInfoMinFirmware  `id:"0x28" when:"byte0=0x02"`
+	RetAccessoryInfoLingoVersion `id:"0x28" when:"byte0=0x03"`
+}
+
 type RequestIdentify struct{}
 
+//go:generate stringer -type=ACKStatus
 type ACKStatus uint8
 
 const (
-	ACKStatusSuccess  ACKStatus = 0x00
-	ACKStatusUnkownID ACKStatus = 0x05
-	ACKStatusPending  ACKStatus = 0x06
+	ACKStatusSuccess      ACKStatus = 0x00
+	ACKStatusFailed       ACKStatus = 0x02
+	ACKStatusBadParameter ACKStatus = 0x04
+	ACKStatusUnkownID     ACKStatus = 0x05
+	ACKStatusPending      ACKStatus = 0x06
 )
 
 type ACK struct {
@@ -107,10 +128,30 @@ type ACK struct {
 	CmdID  uint8
 }
 
+func (s *ACK) ACKStatus() ipod.ACKStatus {
+	return ipod.ACKStatus(s.Status)
+}
+
+func (s *ACK) ACKCmdID() uint16 {
+	return uint16(s.CmdID)
+}
+
 type ACKPending struct {
 	Status  ACKStatus
 	CmdID   uint8
-	MaxWait uint32
+	MaxWait uint32 // ms
+}
+
+func (s *ACKPending) ACKStatus() ipod.ACKStatus {
+	return ipod.ACKStatus(s.Status)
+}
+
+func (s *ACKPending) ACKCmdID() uint16 {
+	return uint16(s.CmdID)
+}
+
+func (s *ACKPending) ACKMaxWait() time.Duration {
+	return time.Duration(s.MaxWait) * time.Millisecond
 }
 
 type ACKDataDropped struct {
@@ -120,6 +161,14 @@ type ACKDataDropped struct {
 	NumBytesDropped uint32
 }
 
+func (s *ACKDataDropped) ACKStatus() ipod.ACKStatus {
+	return ipod.ACKStatus(s.Status)
+}
+
+func (s *ACKDataDropped) ACKCmdID() uint16 {
+	return uint16(s.CmdID)
+}
+
 type RequestRemoteUIMode struct{}
 
 type ReturnRemoteUIMode struct {
@@ -226,6 +275,7 @@ func (s *RetDevAuthenticationInfo) UnmarshalBinary(r []byte) error {
 
 }
 
+//go:generate stringer -type=DevAuthInfoStatus
 type DevAuthInfoStatus uint8
 
 const (
@@ -235,6 +285,9 @@ const (
 type AckDevAuthenticationInfo struct {
 	Status DevAuthInfoStatus
 }
+
+// GetDevAuthenticationSignatureV1 is the authentication 1.0 form,
+// which predates transaction IDs
 type GetDevAuthenticationSignatureV1 struct {
 	Challenge [16]byte
 	Counter   byte
@@ -255,6 +308,7 @@ func (s *RetDevAuthenticationSignature) UnmarshalBinary(r []byte) error {
 	return nil
 }
 
+//go:generate stringer -type=DevAuthStatus
 type DevAuthStatus uint8
 
 const (
@@ -272,8 +326,36 @@ type RetiPodAuthenticationInfo struct {
 	Minor              byte
 	CertCurrentSection byte
 	CertMaxSection     byte
-	CertData           []byte
+	CertData           []byte `ipod:"rest"`
 }
+
+// Split splits the certificate into sections of up to max bytes
+func (s *RetiPodAuthenticationInfo) Split(max int) ([]interface{}, error) {
+	n := max - 4
+	if n <= 0 {
+		return nil, errors.New("no room for the certificate data")
+	}
+	sections := (len(s.CertData) + n - 1) / n
+	if sections > 0x100 {
+		return nil, errors.New("too many certificate sections")
+	}
+	var parts []interface{}
+	for i := 0; i < len(s.CertData); i += n {
+		end := i + n
+		if end > len(s.CertData) {
+			end = len(s.CertData)
+		}
+		parts = append(parts, &RetiPodAuthenticationInfo{
+			Major:              s.Major,
+			Minor:              s.Minor,
+			CertCurrentSection: byte(len(parts)),
+			CertMaxSection:     byte(sections - 1),
+			CertData:           s.CertData[i:end],
+		})
+	}
+	return parts, nil
+}
+
 type AckiPodAuthenticationInfo struct {
 	Status byte
 }
@@ -317,34 +399,33 @@ type GetAccessoryInfo3 struct {
 
 type RetAccessoryInfo struct {
 	InfoType byte
-	Data     []byte
+	Data     []byte `ipod:"rest"`
 }
 
-// type RetAccessoryInfo0 struct {
-// 	InfoType byte
-// 	Caps uint32
-// }
-
-// type RetAccessoryInfo1678 struct {
-// 	InfoType byte
-// 	Data []byte
-// }
+// RetAccessoryInfoCaps answers the info type 0x00
+type RetAccessoryInfoCaps struct {
+	InfoType byte
+	Caps     uint32
+}
 
-// type RetAccessoryInfo2 struct {
-// 	InfoType byte
-// 	ModelID uint32
-// 	MinMajor byte
-// 	MinMinor byte
-// 	MinRev   byte
-// }
+// RetAccessoryInfoMinFirmware answers the info type 0x02,
+// the minimum firmware version of an iPod model
+type RetAccessoryInfoMinFirmware struct {
+	InfoType byte
+	ModelID  uint32
+	Major    byte
+	Minor    byte
+	Rev      byte
+}
 
-// type RetAccessoryInfo3 struct {
-// 	InfoType byte
-// 	ModelID uint32
-// 	MinMajor byte
-// 	MinMinor byte
-// 	MinRev   byte
-// }
+// RetAccessoryInfoLingoVersion answers the info type 0x03,
+// the minimum protocol version of a lingo
+type RetAccessoryInfoLingoVersion struct {
+	InfoType byte
+	LingoID  byte
+	Major    byte
+	Minor    byte
+}
 
 type GetiPodPreferences struct {
 	PrefClassID byte
@@ -360,6 +441,7 @@ type SetiPodPreferences struct {
 	RestoreOnExit      byte
 }
 
+//go:generate stringer -type=UIMode
 type UIMode uint8
 
 const (
@@ -388,12 +470,20 @@ type FIDIdentifyToken struct {
 
 func (t *FIDIdentifyToken) UnmarshalBinary(data []byte) error {
 	r := bytes.NewReader(data)
-	binary.Read(r, binary.BigEndian, &t.NumLingoes)
+	if err := binary.Read(r, binary.BigEndian, &t.NumLingoes); err != nil {
+		return err
+	}
+	if int(t.NumLingoes) > r.Len() {
+		return io.ErrUnexpectedEOF
+	}
 	t.AccLingoes = make([]byte, t.NumLingoes)
-	binary.Read(r, binary.BigEndian, &t.AccLingoes)
-	binary.Read(r, binary.BigEndian, &t.DeviceOptions)
-	binary.Read(r, binary.BigEndian, &t.DeviceID)
-	return nil
+	if err := binary.Read(r, binary.BigEndian, &t.AccLingoes); err != nil {
+		return err
+	}
+	if err := binary.Read(r, binary.BigEndian, &t.DeviceOptions); err != nil {
+		return err
+	}
+	return binary.Read(r, binary.BigEndian, &t.DeviceID)
 }
 
 //go:generate stringer -type=AccCapBit
@@ -420,14 +510,22 @@ var AccCaps = []AccCapBit{
 	AccCapAppComm, AccCapCheckVolume,
 }
 
+// AccCapMask is a bitmask of AccCapBit
+type AccCapMask uint64
+
+func (m AccCapMask) String() string {
+	return ipod.FormatMask(uint64(m), func(bit uint64) string {
+		return AccCapBit(bit).String()
+	})
+}
+
 type FIDAccCapsToken struct {
-	AccCapsBitmask uint64
+	AccCapsBitmask AccCapMask
 }
 
 func (t *FIDAccCapsToken) UnmarshalBinary(data []byte) error {
 	r := bytes.NewReader(data)
-	binary.Read(r, binary.BigEndian, &t.AccCapsBitmask)
-	return nil
+	return binary.Read(r, binary.BigEndian, &t.AccCapsBitmask)
 }
 
 //go:generate stringer -type=AccInfoType
@@ -444,32 +542,52 @@ const (
 )
 
 type FIDAccInfoToken struct {
-	AccInfoType byte
+	AccInfoType AccInfoType
 	Value       interface{}
 }
 
+// UnmarshalJSON decodes the value as bytes
+func (t *FIDAccInfoToken) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		AccInfoType AccInfoType
+		Value       []byte
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	t.AccInfoType = raw.AccInfoType
+	t.Value = nil
+	if raw.Value != nil {
+		t.Value = raw.Value
+	}
+	return nil
+}
+
 func (t *FIDAccInfoToken) UnmarshalBinary(data []byte) error {
 	r := bytes.NewReader(data)
-	binary.Read(r, binary.BigEndian, &t.AccInfoType)
+	if err := binary.Read(r, binary.BigEndian, &t.AccInfoType); err != nil {
+		return err
+	}
+	var n int
 	switch t.AccInfoType {
 	//name
 	case 0x01, 0x06, 0x07, 0x08:
 		t.Value, _ = bufio.NewReader(r).ReadBytes(0x00)
+		return nil
 	case 0x04, 0x05:
-		v := make([]byte, 3)
-		r.Read(v)
-		t.Value = v
+		n = 3
 	case 0x09:
-		v := make([]byte, 2)
-		r.Read(v)
-		t.Value = v
+		n = 2
 	case 0x0b, 0x0c:
-		v := make([]byte, 4)
-		r.Read(v)
-		t.Value = v
+		n = 4
 	default:
 		return errors.New("unknown AccInfoToken type")
 	}
+	v := make([]byte, n)
+	if _, err := io.ReadFull(r, v); err != nil {
+		return err
+	}
+	t.Value = v
 	return nil
 }
 
@@ -490,8 +608,11 @@ type FIDEAProtocolToken struct {
 }
 
 func (t *FIDEAProtocolToken) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return errors.New("short packet")
+	}
 	t.ProtocolIndex = data[0]
-	t.ProtocolString = data[1:]
+	t.ProtocolString = append([]byte(nil), data[1:]...)
 	return nil
 }
 
@@ -528,6 +649,9 @@ type FIDEAProtocolMetadataToken struct {
 }
 
 func (t *FIDEAProtocolMetadataToken) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return errors.New("short packet")
+	}
 	t.ProtocolIndex = data[0]
 	t.MetadataType = data[1]
 	return nil
@@ -549,6 +673,77 @@ type FIDTokenValue struct {
 	Token      interface{}
 }
 
+// newFIDToken returns the token of the type and subtype,
+// nil if it is unknown
+func newFIDToken(fidType, fidSubtype byte) interface{} {
+	switch fidType {
+	case 0x00:
+		switch fidSubtype {
+		case 0x00:
+			//identify
+			return &FIDIdentifyToken{}
+		case 0x01:
+			//acc caps
+			return &FIDAccCapsToken{}
+		case 0x02:
+			//accinfo
+			return &FIDAccInfoToken{}
+		case 0x03:
+			//ipod pref
+			return &FIDiPodPreferenceToken{}
+		case 0x04:
+			//sdk proto
+			return &FIDEAProtocolToken{}
+		case 0x05:
+			// bundleseed
+			return &FIDBundleSeedIDPrefToken{}
+		case 0x07:
+			// screen info
+			return &FIDScreenInfoToken{}
+		case 0x08:
+			// eaprotometadata
+			return &FIDEAProtocolMetadataToken{}
+		}
+	case 0x01:
+		//mic
+		return &FIDMicrophoneCapsToken{}
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes the token into the type
+// told by FIDType and FIDSubtype, the unknown tokens are raw bytes
+func (v *FIDTokenValue) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Len        byte
+		FIDType    byte
+		FIDSubtype byte
+		Token      json.RawMessage
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	v.Len, v.FIDType, v.FIDSubtype = raw.Len, raw.FIDType, raw.FIDSubtype
+	v.Token = nil
+	if len(raw.Token) == 0 || string(raw.Token) == "null" {
+		return nil
+	}
+	token := newFIDToken(v.FIDType, v.FIDSubtype)
+	if token == nil {
+		var b []byte
+		if err := json.Unmarshal(raw.Token, &b); err != nil {
+			return err
+		}
+		v.Token = b
+		return nil
+	}
+	if err := json.Unmarshal(raw.Token, token); err != nil {
+		return err
+	}
+	v.Token = token
+	return nil
+}
+
 type SetFIDTokenValues struct {
 	NumFIDTokenValues byte
 	FIDTokenValues    []FIDTokenValue
@@ -578,46 +773,16 @@ func (s *SetFIDTokenValues) UnmarshalBinary(data []byte) error {
 		if err != nil {
 			return err
 		}
+		// the length covers the type and subtype
+		if v.Len < 2 {
+			return errors.New("bad FID token length")
+		}
 		data := make([]byte, v.Len-2)
-		_, err = br.Read(data)
-		if err != nil {
+		if _, err := io.ReadFull(br, data); err != nil {
 			return err
 		}
 
-		switch v.FIDType {
-		case 0x00:
-			switch v.FIDSubtype {
-			case 0x00:
-				//identify
-				v.Token = &FIDIdentifyToken{}
-			case 0x01:
-				//acc caps
-				v.Token = &FIDAccCapsToken{}
-			case 0x02:
-				//accinfo
-				v.Token = &FIDAccInfoToken{}
-			case 0x03:
-				//ipod pref
-				v.Token = &FIDiPodPreferenceToken{}
-			case 0x04:
-				//sdk proto
-				v.Token = &FIDEAProtocolToken{}
-			case 0x05:
-				// bundleseed
-				v.Token = &FIDBundleSeedIDPrefToken{}
-			case 0x07:
-				// screen info
-				v.Token = &FIDScreenInfoToken{}
-			case 0x08:
-				// eaprotometadata
-				v.Token = &FIDEAProtocolMetadataToken{}
-
-			}
-		case 0x01:
-			//mic
-			v.Token = &FIDMicrophoneCapsToken{}
-		}
-
+		v.Token = newFIDToken(v.FIDType, v.FIDSubtype)
 		if bu, ok := v.Token.(encoding.BinaryUnmarshaler); ok {
 			if err := bu.UnmarshalBinary(data); err != nil {
 				return err
@@ -653,6 +818,7 @@ func (s *RetFIDTokenValueACKs) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
+//go:generate stringer -type=AccEndIDPSStatus
 type AccEndIDPSStatus uint8
 
 const (
@@ -666,6 +832,7 @@ type EndIDPS struct {
 	AccEndIDPSStatus AccEndIDPSStatus
 }
 
+//go:generate stringer -type=IDPSStatusEnum
 type IDPSStatusEnum uint8
 
 const (
@@ -692,11 +859,11 @@ type DevACK struct {
 
 type DevDataTransfer struct {
 	SessionID uint16
-	Data      []byte
+	Data      []byte `ipod:"rest"`
 }
 type IPodDataTransfer struct {
 	SessionID uint16
-	Data      []byte
+	Data      []byte `ipod:"rest"`
 }
 type SetAccStatusNotification struct {
 	StatusMask uint32
@@ -706,15 +873,42 @@ type RetAccStatusNotification struct {
 }
 type AccessoryStatusNotification struct {
 	StatusType   byte
-	StatusParams []byte
+	StatusParams []byte `ipod:"rest"`
+}
+
+//go:generate stringer -type=EventBit
+type EventBit uint64
+
+const (
+	EventFlowControl              EventBit = 1 << 2
+	EventRadioTagging             EventBit = 1 << 3
+	EventCamera                   EventBit = 1 << 4
+	EventChargingInfo             EventBit = 1 << 5
+	EventDatabaseChanged          EventBit = 1 << 9
+	EventNowPlayingAppBundleName  EventBit = 1 << 10
+	EventSessionSpaceAvailable    EventBit = 1 << 11
+	EventCommandCompleted         EventBit = 1 << 13
+	EventiPodOutMode              EventBit = 1 << 15
+	EventBluetoothConnection      EventBit = 1 << 17
+	EventNowPlayingAppDisplayName EventBit = 1 << 19
+	EventAssistiveTouch           EventBit = 1 << 20
+)
+
+// EventMask is a bitmask of EventBit
+type EventMask uint64
+
+func (m EventMask) String() string {
+	return ipod.FormatMask(uint64(m), func(bit uint64) string {
+		return EventBit(bit).String()
+	})
 }
 
 type SetEventNotification struct {
-	EventMask uint64
+	EventMask EventMask
 }
 type IPodNotification struct {
 	NotificationType byte
-	Data             []byte
+	Data             []byte `ipod:"rest"`
 }
 
 type GetiPodOptionsForLingo struct {
@@ -727,7 +921,7 @@ type RetiPodOptionsForLingo struct {
 type GetEventNotification struct{}
 
 type RetEventNotification struct {
-	EventMask uint64
+	EventMask EventMask
 }
 type GetSupportedEventNotification struct{}
 
@@ -737,17 +931,17 @@ type CancelCommand struct {
 	TransactionID uint16
 }
 type RetSupportedEventNotification struct {
-	EventMask uint64
+	EventMask EventMask
 }
 type SetAvailableCurrent struct {
 	CurrentLimit uint16
 }
 type RequestApplicationLaunch struct {
 	_     [3]byte
-	AppID []byte
+	AppID []byte `ipod:"cstring"`
 }
 type GetNowPlayingFocusApp struct{}
 
 type RetNowPlayingFocusApp struct {
-	AppID []byte
+	AppID []byte `ipod:"cstring"`
 }
@@ -2,12 +2,17 @@ package general
 
 import (
 	"bytes"
+	"context"
+	"encoding/binary"
+	"time"
 
 	"github.com/oandrew/ipod"
 )
 
 type DeviceGeneral interface {
 	UIMode() UIMode
+	// SetUIMode is called asynchronously by EnterRemoteUIMode
+	// and ExitRemoteUIMode while a pending ACK is outstanding
 	SetUIMode(UIMode)
 	Name() string
 	SoftwareVersion() (major, minor, rev uint8)
@@ -28,17 +33,54 @@ type DeviceGeneral interface {
 	EventNotificationMask() uint64
 	SupportedEventNotificationMask() uint64
 
+	// CancelCommand is called after the session cancelled
+	// the matching request in flight, if any
 	CancelCommand(lingo uint8, cmd uint16, transaction uint16)
 
 	MaxPayload() uint16
 }
 
+// PrefRestorer is implemented by devices that keep the preferences
+// set with RestoreOnExit and can restore them
+type PrefRestorer interface {
+	RestorePrefSettings()
+}
+
+// Teardown resets the state of dev the accessory changed for the session:
+// it leaves the extended UI mode and restores the preferences
+// set with RestoreOnExit if dev is a PrefRestorer.
+// Nothing is sent to the accessory.
+// It is meant to be registered with Session.HandleTeardown.
+func Teardown(tr ipod.CommandWriter, dev DeviceGeneral) error {
+	if r, ok := dev.(PrefRestorer); ok {
+		r.RestorePrefSettings()
+	}
+	if dev.UIMode() == UIModeExtended {
+		dev.SetUIMode(UIModeStandart)
+	}
+	return nil
+}
+
 func ackSuccess(req *ipod.Command) *ACK {
 	return &ACK{Status: ACKStatusSuccess, CmdID: uint8(req.ID.CmdID())}
 }
 
-func ackPending(req *ipod.Command, maxWait uint32) *ACKPending {
-	return &ACKPending{Status: ACKStatusPending, CmdID: uint8(req.ID.CmdID()), MaxWait: maxWait}
+func ackPending(req *ipod.Command, maxWait time.Duration) *ACKPending {
+	return &ACKPending{Status: ACKStatusPending, CmdID: uint8(req.ID.CmdID()), MaxWait: uint32(maxWait / time.Millisecond)}
+}
+
+// remoteUIModeWait is the max wait announced while the UI mode changes
+const remoteUIModeWait = 300 * time.Millisecond
+
+// setUIModePending answers req with a pending ACK and changes the UI mode
+func setUIModePending(req *ipod.Command, tr ipod.CommandWriter, dev DeviceGeneral, mode UIMode) {
+	pendingACK := func(maxWait time.Duration) interface{} {
+		return ackPending(req, maxWait)
+	}
+	ipod.Pending(req, tr, remoteUIModeWait, pendingACK, func(ctx context.Context) (interface{}, error) {
+		dev.SetUIMode(mode)
+		return ackSuccess(req), nil
+	})
 }
 
 func ack(req *ipod.Command, status ACKStatus) *ACK {
@@ -60,7 +102,7 @@ func ackFIDTokens(tokens *SetFIDTokenValues) *RetFIDTokenValueACKs {
 		case *FIDAccCapsToken:
 			ackBuf.Write([]byte{0x00})
 		case *FIDAccInfoToken:
-			ackBuf.Write([]byte{0x00, t.AccInfoType})
+			ackBuf.Write([]byte{0x00, byte(t.AccInfoType)})
 		case *FIDiPodPreferenceToken:
 			ackBuf.Write([]byte{0x00, t.PrefClass})
 		case *FIDEAProtocolToken:
@@ -95,17 +137,13 @@ func HandleGeneral(req *ipod.Command, tr ipod.CommandWriter, dev DeviceGeneral)
 		if dev.UIMode() == UIModeExtended {
 			ipod.Respond(req, tr, ackSuccess(req))
 		} else {
-			ipod.Respond(req, tr, ackPending(req, 300))
-			dev.SetUIMode(UIModeExtended)
-			ipod.Respond(req, tr, ackSuccess(req))
+			setUIModePending(req, tr, dev, UIModeExtended)
 		}
 	case *ExitRemoteUIMode:
 		if dev.UIMode() != UIModeExtended {
 			ipod.Respond(req, tr, ackSuccess(req))
 		} else {
-			ipod.Respond(req, tr, ackPending(req, 300))
-			dev.SetUIMode(UIModeStandart)
-			ipod.Respond(req, tr, ackSuccess(req))
+			setUIModePending(req, tr, dev, UIModeStandart)
 		}
 	case *RequestiPodName:
 		ipod.Respond(req, tr, &ReturniPodName{Name: ipod.StringToBytes(dev.Name())})
@@ -121,6 +159,7 @@ func HandleGeneral(req *ipod.Command, tr ipod.CommandWriter, dev DeviceGeneral)
 		resp.Major, resp.Minor = dev.LingoProtocolVersion(msg.Lingo)
 		ipod.Respond(req, tr, &resp)
 	case *RequestTransportMaxPayloadSize:
+		ipod.SetMaxPayload(tr, ipod.DirIn, int(dev.MaxPayload()))
 		ipod.Respond(req, tr, &ReturnTransportMaxPayloadSize{MaxPayload: dev.MaxPayload()})
 	case *IdentifyDeviceLingoes:
 		ipod.Respond(req, tr, ackSuccess(req))
@@ -174,7 +213,7 @@ func HandleGeneral(req *ipod.Command, tr ipod.CommandWriter, dev DeviceGeneral)
 
 	// GetAccessoryInfo
 	// check back might be useful
-	case *RetAccessoryInfo:
+	case *RetAccessoryInfo, *RetAccessoryInfoCaps, *RetAccessoryInfoMinFirmware, *RetAccessoryInfoLingoVersion:
 		// pass
 
 	case *GetiPodPreferences:
@@ -193,12 +232,17 @@ func HandleGeneral(req *ipod.Command, tr ipod.CommandWriter, dev DeviceGeneral)
 		ipod.Respond(req, tr, ackSuccess(req))
 
 	case *StartIDPS:
-		ipod.TrxReset()
+		ipod.ResetTransactions(tr)
 		dev.StartIDPS()
 		ipod.Respond(req, tr, ackSuccess(req))
 	case *SetFIDTokenValues:
 		for _, token := range msg.FIDTokenValues {
 			dev.SetToken(token)
+			if t, ok := token.Token.(*FIDAccInfoToken); ok && t.AccInfoType == AccInfoMaxPayload {
+				if v, ok := t.Value.([]byte); ok && len(v) == 2 {
+					ipod.SetMaxPayload(tr, ipod.DirOut, int(binary.BigEndian.Uint16(v)))
+				}
+			}
 		}
 		ipod.Respond(req, tr, ackFIDTokens(msg))
 	case *EndIDPS:
@@ -222,7 +266,7 @@ func HandleGeneral(req *ipod.Command, tr ipod.CommandWriter, dev DeviceGeneral)
 
 	// iPodNotification later
 	case *SetEventNotification:
-		dev.SetEventNotificationMask(msg.EventMask)
+		dev.SetEventNotificationMask(uint64(msg.EventMask))
 		ipod.Respond(req, tr, ackSuccess(req))
 
 	case *GetiPodOptionsForLingo:
@@ -233,15 +277,18 @@ func HandleGeneral(req *ipod.Command, tr ipod.CommandWriter, dev DeviceGeneral)
 
 	case *GetEventNotification:
 		ipod.Respond(req, tr, &RetEventNotification{
-			EventMask: dev.EventNotificationMask(),
+			EventMask: EventMask(dev.EventNotificationMask()),
 		})
 
 	case *GetSupportedEventNotification:
 		ipod.Respond(req, tr, &RetSupportedEventNotification{
-			EventMask: dev.SupportedEventNotificationMask(),
+			EventMask: EventMask(dev.SupportedEventNotificationMask()),
 		})
 
 	case *CancelCommand:
+		if c, ok := tr.(ipod.CommandCanceler); ok {
+			c.CancelCommand(ipod.NewLingoCmdID(uint16(msg.LingoID), msg.CmdID), ipod.Transaction(msg.TransactionID))
+		}
 		dev.CancelCommand(msg.LingoID, msg.CmdID, msg.TransactionID)
 		ipod.Respond(req, tr, ackSuccess(req))
 
@@ -252,7 +299,7 @@ func HandleGeneral(req *ipod.Command, tr ipod.CommandWriter, dev DeviceGeneral)
 		ipod.Respond(req, tr, ackSuccess(req))
 
 	case *GetNowPlayingFocusApp:
-		ipod.Respond(req, tr, &RetNowPlayingFocusApp{AppID: ipod.StringToBytes("")})
+		ipod.Respond(req, tr, &RetNowPlayingFocusApp{})
 
 	case ipod.UnknownPayload:
 		ipod.Respond(req, tr, ack(req, ACKStatusUnkownID))
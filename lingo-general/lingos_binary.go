@@ -0,0 +1,1236 @@
+// Code generated by "ipodgen"; DO NOT EDIT.
+
+package general
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/oandrew/ipod"
+)
+
+// lingosPayloads is the static payload table of Lingos
+var lingosPayloads = []ipod.PayloadDef{
+	{ID: 0x00, Size: 0, New: func() interface{} { return &RequestIdentify{} }},
+	{ID: 0x02, Size: 2, New: func() interface{} { return &ACK{} }, When: "byte0!=0x06"},
+	{ID: 0x02, Size: 6, New: func() interface{} { return &ACKPending{} }, When: "byte0=0x06"},
+	{ID: 0x02, Size: 8, New: func() interface{} { return &ACKDataDropped{} }, When: "byte0!=0x06,byte1=0x43"},
+	{ID: 0x03, Size: 0, New: func() interface{} { return &RequestRemoteUIMode{} }},
+	{ID: 0x04, Size: 1, New: func() interface{} { return &ReturnRemoteUIMode{} }},
+	{ID: 0x05, Size: 0, New: func() interface{} { return &EnterRemoteUIMode{} }},
+	{ID: 0x06, Size: 0, New: func() interface{} { return &ExitRemoteUIMode{} }},
+	{ID: 0x07, Size: 0, New: func() interface{} { return &RequestiPodName{} }},
+	{ID: 0x08, Size: -1, New: func() interface{} { return &ReturniPodName{} }},
+	{ID: 0x09, Size: 0, New: func() interface{} { return &RequestiPodSoftwareVersion{} }},
+	{ID: 0x0A, Size: 3, New: func() interface{} { return &ReturniPodSoftwareVersion{} }},
+	{ID: 0x0B, Size: 0, New: func() interface{} { return &RequestiPodSerialNum{} }},
+	{ID: 0x0C, Size: -1, New: func() interface{} { return &ReturniPodSerialNum{} }},
+	{ID: 0x0F, Size: 1, New: func() interface{} { return &RequestLingoProtocolVersion{} }},
+	{ID: 0x10, Size: 3, New: func() interface{} { return &ReturnLingoProtocolVersion{} }},
+	{ID: 0x11, Size: 0, New: func() interface{} { return &RequestTransportMaxPayloadSize{} }},
+	{ID: 0x12, Size: 2, New: func() interface{} { return &ReturnTransportMaxPayloadSize{} }},
+	{ID: 0x13, Size: 12, New: func() interface{} { return &IdentifyDeviceLingoes{} }},
+	{ID: 0x14, Size: 0, New: func() interface{} { return &GetDevAuthenticationInfo{} }},
+	{ID: 0x15, Size: -1, New: func() interface{} { return &RetDevAuthenticationInfo{} }},
+	{ID: 0x16, Size: 1, New: func() interface{} { return &AckDevAuthenticationInfo{} }},
+	{ID: 0x17, Size: 17, New: func() interface{} { return &GetDevAuthenticationSignatureV1{} }, When: "notrx"},
+	{ID: 0x17, Size: 21, New: func() interface{} { return &GetDevAuthenticationSignatureV2{} }},
+	{ID: 0x18, Size: -1, New: func() interface{} { return &RetDevAuthenticationSignature{} }},
+	{ID: 0x19, Size: 1, New: func() interface{} { return &AckDevAuthenticationStatus{} }},
+	{ID: 0x1A, Size: 0, New: func() interface{} { return &GetiPodAuthenticationInfo{} }},
+	{ID: 0x1B, Size: -1, New: func() interface{} { return &RetiPodAuthenticationInfo{} }},
+	{ID: 0x1C, Size: 1, New: func() interface{} { return &AckiPodAuthenticationInfo{} }},
+	{ID: 0x1D, Size: 21, New: func() interface{} { return &GetiPodAuthenticationSignature{} }},
+	{ID: 0x1E, Size: 20, New: func() interface{} { return &RetiPodAuthenticationSignature{} }},
+	{ID: 0x1F, Size: 1, New: func() interface{} { return &AckiPodAuthenticationStatus{} }},
+	{ID: 0x23, Size: 1, New: func() interface{} { return &NotifyiPodStateChange{} }},
+	{ID: 0x24, Size: 0, New: func() interface{} { return &GetiPodOptions{} }},
+	{ID: 0x25, Size: 8, New: func() interface{} { return &RetiPodOptions{} }},
+	{ID: 0x27, Size: 1, New: func() interface{} { return &GetAccessoryInfo{} }},
+	{ID: 0x28, Size: -1, New: func() interface{} { return &RetAccessoryInfo{} }},
+	{ID: 0x29, Size: 1, New: func() interface{} { return &GetiPodPreferences{} }},
+	{ID: 0x2A, Size: 2, New: func() interface{} { return &RetiPodPreferences{} }},
+	{ID: 0x2B, Size: 3, New: func() interface{} { return &SetiPodPreferences{} }},
+	{ID: 0x35, Size: 0, New: func() interface{} { return &GetUIMode{} }},
+	{ID: 0x36, Size: 1, New: func() interface{} { return &RetUIMode{} }},
+	{ID: 0x37, Size: 1, New: func() interface{} { return &SetUIMode{} }},
+	{ID: 0x38, Size: 0, New: func() interface{} { return &StartIDPS{} }},
+	{ID: 0x39, Size: -1, New: func() interface{} { return &SetFIDTokenValues{} }},
+	{ID: 0x3A, Size: -1, New: func() interface{} { return &RetFIDTokenValueACKs{} }},
+	{ID: 0x3B, Size: 1, New: func() interface{} { return &EndIDPS{} }},
+	{ID: 0x3C, Size: 1, New: func() interface{} { return &IDPSStatus{} }},
+	{ID: 0x3F, Size: 3, New: func() interface{} { return &OpenDataSessionForProtocol{} }},
+	{ID: 0x40, Size: 2, New: func() interface{} { return &CloseDataSession{} }},
+	{ID: 0x41, Size: 2, New: func() interface{} { return &DevACK{} }},
+	{ID: 0x42, Size: -1, New: func() interface{} { return &DevDataTransfer{} }},
+	{ID: 0x43, Size: -1, New: func() interface{} { return &IPodDataTransfer{} }},
+	{ID: 0x46, Size: 4, New: func() interface{} { return &SetAccStatusNotification{} }},
+	{ID: 0x47, Size: 4, New: func() interface{} { return &RetAccStatusNotification{} }},
+	{ID: 0x48, Size: -1, New: func() interface{} { return &AccessoryStatusNotification{} }},
+	{ID: 0x49, Size: 8, New: func() interface{} { return &SetEventNotification{} }},
+	{ID: 0x4A, Size: -1, New: func() interface{} { return &IPodNotification{} }},
+	{ID: 0x4B, Size: 1, New: func() interface{} { return &GetiPodOptionsForLingo{} }},
+	{ID: 0x4C, Size: 9, New: func() interface{} { return &RetiPodOptionsForLingo{} }},
+	{ID: 0x4D, Size: 0, New: func() interface{} { return &GetEventNotification{} }},
+	{ID: 0x4E, Size: 8, New: func() interface{} { return &RetEventNotification{} }},
+	{ID: 0x4F, Size: 0, New: func() interface{} { return &GetSupportedEventNotification{} }},
+	{ID: 0x50, Size: 5, New: func() interface{} { return &CancelCommand{} }},
+	{ID: 0x51, Size: 8, New: func() interface{} { return &RetSupportedEventNotification{} }},
+	{ID: 0x54, Size: 2, New: func() interface{} { return &SetAvailableCurrent{} }},
+	{ID: 0x64, Size: -1, New: func() interface{} { return &RequestApplicationLaunch{} }},
+	{ID: 0x65, Size: 0, New: func() interface{} { return &GetNowPlayingFocusApp{} }},
+	{ID: 0x66, Size: -1, New: func() interface{} { return &RetNowPlayingFocusApp{} }},
+}
+
+// lingosV1_04Payloads is the static payload table of LingosV1_04
+var lingosV1_04Payloads = []ipod.PayloadDef{
+	{ID: 0x27, Size: 8, New: func() interface{} { return &GetAccessoryInfo2{} }, When: "byte0=0x02"},
+	{ID: 0x27, Size: 2, New: func() interface{} { return &GetAccessoryInfo3{} }, When: "byte0=0x03"},
+	{ID: 0x28, Size: 5, New: func() interface{} { return &RetAccessoryInfoCaps{} }, When: "byte0=0x00"},
+	{ID: 0x28, Size: 8, New: func() interface{} { return &RetAccessoryInfoMinFirmware{} }, When: "byte0=0x02"},
+	{ID: 0x28, Size: 4, New: func() interface{} { return &RetAccessoryInfoLingoVersion{} }, When: "byte0=0x03"},
+}
+
+func (*RequestIdentify) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*RequestIdentify) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*RequestIdentify) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *ACK) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 2))
+}
+
+func (p *ACK) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 2)...)
+	b := dst[n:]
+	b[0] = byte(p.Status)
+	b[1] = p.CmdID
+	return dst, nil
+}
+
+func (p *ACK) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Status = ACKStatus(data[0])
+	p.CmdID = data[1]
+	return nil
+}
+
+func (p *ACKPending) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 6))
+}
+
+func (p *ACKPending) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 6)...)
+	b := dst[n:]
+	b[0] = byte(p.Status)
+	b[1] = p.CmdID
+	binary.BigEndian.PutUint32(b[2:], p.MaxWait)
+	return dst, nil
+}
+
+func (p *ACKPending) UnmarshalBinary(data []byte) error {
+	if len(data) < 6 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Status = ACKStatus(data[0])
+	p.CmdID = data[1]
+	p.MaxWait = binary.BigEndian.Uint32(data[2:])
+	return nil
+}
+
+func (p *ACKDataDropped) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 8))
+}
+
+func (p *ACKDataDropped) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 8)...)
+	b := dst[n:]
+	b[0] = byte(p.Status)
+	b[1] = p.CmdID
+	binary.BigEndian.PutUint16(b[2:], p.SessionID)
+	binary.BigEndian.PutUint32(b[4:], p.NumBytesDropped)
+	return dst, nil
+}
+
+func (p *ACKDataDropped) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Status = ACKStatus(data[0])
+	p.CmdID = data[1]
+	p.SessionID = binary.BigEndian.Uint16(data[2:])
+	p.NumBytesDropped = binary.BigEndian.Uint32(data[4:])
+	return nil
+}
+
+func (*RequestRemoteUIMode) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*RequestRemoteUIMode) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*RequestRemoteUIMode) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *ReturnRemoteUIMode) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *ReturnRemoteUIMode) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = p.Mode
+	return dst, nil
+}
+
+func (p *ReturnRemoteUIMode) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Mode = data[0]
+	return nil
+}
+
+func (*EnterRemoteUIMode) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*EnterRemoteUIMode) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*EnterRemoteUIMode) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*ExitRemoteUIMode) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*ExitRemoteUIMode) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*ExitRemoteUIMode) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*RequestiPodName) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*RequestiPodName) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*RequestiPodName) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*RequestiPodSoftwareVersion) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*RequestiPodSoftwareVersion) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*RequestiPodSoftwareVersion) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *ReturniPodSoftwareVersion) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 3))
+}
+
+func (p *ReturniPodSoftwareVersion) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 3)...)
+	b := dst[n:]
+	b[0] = p.Major
+	b[1] = p.Minor
+	b[2] = p.Rev
+	return dst, nil
+}
+
+func (p *ReturniPodSoftwareVersion) UnmarshalBinary(data []byte) error {
+	if len(data) < 3 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Major = data[0]
+	p.Minor = data[1]
+	p.Rev = data[2]
+	return nil
+}
+
+func (*RequestiPodSerialNum) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*RequestiPodSerialNum) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*RequestiPodSerialNum) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *RequestLingoProtocolVersion) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *RequestLingoProtocolVersion) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = p.Lingo
+	return dst, nil
+}
+
+func (p *RequestLingoProtocolVersion) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Lingo = data[0]
+	return nil
+}
+
+func (p *ReturnLingoProtocolVersion) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 3))
+}
+
+func (p *ReturnLingoProtocolVersion) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 3)...)
+	b := dst[n:]
+	b[0] = p.Lingo
+	b[1] = p.Major
+	b[2] = p.Minor
+	return dst, nil
+}
+
+func (p *ReturnLingoProtocolVersion) UnmarshalBinary(data []byte) error {
+	if len(data) < 3 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Lingo = data[0]
+	p.Major = data[1]
+	p.Minor = data[2]
+	return nil
+}
+
+func (*RequestTransportMaxPayloadSize) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*RequestTransportMaxPayloadSize) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*RequestTransportMaxPayloadSize) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *ReturnTransportMaxPayloadSize) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 2))
+}
+
+func (p *ReturnTransportMaxPayloadSize) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 2)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint16(b[0:], p.MaxPayload)
+	return dst, nil
+}
+
+func (p *ReturnTransportMaxPayloadSize) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return io.ErrUnexpectedEOF
+	}
+	p.MaxPayload = binary.BigEndian.Uint16(data[0:])
+	return nil
+}
+
+func (p *IdentifyDeviceLingoes) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 12))
+}
+
+func (p *IdentifyDeviceLingoes) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 12)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], p.Lingos)
+	binary.BigEndian.PutUint32(b[4:], p.Options)
+	binary.BigEndian.PutUint32(b[8:], p.DeviceID)
+	return dst, nil
+}
+
+func (p *IdentifyDeviceLingoes) UnmarshalBinary(data []byte) error {
+	if len(data) < 12 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Lingos = binary.BigEndian.Uint32(data[0:])
+	p.Options = binary.BigEndian.Uint32(data[4:])
+	p.DeviceID = binary.BigEndian.Uint32(data[8:])
+	return nil
+}
+
+func (*GetDevAuthenticationInfo) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetDevAuthenticationInfo) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetDevAuthenticationInfo) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *AckDevAuthenticationInfo) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *AckDevAuthenticationInfo) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = byte(p.Status)
+	return dst, nil
+}
+
+func (p *AckDevAuthenticationInfo) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Status = DevAuthInfoStatus(data[0])
+	return nil
+}
+
+func (p *GetDevAuthenticationSignatureV1) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 17))
+}
+
+func (p *GetDevAuthenticationSignatureV1) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 17)...)
+	b := dst[n:]
+	copy(b[0:16], p.Challenge[:])
+	b[16] = p.Counter
+	return dst, nil
+}
+
+func (p *GetDevAuthenticationSignatureV1) UnmarshalBinary(data []byte) error {
+	if len(data) < 17 {
+		return io.ErrUnexpectedEOF
+	}
+	copy(p.Challenge[:], data[0:16])
+	p.Counter = data[16]
+	return nil
+}
+
+func (p *GetDevAuthenticationSignatureV2) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 21))
+}
+
+func (p *GetDevAuthenticationSignatureV2) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 21)...)
+	b := dst[n:]
+	copy(b[0:20], p.Challenge[:])
+	b[20] = p.Counter
+	return dst, nil
+}
+
+func (p *GetDevAuthenticationSignatureV2) UnmarshalBinary(data []byte) error {
+	if len(data) < 21 {
+		return io.ErrUnexpectedEOF
+	}
+	copy(p.Challenge[:], data[0:20])
+	p.Counter = data[20]
+	return nil
+}
+
+func (p *AckDevAuthenticationStatus) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *AckDevAuthenticationStatus) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = byte(p.Status)
+	return dst, nil
+}
+
+func (p *AckDevAuthenticationStatus) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Status = DevAuthStatus(data[0])
+	return nil
+}
+
+func (*GetiPodAuthenticationInfo) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetiPodAuthenticationInfo) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetiPodAuthenticationInfo) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *AckiPodAuthenticationInfo) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *AckiPodAuthenticationInfo) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = p.Status
+	return dst, nil
+}
+
+func (p *AckiPodAuthenticationInfo) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Status = data[0]
+	return nil
+}
+
+func (p *GetiPodAuthenticationSignature) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 21))
+}
+
+func (p *GetiPodAuthenticationSignature) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 21)...)
+	b := dst[n:]
+	copy(b[0:20], p.Challenge[:])
+	b[20] = p.Counter
+	return dst, nil
+}
+
+func (p *GetiPodAuthenticationSignature) UnmarshalBinary(data []byte) error {
+	if len(data) < 21 {
+		return io.ErrUnexpectedEOF
+	}
+	copy(p.Challenge[:], data[0:20])
+	p.Counter = data[20]
+	return nil
+}
+
+func (p *RetiPodAuthenticationSignature) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 20))
+}
+
+func (p *RetiPodAuthenticationSignature) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 20)...)
+	b := dst[n:]
+	copy(b[0:20], p.Signature[:])
+	return dst, nil
+}
+
+func (p *RetiPodAuthenticationSignature) UnmarshalBinary(data []byte) error {
+	if len(data) < 20 {
+		return io.ErrUnexpectedEOF
+	}
+	copy(p.Signature[:], data[0:20])
+	return nil
+}
+
+func (p *AckiPodAuthenticationStatus) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *AckiPodAuthenticationStatus) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = p.Status
+	return dst, nil
+}
+
+func (p *AckiPodAuthenticationStatus) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Status = data[0]
+	return nil
+}
+
+func (p *NotifyiPodStateChange) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *NotifyiPodStateChange) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = p.StateChange
+	return dst, nil
+}
+
+func (p *NotifyiPodStateChange) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.StateChange = data[0]
+	return nil
+}
+
+func (*GetiPodOptions) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetiPodOptions) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetiPodOptions) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *RetiPodOptions) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 8))
+}
+
+func (p *RetiPodOptions) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 8)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint64(b[0:], p.Options)
+	return dst, nil
+}
+
+func (p *RetiPodOptions) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Options = binary.BigEndian.Uint64(data[0:])
+	return nil
+}
+
+func (p *GetAccessoryInfo) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *GetAccessoryInfo) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = p.InfoType
+	return dst, nil
+}
+
+func (p *GetAccessoryInfo) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.InfoType = data[0]
+	return nil
+}
+
+func (p *GetiPodPreferences) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *GetiPodPreferences) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = p.PrefClassID
+	return dst, nil
+}
+
+func (p *GetiPodPreferences) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.PrefClassID = data[0]
+	return nil
+}
+
+func (p *RetiPodPreferences) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 2))
+}
+
+func (p *RetiPodPreferences) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 2)...)
+	b := dst[n:]
+	b[0] = p.PrefClassID
+	b[1] = p.PrefClassSettingID
+	return dst, nil
+}
+
+func (p *RetiPodPreferences) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return io.ErrUnexpectedEOF
+	}
+	p.PrefClassID = data[0]
+	p.PrefClassSettingID = data[1]
+	return nil
+}
+
+func (p *SetiPodPreferences) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 3))
+}
+
+func (p *SetiPodPreferences) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 3)...)
+	b := dst[n:]
+	b[0] = p.PrefClassID
+	b[1] = p.PrefClassSettingID
+	b[2] = p.RestoreOnExit
+	return dst, nil
+}
+
+func (p *SetiPodPreferences) UnmarshalBinary(data []byte) error {
+	if len(data) < 3 {
+		return io.ErrUnexpectedEOF
+	}
+	p.PrefClassID = data[0]
+	p.PrefClassSettingID = data[1]
+	p.RestoreOnExit = data[2]
+	return nil
+}
+
+func (*GetUIMode) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetUIMode) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetUIMode) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *RetUIMode) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *RetUIMode) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = byte(p.UIMode)
+	return dst, nil
+}
+
+func (p *RetUIMode) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.UIMode = UIMode(data[0])
+	return nil
+}
+
+func (p *SetUIMode) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *SetUIMode) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = byte(p.UIMode)
+	return dst, nil
+}
+
+func (p *SetUIMode) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.UIMode = UIMode(data[0])
+	return nil
+}
+
+func (*StartIDPS) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*StartIDPS) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*StartIDPS) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *EndIDPS) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *EndIDPS) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = byte(p.AccEndIDPSStatus)
+	return dst, nil
+}
+
+func (p *EndIDPS) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.AccEndIDPSStatus = AccEndIDPSStatus(data[0])
+	return nil
+}
+
+func (p *IDPSStatus) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *IDPSStatus) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = byte(p.Status)
+	return dst, nil
+}
+
+func (p *IDPSStatus) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.Status = IDPSStatusEnum(data[0])
+	return nil
+}
+
+func (p *OpenDataSessionForProtocol) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 3))
+}
+
+func (p *OpenDataSessionForProtocol) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 3)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint16(b[0:], p.SessionID)
+	b[2] = p.ProtocolIndex
+	return dst, nil
+}
+
+func (p *OpenDataSessionForProtocol) UnmarshalBinary(data []byte) error {
+	if len(data) < 3 {
+		return io.ErrUnexpectedEOF
+	}
+	p.SessionID = binary.BigEndian.Uint16(data[0:])
+	p.ProtocolIndex = data[2]
+	return nil
+}
+
+func (p *CloseDataSession) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 2))
+}
+
+func (p *CloseDataSession) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 2)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint16(b[0:], p.SessionID)
+	return dst, nil
+}
+
+func (p *CloseDataSession) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return io.ErrUnexpectedEOF
+	}
+	p.SessionID = binary.BigEndian.Uint16(data[0:])
+	return nil
+}
+
+func (p *DevACK) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 2))
+}
+
+func (p *DevACK) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 2)...)
+	b := dst[n:]
+	b[0] = p.AckStatus
+	b[1] = p.CmdID
+	return dst, nil
+}
+
+func (p *DevACK) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return io.ErrUnexpectedEOF
+	}
+	p.AckStatus = data[0]
+	p.CmdID = data[1]
+	return nil
+}
+
+func (p *SetAccStatusNotification) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 4))
+}
+
+func (p *SetAccStatusNotification) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 4)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], p.StatusMask)
+	return dst, nil
+}
+
+func (p *SetAccStatusNotification) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	p.StatusMask = binary.BigEndian.Uint32(data[0:])
+	return nil
+}
+
+func (p *RetAccStatusNotification) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 4))
+}
+
+func (p *RetAccStatusNotification) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 4)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint32(b[0:], p.StatusMask)
+	return dst, nil
+}
+
+func (p *RetAccStatusNotification) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	p.StatusMask = binary.BigEndian.Uint32(data[0:])
+	return nil
+}
+
+func (p *SetEventNotification) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 8))
+}
+
+func (p *SetEventNotification) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 8)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint64(b[0:], uint64(p.EventMask))
+	return dst, nil
+}
+
+func (p *SetEventNotification) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 {
+		return io.ErrUnexpectedEOF
+	}
+	p.EventMask = EventMask(binary.BigEndian.Uint64(data[0:]))
+	return nil
+}
+
+func (p *GetiPodOptionsForLingo) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *GetiPodOptionsForLingo) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = p.LingoID
+	return dst, nil
+}
+
+func (p *GetiPodOptionsForLingo) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.LingoID = data[0]
+	return nil
+}
+
+func (p *RetiPodOptionsForLingo) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 9))
+}
+
+func (p *RetiPodOptionsForLingo) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 9)...)
+	b := dst[n:]
+	b[0] = p.LingoID
+	binary.BigEndian.PutUint64(b[1:], p.Options)
+	return dst, nil
+}
+
+func (p *RetiPodOptionsForLingo) UnmarshalBinary(data []byte) error {
+	if len(data) < 9 {
+		return io.ErrUnexpectedEOF
+	}
+	p.LingoID = data[0]
+	p.Options = binary.BigEndian.Uint64(data[1:])
+	return nil
+}
+
+func (*GetEventNotification) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetEventNotification) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetEventNotification) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *RetEventNotification) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 8))
+}
+
+func (p *RetEventNotification) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 8)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint64(b[0:], uint64(p.EventMask))
+	return dst, nil
+}
+
+func (p *RetEventNotification) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 {
+		return io.ErrUnexpectedEOF
+	}
+	p.EventMask = EventMask(binary.BigEndian.Uint64(data[0:]))
+	return nil
+}
+
+func (*GetSupportedEventNotification) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetSupportedEventNotification) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetSupportedEventNotification) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *CancelCommand) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 5))
+}
+
+func (p *CancelCommand) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 5)...)
+	b := dst[n:]
+	b[0] = p.LingoID
+	binary.BigEndian.PutUint16(b[1:], p.CmdID)
+	binary.BigEndian.PutUint16(b[3:], p.TransactionID)
+	return dst, nil
+}
+
+func (p *CancelCommand) UnmarshalBinary(data []byte) error {
+	if len(data) < 5 {
+		return io.ErrUnexpectedEOF
+	}
+	p.LingoID = data[0]
+	p.CmdID = binary.BigEndian.Uint16(data[1:])
+	p.TransactionID = binary.BigEndian.Uint16(data[3:])
+	return nil
+}
+
+func (p *RetSupportedEventNotification) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 8))
+}
+
+func (p *RetSupportedEventNotification) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 8)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint64(b[0:], uint64(p.EventMask))
+	return dst, nil
+}
+
+func (p *RetSupportedEventNotification) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 {
+		return io.ErrUnexpectedEOF
+	}
+	p.EventMask = EventMask(binary.BigEndian.Uint64(data[0:]))
+	return nil
+}
+
+func (p *SetAvailableCurrent) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 2))
+}
+
+func (p *SetAvailableCurrent) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 2)...)
+	b := dst[n:]
+	binary.BigEndian.PutUint16(b[0:], p.CurrentLimit)
+	return dst, nil
+}
+
+func (p *SetAvailableCurrent) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return io.ErrUnexpectedEOF
+	}
+	p.CurrentLimit = binary.BigEndian.Uint16(data[0:])
+	return nil
+}
+
+func (*GetNowPlayingFocusApp) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetNowPlayingFocusApp) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetNowPlayingFocusApp) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *GetAccessoryInfo2) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 8))
+}
+
+func (p *GetAccessoryInfo2) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 8)...)
+	b := dst[n:]
+	b[0] = p.InfoType
+	binary.BigEndian.PutUint32(b[1:], p.ModelID)
+	b[5] = p.Major
+	b[6] = p.Minor
+	b[7] = p.Rev
+	return dst, nil
+}
+
+func (p *GetAccessoryInfo2) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 {
+		return io.ErrUnexpectedEOF
+	}
+	p.InfoType = data[0]
+	p.ModelID = binary.BigEndian.Uint32(data[1:])
+	p.Major = data[5]
+	p.Minor = data[6]
+	p.Rev = data[7]
+	return nil
+}
+
+func (p *GetAccessoryInfo3) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 2))
+}
+
+func (p *GetAccessoryInfo3) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 2)...)
+	b := dst[n:]
+	b[0] = p.InfoType
+	b[1] = p.LingoID
+	return dst, nil
+}
+
+func (p *GetAccessoryInfo3) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return io.ErrUnexpectedEOF
+	}
+	p.InfoType = data[0]
+	p.LingoID = data[1]
+	return nil
+}
+
+func (p *RetAccessoryInfoCaps) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 5))
+}
+
+func (p *RetAccessoryInfoCaps) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 5)...)
+	b := dst[n:]
+	b[0] = p.InfoType
+	binary.BigEndian.PutUint32(b[1:], p.Caps)
+	return dst, nil
+}
+
+func (p *RetAccessoryInfoCaps) UnmarshalBinary(data []byte) error {
+	if len(data) < 5 {
+		return io.ErrUnexpectedEOF
+	}
+	p.InfoType = data[0]
+	p.Caps = binary.BigEndian.Uint32(data[1:])
+	return nil
+}
+
+func (p *RetAccessoryInfoMinFirmware) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 8))
+}
+
+func (p *RetAccessoryInfoMinFirmware) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 8)...)
+	b := dst[n:]
+	b[0] = p.InfoType
+	binary.BigEndian.PutUint32(b[1:], p.ModelID)
+	b[5] = p.Major
+	b[6] = p.Minor
+	b[7] = p.Rev
+	return dst, nil
+}
+
+func (p *RetAccessoryInfoMinFirmware) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 {
+		return io.ErrUnexpectedEOF
+	}
+	p.InfoType = data[0]
+	p.ModelID = binary.BigEndian.Uint32(data[1:])
+	p.Major = data[5]
+	p.Minor = data[6]
+	p.Rev = data[7]
+	return nil
+}
+
+func (p *RetAccessoryInfoLingoVersion) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 4))
+}
+
+func (p *RetAccessoryInfoLingoVersion) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 4)...)
+	b := dst[n:]
+	b[0] = p.InfoType
+	b[1] = p.LingoID
+	b[2] = p.Major
+	b[3] = p.Minor
+	return dst, nil
+}
+
+func (p *RetAccessoryInfoLingoVersion) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	p.InfoType = data[0]
+	p.LingoID = data[1]
+	p.Major = data[2]
+	p.Minor = data[3]
+	return nil
+}
@@ -0,0 +1,320 @@
+// Code generated by "ipodgen"; DO NOT EDIT.
+
+package simpleremote
+
+import (
+	"io"
+
+	"github.com/oandrew/ipod"
+)
+
+// lingosPayloads is the static payload table of Lingos
+var lingosPayloads = []ipod.PayloadDef{
+	{ID: 0x00, Size: 1, New: func() interface{} { return &ContextButtonStatus{} }},
+	{ID: 0x01, Size: 0, New: func() interface{} { return &ACK{} }},
+	{ID: 0x03, Size: 1, New: func() interface{} { return &VideoButtonStatus{} }},
+	{ID: 0x04, Size: 1, New: func() interface{} { return &AudioButtonStatus{} }},
+	{ID: 0x0B, Size: 2, New: func() interface{} { return &iPodOutButtonStatus{} }},
+	{ID: 0x0C, Size: 0, New: func() interface{} { return &RotationInputStatus{} }},
+	{ID: 0x0D, Size: 0, New: func() interface{} { return &RadioButtonStatus{} }},
+	{ID: 0x0E, Size: 0, New: func() interface{} { return &CameraButtonStatus{} }},
+	{ID: 0x0F, Size: 0, New: func() interface{} { return &RegisterDescriptor{} }},
+	{ID: 0x10, Size: 0, New: func() interface{} { return &SendHIDReportToiPod{} }},
+	{ID: 0x11, Size: 0, New: func() interface{} { return &SendHIDReportToAcc{} }},
+	{ID: 0x12, Size: 0, New: func() interface{} { return &UnregisterDescriptor{} }},
+	{ID: 0x13, Size: 0, New: func() interface{} { return &AccessibilityEvent{} }},
+	{ID: 0x14, Size: 0, New: func() interface{} { return &GetAccessibilityParameter{} }},
+	{ID: 0x15, Size: 0, New: func() interface{} { return &RetAccessibilityParameter{} }},
+	{ID: 0x16, Size: 0, New: func() interface{} { return &SetAccessibilityParameter{} }},
+	{ID: 0x17, Size: 0, New: func() interface{} { return &GetCurrentItemProperty{} }},
+	{ID: 0x18, Size: 0, New: func() interface{} { return &RetCurrentItemProperty{} }},
+	{ID: 0x19, Size: 0, New: func() interface{} { return &SetContext{} }},
+	{ID: 0x1A, Size: 0, New: func() interface{} { return &AccParameterChanged{} }},
+	{ID: 0x81, Size: 0, New: func() interface{} { return &DevACK{} }},
+}
+
+func (p *ContextButtonStatus) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *ContextButtonStatus) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = p.ButtonStates
+	return dst, nil
+}
+
+func (p *ContextButtonStatus) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.ButtonStates = data[0]
+	return nil
+}
+
+func (*ACK) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*ACK) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*ACK) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (p *VideoButtonStatus) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *VideoButtonStatus) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = p.ButtonStates
+	return dst, nil
+}
+
+func (p *VideoButtonStatus) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.ButtonStates = data[0]
+	return nil
+}
+
+func (p *AudioButtonStatus) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 1))
+}
+
+func (p *AudioButtonStatus) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 1)...)
+	b := dst[n:]
+	b[0] = p.ButtonStates
+	return dst, nil
+}
+
+func (p *AudioButtonStatus) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	p.ButtonStates = data[0]
+	return nil
+}
+
+func (p *iPodOutButtonStatus) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, 2))
+}
+
+func (p *iPodOutButtonStatus) AppendBinary(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, 2)...)
+	b := dst[n:]
+	b[0] = p.ButtonSource
+	b[1] = p.ButtonMask
+	return dst, nil
+}
+
+func (p *iPodOutButtonStatus) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return io.ErrUnexpectedEOF
+	}
+	p.ButtonSource = data[0]
+	p.ButtonMask = data[1]
+	return nil
+}
+
+func (*RotationInputStatus) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*RotationInputStatus) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*RotationInputStatus) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*RadioButtonStatus) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*RadioButtonStatus) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*RadioButtonStatus) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*CameraButtonStatus) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*CameraButtonStatus) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*CameraButtonStatus) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*RegisterDescriptor) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*RegisterDescriptor) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*RegisterDescriptor) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*SendHIDReportToiPod) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*SendHIDReportToiPod) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*SendHIDReportToiPod) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*SendHIDReportToAcc) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*SendHIDReportToAcc) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*SendHIDReportToAcc) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*UnregisterDescriptor) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*UnregisterDescriptor) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*UnregisterDescriptor) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*AccessibilityEvent) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*AccessibilityEvent) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*AccessibilityEvent) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*GetAccessibilityParameter) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetAccessibilityParameter) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetAccessibilityParameter) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*RetAccessibilityParameter) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*RetAccessibilityParameter) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*RetAccessibilityParameter) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*SetAccessibilityParameter) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*SetAccessibilityParameter) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*SetAccessibilityParameter) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*GetCurrentItemProperty) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*GetCurrentItemProperty) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*GetCurrentItemProperty) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*RetCurrentItemProperty) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*RetCurrentItemProperty) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*RetCurrentItemProperty) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*SetContext) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*SetContext) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*SetContext) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*AccParameterChanged) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*AccParameterChanged) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*AccParameterChanged) UnmarshalBinary(data []byte) error {
+	return nil
+}
+
+func (*DevACK) MarshalBinary() ([]byte, error) {
+	return nil, nil
+}
+
+func (*DevACK) AppendBinary(dst []byte) ([]byte, error) {
+	return dst, nil
+}
+
+func (*DevACK) UnmarshalBinary(data []byte) error {
+	return nil
+}
@@ -5,11 +5,12 @@ import (
 )
 
 func init() {
-	ipod.RegisterLingos(LingoSimpleRemotelID, Lingos)
+	ipod.RegisterPayloads(LingoSimpleRemotelID, lingosPayloads)
 }
 
 const LingoSimpleRemotelID = 0x02
 
+//go:generate go run ../cmd/ipodgen
 var Lingos struct {
 	ContextButtonStatus       `id:"0x00"`
 	ACK                       `id:"0x01"`
@@ -1,12 +1,10 @@
 package ipod
 
 import (
-	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
 	"reflect"
-	"sort"
 	"strconv"
 )
 
@@ -42,44 +40,30 @@ func cmdIDLen(lingoID uint16) int {
 	}
 }
 
-func marshalLingoCmdID(w io.Writer, id LingoCmdID) error {
-	err := binary.Write(w, binary.BigEndian, byte(id.LingoID()))
-	if err != nil {
-		return err
-	}
-	switch cmdIDLen(id.LingoID()) {
-	case 2:
-		return binary.Write(w, binary.BigEndian, uint16(id.CmdID()))
-	default:
-		return binary.Write(w, binary.BigEndian, byte(id.CmdID()))
+// appendLingoCmdID appends the encoded lingo and command ID to b
+func appendLingoCmdID(b []byte, id LingoCmdID) []byte {
+	b = append(b, byte(id.LingoID()))
+	if cmdIDLen(id.LingoID()) == 2 {
+		return append(b, byte(id.CmdID()>>8), byte(id.CmdID()))
 	}
+	return append(b, byte(id.CmdID()))
 }
 
-func unmarshalLingoCmdID(r io.Reader, id *LingoCmdID) error {
-	var lingoID byte
-	err := binary.Read(r, binary.BigEndian, &lingoID)
-	if err != nil {
-		return err
+// decodeLingoCmdID decodes the lingo and command ID at the start of data
+// and returns its encoded size
+func decodeLingoCmdID(data []byte) (LingoCmdID, int, error) {
+	if len(data) == 0 {
+		return 0, 0, io.EOF
 	}
-
-	switch cmdIDLen(uint16(lingoID)) {
-	case 2:
-		var cmdID uint16
-		err := binary.Read(r, binary.BigEndian, &cmdID)
-		if err != nil {
-			return err
-		}
-		*id = NewLingoCmdID(uint16(lingoID), uint16(cmdID))
-		return nil
-	default:
-		var cmdID uint8
-		err := binary.Read(r, binary.BigEndian, &cmdID)
-		if err != nil {
-			return err
-		}
-		*id = NewLingoCmdID(uint16(lingoID), uint16(cmdID))
-		return nil
+	lingoID := uint16(data[0])
+	n := 1 + cmdIDLen(lingoID)
+	if len(data) < n {
+		return 0, 0, io.ErrUnexpectedEOF
 	}
+	if n == 3 {
+		return NewLingoCmdID(lingoID, binary.BigEndian.Uint16(data[1:3])), n, nil
+	}
+	return NewLingoCmdID(lingoID, uint16(data[1])), n, nil
 }
 
 func NewLingoCmdID(lingo, cmd uint16) LingoCmdID {
@@ -91,61 +75,13 @@ func parseIdTag(tag *reflect.StructTag) (uint16, error) {
 	return uint16(id), err
 }
 
-var mIDToType = make(map[LingoCmdID][]reflect.Type)
-var mTypeToID = make(map[reflect.Type]LingoCmdID)
-
-func storeMapping(cmd LingoCmdID, t reflect.Type) {
-	mIDToType[cmd] = append(mIDToType[cmd], t)
-	mTypeToID[t] = cmd
-}
-
-// RegisterLingos registers a set of lingo commands
-func RegisterLingos(lingoID uint8, m interface{}) error {
-	lingos := reflect.TypeOf(m)
-
-	for i := 0; i < lingos.NumField(); i++ {
-		cmd := lingos.Field(i)
-		cmdID, err := parseIdTag(&cmd.Tag)
-		if err != nil {
-			return fmt.Errorf("register lingos: parse id tag err: %v", err)
-		}
-
-		storeMapping(NewLingoCmdID(uint16(lingoID), cmdID), cmd.Type)
-	}
-	return nil
-
-}
-
-// DumpLingos returns a list of all registered lingos and commands
-func DumpLingos() string {
-	type cmd struct {
-		id   LingoCmdID
-		name string
-	}
-	var cmds []cmd
-	for id, types := range mIDToType {
-		cmds = append(cmds, cmd{id, types[0].String()})
-	}
-	sort.Slice(cmds, func(i, j int) bool {
-		return cmds[i].id < cmds[j].id
-	})
-	buf := bytes.Buffer{}
-	for _, cmd := range cmds {
-		fmt.Fprintf(&buf, "%s\t%s\n", cmd.id.GoString(), cmd.name)
-	}
-	return buf.String()
-
-}
-
-// LookupID finds a registered LingoCmdID by the type of v
-// i.e. reverse to Lookup
-func LookupID(v interface{}) (id LingoCmdID, ok bool) {
-	t := reflect.TypeOf(v)
-	if t.Kind() != reflect.Ptr {
-		panic(fmt.Sprintf("payload is not pointer: %v", v))
+// wireSize returns the encoded size of a payload type
+// or -1 if the type has a variable length
+func wireSize(t reflect.Type) int {
+	if isTagged(t) {
+		return -1
 	}
-	id, ok = mTypeToID[t.Elem()]
-	return
+	return binary.Size(reflect.Zero(t).Interface())
 }
 
 // LookupResult contains the result of a Lookup.
@@ -155,33 +91,3 @@ type LookupResult struct {
 	Payload     interface{}
 	Transaction bool
 }
-
-// Lookup finds a the payload by LingoCmdID using payloadSize as a hint
-func Lookup(id LingoCmdID, payloadSize int) (LookupResult, bool) {
-	payloads, ok := mIDToType[id]
-	if !ok {
-		return LookupResult{}, false
-	}
-	for _, p := range payloads {
-		switch {
-		case p.Size() == uintptr(payloadSize):
-			return LookupResult{
-				Payload:     reflect.New(p).Interface(),
-				Transaction: false,
-			}, true
-		case p.Size() == uintptr(payloadSize-2):
-			return LookupResult{
-				Payload:     reflect.New(p).Interface(),
-				Transaction: true,
-			}, true
-		}
-	}
-	if len(payloads) == 1 {
-		return LookupResult{
-			Payload:     reflect.New(payloads[0]).Interface(),
-			Transaction: true,
-		}, true
-	}
-
-	return LookupResult{}, false
-}
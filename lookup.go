@@ -0,0 +1,263 @@
+package ipod
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TransactionMode tells whether the packets of a link carry a transaction ID
+type TransactionMode uint8
+
+const (
+	// TransactionModeUnknown is the mode before it is known,
+	// variable-size payloads are assumed to carry a transaction
+	TransactionModeUnknown TransactionMode = iota
+	// TransactionModeOn is the mode after StartIDPS
+	TransactionModeOn
+	// TransactionModeOff is the mode of accessories that don't use transactions
+	TransactionModeOff
+)
+
+func (m TransactionMode) String() string {
+	switch m {
+	case TransactionModeOn:
+		return "on"
+	case TransactionModeOff:
+		return "off"
+	default:
+		return "unknown"
+	}
+}
+
+// LookupContext is the state of the link LookupPayload uses
+// to choose between the payloads registered for a command
+type LookupContext struct {
+	// Version is the protocol version of the lingo, zero if unknown
+	Version         Version
+	TransactionMode TransactionMode
+}
+
+// LookupError is returned by LookupPayload when no payload
+// or more than one match the packet
+type LookupError struct {
+	ID LingoCmdID
+	// Candidates are the matching payloads if the choice is ambiguous
+	Candidates []string
+}
+
+func (e *LookupError) Error() string {
+	if len(e.Candidates) == 0 {
+		return fmt.Sprintf("unknown cmd %v", e.ID)
+	}
+	return fmt.Sprintf("ambiguous cmd %v: %s", e.ID, strings.Join(e.Candidates, ", "))
+}
+
+// rule is a discriminator of a payload layout, it is called
+// with the payload data without the transaction
+type rule func(ctx LookupContext, trx bool, payload []byte) bool
+
+// parseWhenTag parses the discriminator rules of a payload, i.e.
+//
+//	ACKPending `id:"0x02" when:"byte0=0x06"`
+//
+// The comma-separated rules must all hold:
+//
+//	byteN=V       payload byte N equals V
+//	byteN!=V      payload byte N is missing or not equal to V
+//	byteN<=V      payload byte N is at most V
+//	version>=X.Y  the lingo protocol version is at least X.Y
+//	trx           the packet carries a transaction
+//	notrx         the packet carries no transaction
+func parseWhenTag(tag string) ([]rule, error) {
+	if tag == "" {
+		return nil, nil
+	}
+	var rules []rule
+	for _, cond := range strings.Split(tag, ",") {
+		r, err := parseRule(strings.TrimSpace(cond))
+		if err != nil {
+			return nil, fmt.Errorf("when %q: %v", cond, err)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func parseRule(cond string) (rule, error) {
+	switch {
+	case cond == "trx":
+		return func(_ LookupContext, trx bool, _ []byte) bool { return trx }, nil
+	case cond == "notrx":
+		return func(_ LookupContext, trx bool, _ []byte) bool { return !trx }, nil
+	case strings.HasPrefix(cond, "version>="):
+		v, err := parseVersion(strings.TrimPrefix(cond, "version>="))
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx LookupContext, _ bool, _ []byte) bool {
+			return ctx.Version == Version{} || !ctx.Version.Less(v)
+		}, nil
+	case strings.HasPrefix(cond, "byte"):
+		parts := strings.SplitN(strings.TrimPrefix(cond, "byte"), "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("bad byte rule")
+		}
+		ne := strings.HasSuffix(parts[0], "!")
+		le := strings.HasSuffix(parts[0], "<")
+		i, err := strconv.ParseUint(strings.TrimRight(parts[0], "!<"), 10, 16)
+		if err != nil {
+			return nil, err
+		}
+		b, err := strconv.ParseUint(parts[1], 0, 8)
+		if err != nil {
+			return nil, err
+		}
+		if le {
+			return func(_ LookupContext, _ bool, payload []byte) bool {
+				return int(i) < len(payload) && payload[i] <= byte(b)
+			}, nil
+		}
+		return func(_ LookupContext, _ bool, payload []byte) bool {
+			eq := int(i) < len(payload) && payload[i] == byte(b)
+			return eq != ne
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown rule")
+	}
+}
+
+func parseVersion(s string) (Version, error) {
+	parts := strings.SplitN(s, ".", 2)
+	major, err := strconv.ParseUint(parts[0], 10, 8)
+	if err != nil {
+		return Version{}, err
+	}
+	var minor uint64
+	if len(parts) == 2 {
+		if minor, err = strconv.ParseUint(parts[1], 10, 8); err != nil {
+			return Version{}, err
+		}
+	}
+	return Version{uint8(major), uint8(minor)}, nil
+}
+
+func (p *payloadType) match(ctx LookupContext, trx bool, data []byte) bool {
+	if trx {
+		if len(data) < 2 {
+			return false
+		}
+		data = data[2:]
+	}
+	if p.size >= 0 && len(data) != p.size {
+		return false
+	}
+	for _, r := range p.rules {
+		if !r(ctx, trx, data) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *payloadType) String() string {
+	return p.t.String()
+}
+
+type lookupMatch struct {
+	p   *payloadType
+	trx bool
+}
+
+// LookupPayload chooses the payload of the command id for the packet data
+// following the command ID. The registered payloads are filtered by
+// the lingo version and the transaction mode of ctx, by their size
+// and by the rules of their when tag.
+// Of several matching payloads the one introduced in the newest version wins,
+// if that doesn't decide a *LookupError listing the candidates is returned.
+//
+// If nothing matches and only one payload is registered for the command,
+// it is returned assuming a transaction.
+func (r *Registry) LookupPayload(id LingoCmdID, data []byte, ctx LookupContext) (LookupResult, error) {
+	p, trx, err := r.lookupType(id, data, ctx)
+	if err != nil {
+		return LookupResult{}, err
+	}
+	return LookupResult{
+		Payload:     p.new(),
+		Transaction: trx,
+	}, nil
+}
+
+// lookupType implements LookupPayload without allocating
+// unless there is an error
+func (r *Registry) lookupType(id LingoCmdID, data []byte, ctx LookupContext) (*payloadType, bool, error) {
+	var payloadsBuf [8]*payloadType
+	payloads := payloadsBuf[:0]
+	for i, p := range r.idToType[id] {
+		if ctx.Version == (Version{}) || !ctx.Version.Less(p.minVersion) {
+			payloads = append(payloads, &r.idToType[id][i])
+		}
+	}
+	if len(payloads) == 0 {
+		return nil, false, &LookupError{ID: id}
+	}
+
+	var matchesBuf [8]lookupMatch
+	matches := matchesBuf[:0]
+	for _, p := range payloads {
+		for _, trx := range [...]bool{false, true} {
+			switch {
+			case ctx.TransactionMode == TransactionModeOn && !trx,
+				ctx.TransactionMode == TransactionModeOff && trx,
+				ctx.TransactionMode == TransactionModeUnknown && p.size < 0 && !trx:
+				continue
+			}
+			if p.match(ctx, trx, data) {
+				matches = append(matches, lookupMatch{p, trx})
+			}
+		}
+	}
+
+	if len(matches) > 1 {
+		newest := matches[0].p.minVersion
+		for _, m := range matches {
+			if newest.Less(m.p.minVersion) {
+				newest = m.p.minVersion
+			}
+		}
+		n := 0
+		for _, m := range matches {
+			if m.p.minVersion == newest {
+				matches[n] = m
+				n++
+			}
+		}
+		matches = matches[:n]
+	}
+
+	switch {
+	case len(matches) == 1:
+		return matches[0].p, matches[0].trx, nil
+	case len(matches) > 1:
+		err := &LookupError{ID: id}
+		for _, m := range matches {
+			c := m.p.String()
+			if m.trx {
+				c += "+trx"
+			}
+			err.Candidates = append(err.Candidates, c)
+		}
+		return nil, false, err
+	}
+
+	if p := payloads[0]; len(payloads) == 1 && ctx.TransactionMode != TransactionModeOff {
+		for _, r := range p.rules {
+			if len(data) < 2 || !r(ctx, true, data[2:]) {
+				return nil, false, &LookupError{ID: id}
+			}
+		}
+		return p, true, nil
+	}
+	return nil, false, &LookupError{ID: id}
+}
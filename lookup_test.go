@@ -0,0 +1,170 @@
+package ipod_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/oandrew/ipod"
+	"github.com/oandrew/ipod/lingo-extremote"
+	"github.com/oandrew/ipod/lingo-general"
+)
+
+type LookupShort struct {
+	Status uint8
+}
+
+type LookupLong struct {
+	Status uint8
+	V      uint16
+}
+
+type LookupOther struct {
+	Status uint8
+	V      uint16
+}
+
+type LookupVar struct {
+	Data []byte `ipod:"rest"`
+}
+
+func newLookupRegistry(t *testing.T) *ipod.Registry {
+	r := ipod.NewRegistry()
+	err := r.RegisterLingos(0xa2, struct {
+		LookupShort `id:"0x01"`
+		LookupLong  `id:"0x01" when:"byte0=0x01"`
+		LookupOther `id:"0x01" when:"byte0!=0x01"`
+		LookupVar   `id:"0x02"`
+	}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.RegisterLingos(0xa3, struct {
+		LookupLong  `id:"0x01"`
+		LookupOther `id:"0x01"`
+	}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestRegistry_LookupPayload(t *testing.T) {
+	r := newLookupRegistry(t)
+	on := ipod.LookupContext{TransactionMode: ipod.TransactionModeOn}
+	off := ipod.LookupContext{TransactionMode: ipod.TransactionModeOff}
+	tests := []struct {
+		name    string
+		id      ipod.LingoCmdID
+		data    []byte
+		ctx     ipod.LookupContext
+		want    interface{}
+		wantTrx bool
+		wantErr bool
+	}{
+		{"short", ipod.NewLingoCmdID(0xa2, 0x01), []byte{0x01}, ipod.LookupContext{}, &LookupShort{}, false, false},
+		// 3 bytes is both LookupShort+trx and a 3 byte payload
+		{"long", ipod.NewLingoCmdID(0xa2, 0x01), []byte{0x01, 0x00, 0x02}, off, &LookupLong{}, false, false},
+		{"other", ipod.NewLingoCmdID(0xa2, 0x01), []byte{0x02, 0x00, 0x02}, off, &LookupOther{}, false, false},
+		{"short trx", ipod.NewLingoCmdID(0xa2, 0x01), []byte{0x00, 0x01, 0x05}, on, &LookupShort{}, true, false},
+		{"ambiguous", ipod.NewLingoCmdID(0xa2, 0x01), []byte{0x01, 0x00, 0x02}, ipod.LookupContext{}, nil, false, true},
+		{"long trx", ipod.NewLingoCmdID(0xa2, 0x01), []byte{0x00, 0x01, 0x01, 0x00, 0x02}, ipod.LookupContext{}, &LookupLong{}, true, false},
+		{"no rules", ipod.NewLingoCmdID(0xa3, 0x01), []byte{0x01, 0x00, 0x02}, ipod.LookupContext{}, nil, false, true},
+		{"variable", ipod.NewLingoCmdID(0xa2, 0x02), []byte{0x00, 0x01, 0x02}, ipod.LookupContext{}, &LookupVar{}, true, false},
+		{"variable notrx", ipod.NewLingoCmdID(0xa2, 0x02), []byte{0x00, 0x01, 0x02}, off, &LookupVar{}, false, false},
+		{"unknown", ipod.NewLingoCmdID(0xa2, 0x03), []byte{}, ipod.LookupContext{}, nil, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.LookupPayload(tt.id, tt.data, tt.ctx)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Registry.LookupPayload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got.Payload, tt.want) || got.Transaction != tt.wantTrx {
+				t.Errorf("Registry.LookupPayload() = %#v, want %#v trx %v", got, tt.want, tt.wantTrx)
+			}
+		})
+	}
+}
+
+func TestRegistry_LookupPayloadAmbiguous(t *testing.T) {
+	r := newLookupRegistry(t)
+	_, err := r.LookupPayload(ipod.NewLingoCmdID(0xa3, 0x01), []byte{0x01, 0x00, 0x02}, ipod.LookupContext{})
+	lerr, ok := err.(*ipod.LookupError)
+	if !ok {
+		t.Fatalf("Registry.LookupPayload() error = %v, want *LookupError", err)
+	}
+	want := []string{"ipod_test.LookupLong", "ipod_test.LookupOther"}
+	if !reflect.DeepEqual(lerr.Candidates, want) {
+		t.Errorf("LookupError.Candidates = %v, want %v", lerr.Candidates, want)
+	}
+}
+
+func TestCommand_UnmarshalBinaryACK(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    interface{}
+		wantTrx bool
+	}{
+		{"ack", []byte{0x00, 0x02, 0x00, 0x01, 0x00, 0x13}, &general.ACK{Status: 0x00, CmdID: 0x13}, true},
+		{"pending", []byte{0x00, 0x02, 0x00, 0x01, 0x06, 0x13, 0x00, 0x00, 0x03, 0xe8}, &general.ACKPending{Status: 0x06, CmdID: 0x13, MaxWait: 1000}, true},
+		{"data dropped", []byte{0x00, 0x02, 0x00, 0x43, 0x00, 0x01, 0x00, 0x00, 0x00, 0x10}, &general.ACKDataDropped{Status: 0x00, CmdID: 0x43, SessionID: 1, NumBytesDropped: 16}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ipod.Command
+			if err := got.UnmarshalBinary(tt.data); err != nil {
+				t.Fatalf("Command.UnmarshalBinary() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Payload, tt.want) || (got.Transaction != nil) != tt.wantTrx {
+				t.Errorf("Command.UnmarshalBinary() = %#v trx %v, want %#v", got.Payload, got.Transaction, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommand_UnmarshalBinaryVariants(t *testing.T) {
+	cat := func(parts ...[]byte) []byte {
+		var b []byte
+		for _, p := range parts {
+			b = append(b, p...)
+		}
+		return b
+	}
+	tests := []struct {
+		name    string
+		data    []byte
+		want    interface{}
+		wantTrx bool
+		wantErr bool
+	}{
+		{"auth v1", cat([]byte{0x00, 0x17}, make([]byte, 16), []byte{0x01}), &general.GetDevAuthenticationSignatureV1{Counter: 1}, false, false},
+		{"auth v2", cat([]byte{0x00, 0x17}, make([]byte, 20), []byte{0x01}), &general.GetDevAuthenticationSignatureV2{Counter: 1}, false, false},
+		{"auth v2 trx", cat([]byte{0x00, 0x17, 0x00, 0x01}, make([]byte, 20), []byte{0x01}), &general.GetDevAuthenticationSignatureV2{Counter: 1}, true, false},
+		// authentication 1.0 has no transactions
+		{"auth v1 trx", cat([]byte{0x00, 0x17, 0x00, 0x01}, make([]byte, 16), []byte{0x01}), nil, false, true},
+		{"play status short", []byte{0x04, 0x00, 0x26, 0x01}, &extremote.SetPlayStatusChangeNotificationShort{Enabled: true}, false, false},
+		{"play status short trx", []byte{0x04, 0x00, 0x26, 0x00, 0x05, 0x00}, &extremote.SetPlayStatusChangeNotificationShort{Enabled: false}, true, false},
+		{"play status short bad", []byte{0x04, 0x00, 0x26, 0x02}, nil, false, true},
+		{"play status mask", []byte{0x04, 0x00, 0x26, 0x00, 0x00, 0x00, 0x01}, &extremote.SetPlayStatusChangeNotification{EventMask: 1}, false, false},
+		{"play status mask trx", []byte{0x04, 0x00, 0x26, 0x00, 0x05, 0x00, 0x00, 0x00, 0x01}, &extremote.SetPlayStatusChangeNotification{EventMask: 1}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ipod.Command
+			err := got.UnmarshalBinary(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Command.UnmarshalBinary() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got.Payload, tt.want) || (got.Transaction != nil) != tt.wantTrx {
+				t.Errorf("Command.UnmarshalBinary() = %#v trx %v, want %#v", got.Payload, got.Transaction, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package ipod
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Middleware wraps a Handler i.e. to log or filter commands
+type Middleware func(Handler) Handler
+
+// Mux is a Handler that dispatches commands to the handlers
+// registered for their payload type.
+// Commands without a matching handler, i.e. UnknownPayload,
+// are passed to the default handler.
+type Mux struct {
+	handlers   map[reflect.Type]Handler
+	fallback   Handler
+	middleware []Middleware
+
+	// Registry holds the payload types the handlers are registered for,
+	// i.e. the LingoRegistry of the session the mux serves,
+	// DefaultRegistry if nil
+	Registry *Registry
+}
+
+// NewMux creates an empty Mux
+func NewMux() *Mux {
+	return &Mux{
+		handlers: make(map[reflect.Type]Handler),
+	}
+}
+
+func (m *Mux) mustLookupType(payload interface{}) reflect.Type {
+	r := m.Registry
+	if r == nil {
+		r = DefaultRegistry
+	}
+	if _, ok := r.LookupID(payload); !ok {
+		panic(fmt.Sprintf("ipod: mux: payload %T is not registered", payload))
+	}
+	return reflect.TypeOf(payload)
+}
+
+// Handle registers the handler for the type of payload.
+// payload must be a pointer to a payload type registered in m.Registry
+// i.e. &extremote.GetPlayStatus{}
+func (m *Mux) Handle(payload interface{}, h Handler) {
+	m.handlers[m.mustLookupType(payload)] = h
+}
+
+// HandleFunc registers the handler function for the type of payload
+func (m *Mux) HandleFunc(payload interface{}, f func(cmd *Command, w CommandWriter) error) {
+	m.Handle(payload, HandlerFunc(f))
+}
+
+var (
+	typeCommand       = reflect.TypeOf((*Command)(nil))
+	typeCommandWriter = reflect.TypeOf((*CommandWriter)(nil)).Elem()
+	typeError         = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// HandleTyped registers a typed handler function.
+// f must have the signature
+//
+//	func(cmd *ipod.Command, w ipod.CommandWriter, payload *T) error
+//
+// where *T is a registered payload type, i.e.
+//
+//	mux.HandleTyped(func(cmd *ipod.Command, w ipod.CommandWriter, p *extremote.GetPlayStatus) error {
+//		...
+//	})
+func (m *Mux) HandleTyped(f interface{}) {
+	fv := reflect.ValueOf(f)
+	ft := fv.Type()
+	if ft.Kind() != reflect.Func ||
+		ft.NumIn() != 3 || ft.In(0) != typeCommand || ft.In(1) != typeCommandWriter || ft.In(2).Kind() != reflect.Ptr ||
+		ft.NumOut() != 1 || ft.Out(0) != typeError {
+		panic(fmt.Sprintf("ipod: mux: bad typed handler signature: %v", ft))
+	}
+	pt := m.mustLookupType(reflect.Zero(ft.In(2)).Interface())
+	m.handlers[pt] = HandlerFunc(func(cmd *Command, w CommandWriter) error {
+		out := fv.Call([]reflect.Value{
+			reflect.ValueOf(cmd),
+			reflect.ValueOf(&w).Elem(),
+			reflect.ValueOf(cmd.Payload),
+		})
+		err, _ := out[0].Interface().(error)
+		return err
+	})
+}
+
+// HandleDefault registers the handler for commands
+// that don't have a handler of their own
+func (m *Mux) HandleDefault(h Handler) {
+	m.fallback = h
+}
+
+// Use appends middleware to the chain. Middleware is applied
+// to every handler including the default one, the first one being the outermost.
+func (m *Mux) Use(mw ...Middleware) {
+	m.middleware = append(m.middleware, mw...)
+}
+
+// Handler returns the handler for cmd, or nil if there is none
+func (m *Mux) Handler(cmd *Command) Handler {
+	if cmd.Payload != nil {
+		if h, ok := m.handlers[reflect.TypeOf(cmd.Payload)]; ok {
+			return h
+		}
+	}
+	return m.fallback
+}
+
+// HandleCommand dispatches cmd to the matching handler
+func (m *Mux) HandleCommand(cmd *Command, w CommandWriter) error {
+	h := m.Handler(cmd)
+	if h == nil {
+		h = HandlerFunc(func(*Command, CommandWriter) error { return nil })
+	}
+	for i := len(m.middleware) - 1; i >= 0; i-- {
+		h = m.middleware[i](h)
+	}
+	return h.HandleCommand(cmd, w)
+}
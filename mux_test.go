@@ -0,0 +1,106 @@
+package ipod_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/oandrew/ipod"
+)
+
+func TestMux(t *testing.T) {
+	var calls []string
+
+	m := ipod.NewMux()
+	m.HandleTyped(func(cmd *ipod.Command, w ipod.CommandWriter, p *SessionRequest) error {
+		calls = append(calls, "request")
+		ipod.Respond(cmd, w, &SessionResponse{V: p.V})
+		return nil
+	})
+	m.HandleFunc(&SessionResponse{}, func(cmd *ipod.Command, w ipod.CommandWriter) error {
+		calls = append(calls, "response")
+		return nil
+	})
+	m.HandleDefault(ipod.HandlerFunc(func(cmd *ipod.Command, w ipod.CommandWriter) error {
+		calls = append(calls, "default")
+		return nil
+	}))
+	m.Use(func(next ipod.Handler) ipod.Handler {
+		return ipod.HandlerFunc(func(cmd *ipod.Command, w ipod.CommandWriter) error {
+			calls = append(calls, "mw1")
+			return next.HandleCommand(cmd, w)
+		})
+	}, func(next ipod.Handler) ipod.Handler {
+		return ipod.HandlerFunc(func(cmd *ipod.Command, w ipod.CommandWriter) error {
+			calls = append(calls, "mw2")
+			return next.HandleCommand(cmd, w)
+		})
+	})
+
+	tests := []struct {
+		name      string
+		cmd       *ipod.Command
+		wantCalls []string
+		wantOut   int
+	}{
+		{"typed", &ipod.Command{Payload: &SessionRequest{V: 1}}, []string{"mw1", "mw2", "request"}, 1},
+		{"func", &ipod.Command{Payload: &SessionResponse{V: 1}}, []string{"mw1", "mw2", "response"}, 0},
+		{"unknown", &ipod.Command{Payload: ipod.UnknownPayload{0x01}}, []string{"mw1", "mw2", "default"}, 0},
+		{"nil", &ipod.Command{}, []string{"mw1", "mw2", "default"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = nil
+			out := ipod.CmdBuffer{}
+			if err := m.HandleCommand(tt.cmd, &out); err != nil {
+				t.Fatalf("Mux.HandleCommand() error = %v", err)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("Mux.HandleCommand() calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if len(out.Commands) != tt.wantOut {
+				t.Errorf("Mux.HandleCommand() wrote %d commands, want %d", len(out.Commands), tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestMux_HandleUnregistered(t *testing.T) {
+	type unregistered struct{}
+	tests := []struct {
+		name string
+		f    func(m *ipod.Mux)
+	}{
+		{"handle", func(m *ipod.Mux) { m.HandleFunc(&unregistered{}, nil) }},
+		{"typed", func(m *ipod.Mux) {
+			m.HandleTyped(func(*ipod.Command, ipod.CommandWriter, *unregistered) error { return nil })
+		}},
+		{"typed-bad-signature", func(m *ipod.Mux) {
+			m.HandleTyped(func(*SessionRequest) error { return nil })
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected panic")
+				}
+			}()
+			tt.f(ipod.NewMux())
+		})
+	}
+}
+
+func TestMux_Registry(t *testing.T) {
+	s := ipod.NewSession(&testFrameReadWriter{})
+	s.Registry = newTestRegistry(t)
+	m := ipod.NewMux()
+	m.Registry = s.LingoRegistry()
+	m.HandleFunc(&RegistryOther{}, func(cmd *ipod.Command, w ipod.CommandWriter) error { return nil })
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for a payload outside of the session registry")
+		}
+	}()
+	m.HandleFunc(&SessionRequest{}, func(cmd *ipod.Command, w ipod.CommandWriter) error { return nil })
+}
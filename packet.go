@@ -2,15 +2,16 @@
 package ipod
 
 import (
-	"bufio"
+	"bytes"
 	"encoding/binary"
-	"errors"
 	"fmt"
 	"io"
 )
 
 const (
 	PacketStartByte byte = 0x55
+	// PacketSyncByte may precede the start byte on serial links
+	PacketSyncByte byte = 0xFF
 )
 const (
 	rawSmallPacketMinLen = 1 + 1 + 2 // start + len + ids
@@ -19,79 +20,235 @@ const (
 	minPacketBufSize     = 1024
 )
 
+// PacketStats are the link counters of a PacketReader
+type PacketStats struct {
+	// Packets is the number of valid packets read
+	Packets uint64
+	// CRCErrors is the number of packets dropped because of a crc mismatch
+	CRCErrors uint64
+	// Truncated is the number of packets dropped because the data ended
+	Truncated uint64
+	// Resyncs is the number of times the reader lost sync
+	// and scanned for the next start byte
+	Resyncs uint64
+	// BytesSkipped is the number of bytes skipped while scanning,
+	// zero padding is not counted
+	BytesSkipped uint64
+}
+
+// Add adds the counters of o to s
+func (s *PacketStats) Add(o PacketStats) {
+	s.Packets += o.Packets
+	s.CRCErrors += o.CRCErrors
+	s.Truncated += o.Truncated
+	s.Resyncs += o.Resyncs
+	s.BytesSkipped += o.BytesSkipped
+}
+
+// PacketReader reads packets from a byte stream. Packets can span reads.
+// After garbage, a crc mismatch or a truncated packet the reader
+// resyncs on the next start byte, so ReadPacket can be called again
+// after an error other than io.EOF.
+//
+// The reader owns its buffer, the payloads returned by ReadPacket
+// point into it.
 type PacketReader struct {
-	r *bufio.Reader
-	//r io.Reader
+	r     io.Reader
+	buf   []byte
+	start int
+	err   error
+
+	truncated bool
+	synced    bool
+	stats     PacketStats
 }
 
 func NewPacketReader(r io.Reader) *PacketReader {
 	return &PacketReader{
-		r: bufio.NewReaderSize(r, 512),
-		//r: r,
+		r:      r,
+		buf:    make([]byte, 0, 512),
+		synced: true,
 	}
 }
 
-func (pd *PacketReader) ReadPacket() ([]byte, error) {
-	for {
-		next, err := pd.r.ReadByte()
-		if err != nil {
-			return nil, err
-		}
-		if next == PacketStartByte {
-			pd.r.UnreadByte()
-			break
-		}
+// Reset makes the reader read from r like a new PacketReader
+// while keeping its buffer, the payloads read so far become invalid
+func (pd *PacketReader) Reset(r io.Reader) {
+	pd.r = r
+	pd.buf = pd.buf[:0]
+	pd.start = 0
+	pd.err = nil
+	pd.truncated = false
+	pd.synced = true
+	pd.stats = PacketStats{}
+}
+
+// Stats returns the counters of the reader
+func (pd *PacketReader) Stats() PacketStats {
+	return pd.stats
+}
+
+// fill reads more data into the buffer
+func (pd *PacketReader) fill() error {
+	if pd.err != nil {
+		return pd.err
 	}
-	var header [4]byte
-	if _, err := pd.r.Read(header[:2]); err != nil {
-		return nil, err
+	if pd.start > 0 {
+		n := copy(pd.buf, pd.buf[pd.start:])
+		pd.buf = pd.buf[:n]
+		pd.start = 0
 	}
-
-	if header[0] == 0x00 && header[1] == 0x00 {
-		return nil, io.EOF
+	if len(pd.buf) == cap(pd.buf) {
+		buf := make([]byte, len(pd.buf), 2*cap(pd.buf))
+		copy(buf, pd.buf)
+		pd.buf = buf
 	}
-
-	if header[0] == 0xff && header[1] == PacketStartByte {
-		header[0] = header[1]
-		if _, err := pd.r.Read(header[1:2]); err != nil {
-			return nil, err
+	n, err := pd.r.Read(pd.buf[len(pd.buf):cap(pd.buf)])
+	pd.buf = pd.buf[:len(pd.buf)+n]
+	if err != nil {
+		pd.err = err
+		if n > 0 {
+			return nil
 		}
 	}
+	return err
+}
 
-	if header[0] != PacketStartByte {
-		return nil, errors.New("packet decode: start byte not found")
+// skip drops n bytes while scanning for the start byte,
+// a sync byte right before the start byte is not garbage
+func (pd *PacketReader) skip(n int) {
+	skipped := pd.buf[pd.start : pd.start+n]
+	if n > 0 && skipped[n-1] == PacketSyncByte &&
+		pd.start+n < len(pd.buf) && pd.buf[pd.start+n] == PacketStartByte {
+		skipped = skipped[:n-1]
 	}
+	for _, b := range skipped {
+		if b != 0x00 {
+			pd.stats.BytesSkipped++
+			if pd.synced {
+				pd.synced = false
+				pd.stats.Resyncs++
+			}
+		}
+	}
+	pd.start += n
+}
+
+// resync drops the start byte of a bad packet
+func (pd *PacketReader) resync() {
+	pd.start++
+	pd.synced = false
+	pd.stats.Resyncs++
+}
 
-	var payLen int
-	if header[1] == 0x00 {
-		if _, err := pd.r.Read(header[2:4]); err != nil {
-			return nil, err
+// ReadPacket returns the payload of the next packet.
+// The payload is valid until the next call to ReadPacket or Reset,
+// copy it to keep it.
+// It returns io.EOF at the end of the data, or io.ErrUnexpectedEOF
+// once if a truncated packet was dropped before it.
+func (pd *PacketReader) ReadPacket() ([]byte, error) {
+	for {
+		data := pd.buf[pd.start:]
+		i := bytes.IndexByte(data, PacketStartByte)
+		if i < 0 {
+			// a trailing sync byte is judged with the next read
+			n := len(data)
+			if n > 0 && data[n-1] == PacketSyncByte && pd.err == nil {
+				n--
+			}
+			pd.skip(n)
+			if err := pd.fill(); err != nil {
+				if err == io.EOF && pd.truncated {
+					pd.truncated = false
+					return nil, io.ErrUnexpectedEOF
+				}
+				return nil, err
+			}
+			continue
 		}
-		payLen = int(binary.BigEndian.Uint16(header[2:4]))
-	} else {
-		payLen = int(header[1])
+		pd.skip(i)
+		data = data[i:]
+
+		hdrLen, payLen, ok := packetHeader(data)
+		if !ok || len(data) < hdrLen+payLen+1 {
+			if err := pd.fill(); err != nil {
+				if err != io.EOF {
+					return nil, err
+				}
+				pd.stats.Truncated++
+				pd.truncated = true
+				pd.resync()
+			}
+			continue
+		}
+		if payLen == 0 {
+			// a large packet header with a zero length is no packet
+			pd.resync()
+			continue
+		}
+
+		payload := data[hdrLen : hdrLen+payLen]
+		crc := data[hdrLen+payLen]
+		var crc8 crc8
+		crc8.Write(data[1:hdrLen])
+		crc8.Write(payload)
+		calcCrc := crc8.Sum8()
+		if crc != calcCrc {
+			pd.stats.CRCErrors++
+			pd.resync()
+			return nil, fmt.Errorf("packet decode: crc mismatch: recv %02x != calc %02x", crc, calcCrc)
+		}
+
+		pd.start += hdrLen + payLen + 1
+		pd.synced = true
+		pd.stats.Packets++
+		return payload, nil
 	}
+}
 
-	payloadWithCrc := make([]byte, payLen+1)
-	if n, err := pd.r.Read(payloadWithCrc); n != len(payloadWithCrc) || err != nil {
-		return nil, errors.New("packet decode: short read")
+// packetHeader parses the header at the start of data
+func packetHeader(data []byte) (hdrLen, payLen int, ok bool) {
+	if len(data) < 2 {
+		return 0, 0, false
 	}
+	if data[1] != 0x00 {
+		return 2, int(data[1]), true
+	}
+	if len(data) < 4 {
+		return 0, 0, false
+	}
+	return 4, int(binary.BigEndian.Uint16(data[2:4])), true
+}
 
-	payload := payloadWithCrc[:payLen]
-	crc := payloadWithCrc[payLen]
-	crc8 := NewCRC8()
-	crc8.Write(header[1:])
-	crc8.Write(payload)
-	calcCrc := crc8.Sum8()
-	if crc != calcCrc {
-		return nil, fmt.Errorf("packet decode: crc mismatch: recv %02x != calc %02x", crc, calcCrc)
+// packetSize returns the encoded size of a packet with the payload size n
+func packetSize(n int) int {
+	if n >= largePacketMinLen {
+		return 1 + 3 + n + 1
 	}
-	return payload, nil
+	return 1 + 1 + n + 1
+}
 
+// appendPacket appends the packet with the payload pkt to dst
+func appendPacket(dst, pkt []byte) []byte {
+	dst = append(dst, PacketStartByte)
+	hdr := len(dst)
+	if len(pkt) >= largePacketMinLen {
+		dst = append(dst, 0x00, byte(len(pkt)>>8), byte(len(pkt)))
+	} else {
+		dst = append(dst, byte(len(pkt)))
+	}
+	dst = append(dst, pkt...)
+	var crc crc8
+	crc.Write(dst[hdr:])
+	return append(dst, crc.Sum8())
 }
 
+// PacketWriter writes packets to a byte stream,
+// every packet is written with a single Write.
+// The writer must not retain the data passed to Write.
 type PacketWriter struct {
-	w io.Writer
+	w   io.Writer
+	buf []byte
 }
 
 func NewPacketWriter(w io.Writer) *PacketWriter {
@@ -100,23 +257,16 @@ func NewPacketWriter(w io.Writer) *PacketWriter {
 	}
 }
 
+// WritePacket writes the packet with the payload pkt,
+// pkt is not retained
 func (pw *PacketWriter) WritePacket(pkt []byte) error {
 	if len(pkt) == 0 {
 		return fmt.Errorf("packet encode: empty packet")
 	}
-	binary.Write(pw.w, binary.BigEndian, PacketStartByte)
-
-	crc := NewCRC8()
-	mw := io.MultiWriter(pw.w, crc)
-	if len(pkt) > largePacketMinLen {
-		binary.Write(mw, binary.BigEndian, byte(0x00))
-		binary.Write(mw, binary.BigEndian, uint16(len(pkt)))
-	} else {
-		binary.Write(mw, binary.BigEndian, byte(len(pkt)))
+	if len(pkt) > 0xffff {
+		return fmt.Errorf("packet encode: packet too large: %d", len(pkt))
 	}
-	mw.Write(pkt)
-
-	pw.w.Write([]byte{crc.Sum8()})
-
-	return nil
+	pw.buf = appendPacket(pw.buf[:0], pkt)
+	_, err := pw.w.Write(pw.buf)
+	return err
 }
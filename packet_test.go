@@ -2,6 +2,7 @@ package ipod_test
 
 import (
 	"bytes"
+	"io"
 	"reflect"
 	"testing"
 
@@ -21,6 +22,8 @@ func TestPacketWriter_WritePacket(t *testing.T) {
 		{"no-data", []byte{}, nil, true},
 		{"with-data", []byte{0x01, 0x02, 0xfd}, []byte{0x55, 0x03, 0x01, 0x02, 0xfd, 0xfd}, false},
 		{"large-with-data", append([]byte{0x1, 0x02}, largeData...), append([]byte{0x55, 0x00, 0x01, 0x01, 0x1, 0x02}, append(largeData, 0xe9)...), false},
+		{"large-boundary", append([]byte{0x1}, largeData...), append([]byte{0x55, 0x00, 0x01, 0x00, 0x1}, append(largeData, 0xec)...), false},
+		{"too-large", make([]byte, 0x10000), nil, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -39,25 +42,26 @@ func TestPacketWriter_WritePacket(t *testing.T) {
 	}
 }
 
-func TestPacketReader_ReadPacket(t *testing.T) {
-	largeData := bytes.Repeat([]byte{0xee}, 255)
+var largeData = bytes.Repeat([]byte{0xee}, 255)
 
-	tests := []struct {
-		name    string
-		data    []byte
-		want    []byte
-		wantErr bool
-	}{
-		{"no-data", []byte{0x55, 0x02, 0x01, 0x02, 256 - 0x05}, []byte{0x01, 0x02}, false},
-		{"with-data", []byte{0x55, 0x03, 0x01, 0x02, 0xfd, 0xfd}, []byte{0x01, 0x02, 0xfd}, false},
-		{"bad-crc", []byte{0x55, 0x03, 0x01, 0x02, 0xfd, 0x22}, nil, true},
-		{"wrong-start-byte", []byte{0xff, 0x03, 0x01, 0x02, 0xfd, 0xfd}, nil, true},
+var packetReaderTests = []struct {
+	name    string
+	data    []byte
+	want    []byte
+	wantErr bool
+}{
+	{"no-data", []byte{0x55, 0x02, 0x01, 0x02, 256 - 0x05}, []byte{0x01, 0x02}, false},
+	{"with-data", []byte{0x55, 0x03, 0x01, 0x02, 0xfd, 0xfd}, []byte{0x01, 0x02, 0xfd}, false},
+	{"bad-crc", []byte{0x55, 0x03, 0x01, 0x02, 0xfd, 0x22}, nil, true},
+	{"wrong-start-byte", []byte{0xff, 0x03, 0x01, 0x02, 0xfd, 0xfd}, nil, true},
 
-		{"large-with-data", append([]byte{0x55, 0x00, 0x01, 0x01, 0x1, 0x02}, append(largeData, 0xe9)...), append([]byte{0x1, 0x02}, largeData...), false},
-		{"large-with-data-short", append([]byte{0x55, 0x00, 0x01, 0x02, 0x1, 0x02}, append(largeData, 0xe9)...), nil, true},
-		{"large-bad-crc", append([]byte{0x55, 0x00, 0x01, 0x01, 0x1, 0x02}, append(largeData, 0x22)...), nil, true},
-	}
-	for _, tt := range tests {
+	{"large-with-data", append([]byte{0x55, 0x00, 0x01, 0x01, 0x1, 0x02}, append(largeData, 0xe9)...), append([]byte{0x1, 0x02}, largeData...), false},
+	{"large-with-data-short", append([]byte{0x55, 0x00, 0x01, 0x02, 0x1, 0x02}, append(largeData, 0xe9)...), nil, true},
+	{"large-bad-crc", append([]byte{0x55, 0x00, 0x01, 0x01, 0x1, 0x02}, append(largeData, 0x22)...), nil, true},
+}
+
+func TestPacketReader_ReadPacket(t *testing.T) {
+	for _, tt := range packetReaderTests {
 		t.Run(tt.name, func(t *testing.T) {
 			r := ipod.NewPacketReader(bytes.NewReader(tt.data))
 			got, err := r.ReadPacket()
@@ -72,6 +76,86 @@ func TestPacketReader_ReadPacket(t *testing.T) {
 	}
 }
 
+// oneByteReader returns the data a byte per Read
+type oneByteReader struct {
+	data []byte
+}
+
+func (r *oneByteReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	p[0] = r.data[0]
+	r.data = r.data[1:]
+	return 1, nil
+}
+
+func TestPacketReader_Resync(t *testing.T) {
+	good := []byte{0x55, 0x03, 0x01, 0x02, 0xfd, 0xfd}
+	badCrc := []byte{0x55, 0x03, 0x01, 0x02, 0xfd, 0x22}
+	large := func() []byte {
+		buf := bytes.Buffer{}
+		ipod.NewPacketWriter(&buf).WritePacket(bytes.Repeat([]byte{0xee}, 300))
+		return buf.Bytes()
+	}()
+	join := func(parts ...[]byte) []byte {
+		return bytes.Join(parts, nil)
+	}
+
+	tests := []struct {
+		name      string
+		data      []byte
+		want      int
+		wantErrs  int
+		wantStats ipod.PacketStats
+	}{
+		{"padding", join(good, good, []byte{0x00, 0x00, 0x00}), 2, 0, ipod.PacketStats{Packets: 2}},
+		{"garbage", join([]byte{0x01, 0x02}, good, []byte{0x03}, good), 2, 0, ipod.PacketStats{Packets: 2, Resyncs: 2, BytesSkipped: 3}},
+		{"bad-crc", join(badCrc, good), 1, 1, ipod.PacketStats{Packets: 1, CRCErrors: 1, Resyncs: 1, BytesSkipped: 5}},
+		// the length of the false start covers the real packet
+		{"false-start", join([]byte{0x55, 0x20}, good), 1, 1, ipod.PacketStats{Packets: 1, Truncated: 1, Resyncs: 1, BytesSkipped: 1}},
+		{"truncated", join(good, good[:4]), 1, 1, ipod.PacketStats{Packets: 1, Truncated: 1, Resyncs: 1, BytesSkipped: 3}},
+		{"empty-large", join([]byte{0x55, 0x00, 0x00, 0x00, 0x00}, good), 1, 0, ipod.PacketStats{Packets: 1, Resyncs: 1}},
+		{"large", join(large, good, large), 3, 0, ipod.PacketStats{Packets: 3}},
+		{"sync", join([]byte{0xff}, good, []byte{0xff}, good), 2, 0, ipod.PacketStats{Packets: 2}},
+		{"sync-garbage", join([]byte{0x01, 0xff}, good, []byte{0xff, 0xff}, good), 2, 0, ipod.PacketStats{Packets: 2, Resyncs: 2, BytesSkipped: 2}},
+	}
+	for _, tt := range tests {
+		for _, oneByte := range []bool{false, true} {
+			name := tt.name
+			var r io.Reader = bytes.NewReader(tt.data)
+			if oneByte {
+				name += "-one-byte"
+				r = &oneByteReader{tt.data}
+			}
+			t.Run(name, func(t *testing.T) {
+				pr := ipod.NewPacketReader(r)
+				var got, gotErrs int
+				for i := 0; i < 100; i++ {
+					_, err := pr.ReadPacket()
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						gotErrs++
+						continue
+					}
+					got++
+				}
+				if got != tt.want || gotErrs != tt.wantErrs {
+					t.Errorf("PacketReader.ReadPacket() packets = %d errors = %d, want %d %d", got, gotErrs, tt.want, tt.wantErrs)
+				}
+				if stats := pr.Stats(); stats != tt.wantStats {
+					t.Errorf("PacketReader.Stats() = %+v, want %+v", stats, tt.wantStats)
+				}
+			})
+		}
+	}
+}
+
 func BenchmarkPacketReader(b *testing.B) {
 	frame := []byte{
 		0x55, 0x28, 0x0a, 0x03, 0x03, 0xe7, 0x00, 0x00,
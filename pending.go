@@ -0,0 +1,96 @@
+package ipod
+
+import (
+	"context"
+	"time"
+)
+
+// PendingACKFunc builds the pending ACK payload announcing maxWait
+type PendingACKFunc func(maxWait time.Duration) interface{}
+
+// PendingFunc does the work of a request answered with a pending ACK.
+// It returns the payload of the final response, nil for a success ACK,
+// or an error for a failure ACK: the status of an *ACKError
+// or ACKStatusFailed.
+type PendingFunc func(ctx context.Context) (interface{}, error)
+
+// Pending answers req with the pending ACK built by ack, runs f
+// asynchronously and answers req with the result of f
+// using the original transaction.
+// While f runs the pending ACK is sent again when 3/4 of maxWait
+// have passed since the last one, so that the accessory keeps waiting.
+//
+// If w is a RequestWriter the request stays in flight until f returns
+// and the context of f is the context of the request,
+// so cancelling the request stops the pending ACKs and drops the final response.
+func Pending(req *Command, w CommandWriter, maxWait time.Duration, ack PendingACKFunc, f PendingFunc) {
+	Respond(req, w, ack(maxWait))
+	run := func(ctx context.Context, w CommandWriter) error {
+		return runPending(ctx, req, w, maxWait, ack, f)
+	}
+	if rw, ok := w.(*RequestWriter); ok {
+		rw.Go(run)
+		return
+	}
+	go run(context.Background(), w)
+}
+
+type pendingResult struct {
+	payload interface{}
+	err     error
+}
+
+func runPending(ctx context.Context, req *Command, w CommandWriter, maxWait time.Duration, ack PendingACKFunc, f PendingFunc) error {
+	done := make(chan pendingResult, 1)
+	go func() {
+		payload, err := f(ctx)
+		done <- pendingResult{payload, err}
+	}()
+
+	// no refresh without a max wait
+	refresh := maxWait - maxWait/4
+	var tick <-chan time.Time
+	if refresh > 0 {
+		ticker := time.NewTicker(refresh)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	cancelled := ctx.Done()
+	for {
+		select {
+		case <-tick:
+			Respond(req, w, ack(maxWait))
+		case <-cancelled:
+			// nothing more is written, the request is over once f returns
+			tick, cancelled = nil, nil
+		case r := <-done:
+			if ctx.Err() != nil {
+				return nil
+			}
+			return completePending(req, w, r.payload, r.err)
+		}
+	}
+}
+
+// completePending writes the final response of a pending request
+func completePending(req *Command, w CommandWriter, payload interface{}, err error) error {
+	if err != nil {
+		status := ACKStatusFailed
+		if ackErr, ok := err.(*ACKError); ok {
+			status = ackErr.Status
+		}
+		if ack, ok := writerRegistry(w).ACK(req, status); ok {
+			Respond(req, w, ack)
+		}
+		return err
+	}
+	if payload == nil {
+		ack, ok := writerRegistry(w).ACK(req, ACKStatusSuccess)
+		if !ok {
+			return nil
+		}
+		payload = ack
+	}
+	Respond(req, w, payload)
+	return nil
+}
@@ -0,0 +1,112 @@
+package ipod_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/oandrew/ipod"
+)
+
+func TestPending(t *testing.T) {
+	sessionACK := func(status ipod.ACKStatus, maxWait uint32) *ipod.Command {
+		return &ipod.Command{
+			ID:          ipod.NewLingoCmdID(testSessionLingoID, 0x03),
+			Transaction: ipod.NewTransaction(1),
+			Payload:     &SessionACK{Status: uint8(status), CmdID: 0x01, MaxWait: maxWait},
+		}
+	}
+	pending := sessionACK(ipod.ACKStatusPending, 40)
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name    string
+		payload interface{}
+		err     error
+		cancel  bool
+		want    []*ipod.Command
+	}{
+		{"response", &SessionResponse{V: 2}, nil, false, []*ipod.Command{pending, pending, pending, sessionResp(1)}},
+		{"success", nil, nil, false, []*ipod.Command{pending, pending, pending, sessionACK(ipod.ACKStatusSuccess, 0)}},
+		{"ack-error", nil, &ipod.ACKError{Status: ipod.ACKStatusBadParameter}, false, []*ipod.Command{pending, pending, pending, sessionACK(ipod.ACKStatusBadParameter, 0)}},
+		{"error", nil, errFailed, false, []*ipod.Command{pending, pending, pending, sessionACK(ipod.ACKStatusFailed, 0)}},
+		{"cancelled", &SessionResponse{V: 2}, nil, true, []*ipod.Command{pending, pending, pending}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the transport ends once the request is complete,
+			// the session cancels the requests in flight at the end
+			frw := &testFrameReadWriter{
+				in:  [][]byte{buildFrame(t, sessionReq(1))},
+				eof: make(chan struct{}),
+			}
+			var once sync.Once
+			complete := func() {
+				once.Do(func() { close(frw.eof) })
+			}
+			s := ipod.NewSession(frw)
+
+			// the pending ACKs written so far
+			pendingACKs := make(chan struct{}, 8)
+			s.Hooks.Command = func(dir ipod.Dir, cmd *ipod.Command, err error) {
+				if dir != ipod.DirOut {
+					return
+				}
+				if ack, ok := cmd.Payload.(*SessionACK); ok && ack.Status == uint8(ipod.ACKStatusPending) {
+					pendingACKs <- struct{}{}
+					return
+				}
+				complete()
+			}
+			var handlerErr error
+			s.Hooks.HandlerError = func(cmd *ipod.Command, err error) {
+				handlerErr = err
+			}
+			var acks int32
+			s.HandleFunc(testSessionLingoID, func(cmd *ipod.Command, w ipod.CommandWriter) error {
+				pendingACK := func(maxWait time.Duration) interface{} {
+					atomic.AddInt32(&acks, 1)
+					return &SessionACK{Status: uint8(ipod.ACKStatusPending), CmdID: 0x01, MaxWait: uint32(maxWait / time.Millisecond)}
+				}
+				ipod.Pending(cmd, w, 40*time.Millisecond, pendingACK, func(ctx context.Context) (interface{}, error) {
+					// wait for the first ACK and two refreshes
+					for i := 0; i < 3; i++ {
+						<-pendingACKs
+					}
+					if tt.cancel {
+						s.CancelCommand(cmd.ID, *cmd.Transaction)
+						<-ctx.Done()
+						// a refresh may race the cancellation
+						time.Sleep(10 * time.Millisecond)
+						n := atomic.LoadInt32(&acks)
+						time.Sleep(100 * time.Millisecond)
+						if got := atomic.LoadInt32(&acks); got != n {
+							t.Errorf("Pending() refreshed %d times after cancel", got-n)
+						}
+						complete()
+					}
+					return tt.payload, tt.err
+				})
+				return nil
+			})
+
+			if err := s.Serve(); err != nil {
+				t.Fatalf("Session.Serve() error = %v", err)
+			}
+			if handlerErr != tt.err {
+				t.Errorf("Pending() error = %v, want %v", handlerErr, tt.err)
+			}
+			var want [][]byte
+			for _, cmd := range tt.want {
+				want = append(want, buildFrame(t, cmd))
+			}
+			if !reflect.DeepEqual(frw.out, want) {
+				t.Errorf("Pending() frames = %x, want %x", frw.out, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package ipod_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/oandrew/ipod"
+	"github.com/oandrew/ipod/hid"
+	"github.com/oandrew/ipod/lingo-extremote"
+)
+
+func pipelineCommand() *ipod.Command {
+	return &ipod.Command{
+		ID:          ipod.NewLingoCmdID(extremote.LingoExtRemotelID, 0x001D),
+		Transaction: ipod.NewTransaction(0x03e7),
+		Payload: &extremote.ReturnPlayStatus{
+			TrackLength:   180000,
+			TrackPosition: 1000,
+			State:         extremote.PlayerStatePlaying,
+		},
+	}
+}
+
+// pipelineReport returns the single report carrying the command
+func pipelineReport(t testing.TB, cmd *ipod.Command) hid.SingleReport {
+	pkt, err := cmd.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var frame bytes.Buffer
+	if err := ipod.NewPacketWriter(&frame).WritePacket(pkt); err != nil {
+		t.Fatal(err)
+	}
+	def, err := hid.DefaultReportDefs.Pick(frame.Len(), hid.ReportDirAccOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(hid.SingleReport{byte(def.ID), byte(hid.LinkControlDone)}, frame.Bytes()...)
+}
+
+// frameToCommand decodes the commands of the reports
+// into a reused Command like the serve loop
+type frameToCommand struct {
+	dec   *hid.Decoder
+	frame bytes.Reader
+	pr    *ipod.PacketReader
+	cmd   ipod.Command
+}
+
+func newFrameToCommand(r hid.ReportReader) *frameToCommand {
+	p := &frameToCommand{dec: hid.NewDecoderDefault(r)}
+	p.pr = ipod.NewPacketReader(&p.frame)
+	return p
+}
+
+func (p *frameToCommand) read() error {
+	frame, err := p.dec.ReadFrame()
+	if err != nil {
+		return err
+	}
+	p.frame.Reset(frame)
+	p.pr.Reset(&p.frame)
+	pkt, err := p.pr.ReadPacket()
+	if err != nil {
+		return err
+	}
+	return ipod.DefaultRegistry.UnmarshalCommand(&p.cmd, pkt, nil)
+}
+
+// commandToFrame encodes commands into reports
+// through reused buffers like the session
+type commandToFrame struct {
+	pkt []byte
+	fb  *ipod.FrameBuilder
+}
+
+func newCommandToFrame(w hid.ReportWriter, strategy hid.ReportStrategy) *commandToFrame {
+	enc := hid.NewEncoderDefault(w)
+	enc.Strategy = strategy
+	return &commandToFrame{fb: ipod.NewFrameBuilder(enc, enc.MaxFrameSize())}
+}
+
+func (p *commandToFrame) write(cmd *ipod.Command) error {
+	pkt, err := cmd.AppendBinary(p.pkt[:0])
+	if err != nil {
+		return err
+	}
+	p.pkt = pkt
+	if err := p.fb.WritePacket(pkt); err != nil {
+		return err
+	}
+	return p.fb.Flush()
+}
+
+func TestPipeline(t *testing.T) {
+	want := pipelineCommand()
+	p := newFrameToCommand(pipelineReport(t, want))
+	for i := 0; i < 2; i++ {
+		if err := p.read(); err != nil {
+			t.Fatalf("read() error = %v", err)
+		}
+		if !reflect.DeepEqual(&p.cmd, want) {
+			t.Errorf("read() = %v, want %v", &p.cmd, want)
+		}
+	}
+
+	var out bytes.Buffer
+	w := newCommandToFrame(hid.NewReportWriter(&out), nil)
+	if err := w.write(want); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+	report := pipelineReport(t, want)
+	report[0] = out.Bytes()[0]
+	if !bytes.HasPrefix(out.Bytes(), report) {
+		t.Errorf("write() = %x, want %x", out.Bytes(), report)
+	}
+}
+
+func TestPipeline_Allocs(t *testing.T) {
+	cmd := pipelineCommand()
+	r := newFrameToCommand(pipelineReport(t, cmd))
+	if n := testing.AllocsPerRun(100, func() {
+		if err := r.read(); err != nil {
+			t.Fatal(err)
+		}
+	}); n != 0 {
+		t.Errorf("frame to command: %v allocs, want 0", n)
+	}
+
+	strategies := []struct {
+		name     string
+		strategy hid.ReportStrategy
+	}{
+		{"greedy", nil},
+		{"memo-min-bytes", hid.Memoize(hid.PickMinBytes)},
+		{"memo-min-reports", hid.Memoize(hid.PickMinReports)},
+	}
+	for _, s := range strategies {
+		w := newCommandToFrame(hid.NewReportWriter(ioutil.Discard), s.strategy)
+		if n := testing.AllocsPerRun(100, func() {
+			if err := w.write(cmd); err != nil {
+				t.Fatal(err)
+			}
+		}); n != 0 {
+			t.Errorf("command to frame with %s: %v allocs, want 0", s.name, n)
+		}
+	}
+}
+
+func BenchmarkPipeline_FrameToCommand(b *testing.B) {
+	p := newFrameToCommand(pipelineReport(b, pipelineCommand()))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := p.read(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPipeline_CommandToFrame(b *testing.B) {
+	cmd := pipelineCommand()
+	p := newCommandToFrame(hid.NewReportWriter(ioutil.Discard), nil)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := p.write(cmd); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// repeatFrameReadWriter returns the same frame n times
+type repeatFrameReadWriter struct {
+	frame []byte
+	n     int
+}
+
+func (rw *repeatFrameReadWriter) ReadFrame() ([]byte, error) {
+	if rw.n == 0 {
+		return nil, io.EOF
+	}
+	rw.n--
+	return rw.frame, nil
+}
+
+func (rw *repeatFrameReadWriter) WriteFrame([]byte) error {
+	return nil
+}
+
+// BenchmarkSession_Serve measures the serve loop answering a command per frame.
+// The allocations left are owned by the handler: the command and its payload,
+// the RequestWriter with its context and the response.
+func BenchmarkSession_Serve(b *testing.B) {
+	req := pipelineCommand()
+	// a repeated transaction would be reported as a duplicate
+	req.Transaction = nil
+	frame := []byte(pipelineReport(b, req)[2:])
+	resp := &extremote.ReturnPlayStatus{}
+
+	frw := &repeatFrameReadWriter{frame: frame, n: b.N}
+	s := ipod.NewSession(frw)
+	s.HandleFunc(extremote.LingoExtRemotelID, func(cmd *ipod.Command, w ipod.CommandWriter) error {
+		ipod.Respond(cmd, w, resp)
+		return nil
+	})
+	b.ReportAllocs()
+	b.ResetTimer()
+	if err := s.Serve(); err != nil {
+		b.Fatal(err)
+	}
+}
@@ -0,0 +1,35 @@
+package ipod
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPooledBuffer is the capacity above which a buffer is not put back,
+// so that a rare large payload doesn't stay in the pool
+const maxPooledBuffer = 64 << 10
+
+// bufferPool holds the scratch buffers of the payloads that are encoded
+// through an io.Writer, i.e. with binary.Write or the ipod struct tags
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// getBuffer takes an empty buffer from the pool,
+// the caller owns it until putBuffer
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// putBuffer returns the buffer to the pool,
+// neither it nor its Bytes may be used afterwards
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	bufferPool.Put(buf)
+}
@@ -0,0 +1,88 @@
+package ipod
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrQueueClosed is returned by QueueWriter.WriteCommand after Close
+var ErrQueueClosed = errors.New("ipod: queue closed")
+
+// DefaultQueueSize is the number of commands a Session's queue holds
+// before WriteCommand blocks
+const DefaultQueueSize = 16
+
+// QueueWriter is a CommandWriter that is safe to use from any goroutine,
+// i.e. to send notifications when the player state changes.
+// The commands are queued and written to the underlying writer
+// in order by a goroutine of its own.
+type QueueWriter struct {
+	w     CommandWriter
+	queue chan *Command
+	done  chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+
+	// OnError is called by the writer goroutine when a write fails.
+	// It must be set before the first WriteCommand.
+	OnError func(cmd *Command, err error)
+}
+
+// NewQueueWriter starts a QueueWriter that writes to w
+// and holds up to size commands
+func NewQueueWriter(w CommandWriter, size int) *QueueWriter {
+	q := &QueueWriter{
+		w:     w,
+		queue: make(chan *Command, size),
+		done:  make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+func (q *QueueWriter) run() {
+	defer close(q.done)
+	for cmd := range q.queue {
+		if err := q.w.WriteCommand(cmd); err != nil && q.OnError != nil {
+			q.OnError(cmd, err)
+		}
+	}
+}
+
+// WriteCommand queues the command, it blocks while the queue is full.
+// The write errors are passed to OnError.
+func (q *QueueWriter) WriteCommand(cmd *Command) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	q.queue <- cmd
+	return nil
+}
+
+// Close writes the queued commands and stops the writer goroutine
+func (q *QueueWriter) Close() error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return ErrQueueClosed
+	}
+	q.closed = true
+	close(q.queue)
+	q.mu.Unlock()
+	<-q.done
+	return nil
+}
+
+// Transactions returns the transactions of the underlying writer,
+// so that Send numbers the queued commands on its link
+func (q *QueueWriter) Transactions() *Transactions {
+	return writerTransactions(q.w)
+}
+
+// LingoRegistry returns the registry of the underlying writer
+func (q *QueueWriter) LingoRegistry() *Registry {
+	return writerRegistry(q.w)
+}
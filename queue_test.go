@@ -0,0 +1,101 @@
+package ipod_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/oandrew/ipod"
+)
+
+// serialWriter records the commands and fails on concurrent writes
+type serialWriter struct {
+	t      *testing.T
+	mu     sync.Mutex
+	active bool
+	cmds   []*ipod.Command
+}
+
+func (w *serialWriter) WriteCommand(cmd *ipod.Command) error {
+	w.mu.Lock()
+	if w.active {
+		w.t.Errorf("concurrent WriteCommand")
+	}
+	w.active = true
+	w.mu.Unlock()
+
+	w.mu.Lock()
+	w.cmds = append(w.cmds, cmd)
+	w.active = false
+	w.mu.Unlock()
+	return nil
+}
+
+func TestQueueWriter(t *testing.T) {
+	const writers, perWriter = 8, 50
+	w := &serialWriter{t: t}
+	q := ipod.NewQueueWriter(w, 4)
+
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < perWriter; j++ {
+				cmd := &ipod.Command{
+					ID:      ipod.NewLingoCmdID(uint16(i), 0x01),
+					Payload: &SessionRequest{V: uint8(j)},
+				}
+				if err := q.WriteCommand(cmd); err != nil {
+					t.Errorf("QueueWriter.WriteCommand() error = %v", err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err := q.Close(); err != nil {
+		t.Fatalf("QueueWriter.Close() error = %v", err)
+	}
+
+	if len(w.cmds) != writers*perWriter {
+		t.Fatalf("QueueWriter wrote %d commands, want %d", len(w.cmds), writers*perWriter)
+	}
+	next := make(map[uint16]uint8)
+	for _, cmd := range w.cmds {
+		v := cmd.Payload.(*SessionRequest).V
+		if v != next[cmd.ID.LingoID()] {
+			t.Fatalf("QueueWriter reordered the commands of writer %d", cmd.ID.LingoID())
+		}
+		next[cmd.ID.LingoID()]++
+	}
+
+	if err := q.WriteCommand(w.cmds[0]); err != ipod.ErrQueueClosed {
+		t.Errorf("QueueWriter.WriteCommand() after Close error = %v, want %v", err, ipod.ErrQueueClosed)
+	}
+}
+
+func TestSession_Queue(t *testing.T) {
+	frw := &testFrameReadWriter{}
+	s := ipod.NewSession(frw)
+	q := s.Queue()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(v uint8) {
+			defer wg.Done()
+			ipod.Send(q, &SessionResponse{V: v})
+		}(uint8(i))
+	}
+	wg.Wait()
+
+	// Serve closes the queue after writing the queued commands
+	if err := s.Serve(); err != nil {
+		t.Fatalf("Session.Serve() error = %v", err)
+	}
+	if len(frw.out) != 10 {
+		t.Errorf("Session.Queue() wrote %d frames, want 10", len(frw.out))
+	}
+	if err := s.Queue().WriteCommand(&ipod.Command{}); err != ipod.ErrQueueClosed {
+		t.Errorf("Session.Queue() after Serve error = %v, want %v", err, ipod.ErrQueueClosed)
+	}
+}
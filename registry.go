@@ -0,0 +1,366 @@
+package ipod
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Version is a lingo protocol version
+type Version struct {
+	Major, Minor uint8
+}
+
+// Less reports whether v is older than o
+func (v Version) Less(o Version) bool {
+	if v.Major != o.Major {
+		return v.Major < o.Major
+	}
+	return v.Minor < o.Minor
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%02d", v.Major, v.Minor)
+}
+
+type payloadType struct {
+	t          reflect.Type
+	ptr        reflect.Type
+	minVersion Version
+	size       int
+	new        func() interface{}
+	rules      []rule
+}
+
+func reflectPayloadType(t reflect.Type, minVersion Version) payloadType {
+	return payloadType{
+		t:          t,
+		ptr:        reflect.PtrTo(t),
+		minVersion: minVersion,
+		size:       wireSize(t),
+		new: func() interface{} {
+			return reflect.New(t).Interface()
+		},
+	}
+}
+
+// PayloadDef describes a payload type of a lingo command.
+// Tables of PayloadDefs are generated by cmd/ipodgen.
+type PayloadDef struct {
+	// ID is the command ID
+	ID uint16
+	// Size is the wire size of the payload or -1 if it is variable
+	Size int
+	// New returns a pointer to a new zero value of the payload
+	New func() interface{}
+	// When holds the discriminator rules, see LookupPayload
+	When string
+}
+
+// Registry maps LingoCmdIDs to payload types and back.
+// A command can have several payload layouts, told apart by the payload size
+// and by the lingo protocol version they were introduced in.
+//
+// Registries are not safe for concurrent modification,
+// they are expected to be set up before use.
+type Registry struct {
+	idToType map[LingoCmdID][]payloadType
+	typeToID map[reflect.Type]LingoCmdID
+	acks     map[uint8]ACKFunc
+}
+
+// ACKFunc builds the ACK payload of a lingo answering req with the status
+type ACKFunc func(req *Command, status ACKStatus) interface{}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{
+		idToType: make(map[LingoCmdID][]payloadType),
+		typeToID: make(map[reflect.Type]LingoCmdID),
+		acks:     make(map[uint8]ACKFunc),
+	}
+}
+
+// DefaultRegistry is the registry used by the package level functions.
+// The lingo packages register their commands in it on init.
+var DefaultRegistry = NewRegistry()
+
+func (r *Registry) store(cmd LingoCmdID, p payloadType) {
+	r.idToType[cmd] = append(r.idToType[cmd], p)
+	r.typeToID[p.t] = cmd
+}
+
+// RegisterLingos registers a set of lingo commands
+// valid for any protocol version
+func (r *Registry) RegisterLingos(lingoID uint8, m interface{}) error {
+	return r.RegisterLingosVersion(lingoID, Version{}, m)
+}
+
+// RegisterLingosVersion registers a set of lingo commands whose layout
+// was introduced in the lingo protocol version minVersion.
+// m is a struct with a field per command i.e.
+//
+//	struct {
+//		GetPlayStatus    `id:"0x001C"`
+//		ReturnPlayStatus `id:"0x001D"`
+//	}
+//
+// Payloads sharing a command ID can be told apart
+// by the discriminator rules of a when tag, see parseWhenTag.
+func (r *Registry) RegisterLingosVersion(lingoID uint8, minVersion Version, m interface{}) error {
+	lingos := reflect.TypeOf(m)
+
+	for i := 0; i < lingos.NumField(); i++ {
+		cmd := lingos.Field(i)
+		cmdID, err := parseIdTag(&cmd.Tag)
+		if err != nil {
+			return fmt.Errorf("register lingos: parse id tag err: %v", err)
+		}
+		p := reflectPayloadType(cmd.Type, minVersion)
+		if p.rules, err = parseWhenTag(cmd.Tag.Get("when")); err != nil {
+			return fmt.Errorf("register lingos: %s: %v", cmd.Name, err)
+		}
+
+		r.store(NewLingoCmdID(uint16(lingoID), cmdID), p)
+	}
+	return nil
+}
+
+// RegisterPayloads registers a table of lingo commands
+// valid for any protocol version
+func (r *Registry) RegisterPayloads(lingoID uint8, defs []PayloadDef) {
+	r.RegisterPayloadsVersion(lingoID, Version{}, defs)
+}
+
+// RegisterPayloadsVersion is like RegisterLingosVersion but takes a table
+// of commands, so that decoding needs no reflection.
+// It panics if a When rule is invalid.
+func (r *Registry) RegisterPayloadsVersion(lingoID uint8, minVersion Version, defs []PayloadDef) {
+	for _, d := range defs {
+		p := reflectPayloadType(reflect.TypeOf(d.New()).Elem(), minVersion)
+		p.new = d.New
+		if d.Size >= 0 {
+			p.size = d.Size
+		}
+		rules, err := parseWhenTag(d.When)
+		if err != nil {
+			panic(fmt.Sprintf("ipod: register payloads: %v: %v", p.t, err))
+		}
+		p.rules = rules
+		r.store(NewLingoCmdID(uint16(lingoID), d.ID), p)
+	}
+}
+
+// RegisterACK registers the ACK builder of the lingo
+func (r *Registry) RegisterACK(lingoID uint8, f ACKFunc) {
+	r.acks[lingoID] = f
+}
+
+// ACK returns the ACK payload of the lingo of req answering it with the status,
+// ok is false if the lingo has no ACK builder
+func (r *Registry) ACK(req *Command, status ACKStatus) (payload interface{}, ok bool) {
+	f, ok := r.acks[uint8(req.ID.LingoID())]
+	if !ok {
+		return nil, false
+	}
+	return f(req, status), true
+}
+
+// Subset returns a new registry with only the commands of the given lingos
+func (r *Registry) Subset(lingoIDs ...uint8) *Registry {
+	sub := NewRegistry()
+	for _, lingoID := range lingoIDs {
+		if f, ok := r.acks[lingoID]; ok {
+			sub.acks[lingoID] = f
+		}
+		for id, payloads := range r.idToType {
+			if id.LingoID() != uint16(lingoID) {
+				continue
+			}
+			for _, p := range payloads {
+				sub.store(id, p)
+			}
+		}
+	}
+	return sub
+}
+
+// LookupID finds a registered LingoCmdID by the type of v
+// i.e. reverse to Lookup
+func (r *Registry) LookupID(v interface{}) (id LingoCmdID, ok bool) {
+	t := reflect.TypeOf(v)
+	if t.Kind() != reflect.Ptr {
+		panic(fmt.Sprintf("payload is not pointer: %v", v))
+	}
+	id, ok = r.typeToID[t.Elem()]
+	return
+}
+
+// LookupName finds a registered payload by the name of its type
+// as returned by PayloadName, i.e. "general.RequestIdentify",
+// and returns its LingoCmdID and a pointer to a new zero value
+func (r *Registry) LookupName(name string) (id LingoCmdID, payload interface{}, ok bool) {
+	for id, payloads := range r.idToType {
+		for _, p := range payloads {
+			if p.t.String() == name {
+				return id, p.new(), true
+			}
+		}
+	}
+	return 0, nil, false
+}
+
+// Names returns the names of all registered payload types
+// ordered by LingoCmdID
+func (r *Registry) Names() []string {
+	ids := make([]LingoCmdID, 0, len(r.idToType))
+	for id := range r.idToType {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	var names []string
+	for _, id := range ids {
+		for _, p := range r.idToType[id] {
+			names = append(names, p.t.String())
+		}
+	}
+	return names
+}
+
+// Lookup finds a the payload by LingoCmdID using payloadSize as a hint.
+// All registered layouts are considered regardless of the version.
+//
+// Deprecated: the discriminator rules are not checked, use LookupPayload
+func (r *Registry) Lookup(id LingoCmdID, payloadSize int) (LookupResult, bool) {
+	return lookupPayload(r.idToType[id], payloadSize)
+}
+
+// LookupVersion is like Lookup but only considers the layouts
+// available in the lingo protocol version v, the newest first
+func (r *Registry) LookupVersion(id LingoCmdID, payloadSize int, v Version) (LookupResult, bool) {
+	var payloads []payloadType
+	for _, p := range r.idToType[id] {
+		if !v.Less(p.minVersion) {
+			payloads = append(payloads, p)
+		}
+	}
+	sort.SliceStable(payloads, func(i, j int) bool {
+		return payloads[j].minVersion.Less(payloads[i].minVersion)
+	})
+	return lookupPayload(payloads, payloadSize)
+}
+
+func lookupPayload(payloads []payloadType, payloadSize int) (LookupResult, bool) {
+	if len(payloads) == 0 {
+		return LookupResult{}, false
+	}
+	for _, p := range payloads {
+		if p.size < 0 {
+			continue
+		}
+		switch p.size {
+		case payloadSize:
+			return LookupResult{
+				Payload:     p.new(),
+				Transaction: false,
+			}, true
+		case payloadSize - 2:
+			return LookupResult{
+				Payload:     p.new(),
+				Transaction: true,
+			}, true
+		}
+	}
+	if len(payloads) == 1 {
+		return LookupResult{
+			Payload:     payloads[0].new(),
+			Transaction: true,
+		}, true
+	}
+
+	return LookupResult{}, false
+}
+
+// BuildCommand creates a command with the ID registered for the payload
+func (r *Registry) BuildCommand(payload interface{}) (*Command, error) {
+	id, ok := r.LookupID(payload)
+	if !ok {
+		return nil, errors.New("payload not known")
+	}
+	return &Command{
+		ID:      id,
+		Payload: payload,
+	}, nil
+}
+
+// Dump returns a list of all registered lingos and commands
+func (r *Registry) Dump() string {
+	type cmd struct {
+		id   LingoCmdID
+		name string
+	}
+	var cmds []cmd
+	for id, types := range r.idToType {
+		cmds = append(cmds, cmd{id, types[0].t.String()})
+	}
+	sort.Slice(cmds, func(i, j int) bool {
+		return cmds[i].id < cmds[j].id
+	})
+	buf := bytes.Buffer{}
+	for _, cmd := range cmds {
+		fmt.Fprintf(&buf, "%s\t%s\n", cmd.id.GoString(), cmd.name)
+	}
+	return buf.String()
+}
+
+// RegistryWriter is a CommandWriter that encodes commands
+// with its own registry, i.e. a Session
+type RegistryWriter interface {
+	CommandWriter
+	LingoRegistry() *Registry
+}
+
+func writerRegistry(w CommandWriter) *Registry {
+	if rw, ok := w.(RegistryWriter); ok {
+		return rw.LingoRegistry()
+	}
+	return DefaultRegistry
+}
+
+// RegisterLingos registers a set of lingo commands in the DefaultRegistry
+func RegisterLingos(lingoID uint8, m interface{}) error {
+	return DefaultRegistry.RegisterLingos(lingoID, m)
+}
+
+// RegisterPayloads registers a table of lingo commands in the DefaultRegistry
+func RegisterPayloads(lingoID uint8, defs []PayloadDef) {
+	DefaultRegistry.RegisterPayloads(lingoID, defs)
+}
+
+// RegisterACK registers the ACK builder of the lingo in the DefaultRegistry
+func RegisterACK(lingoID uint8, f ACKFunc) {
+	DefaultRegistry.RegisterACK(lingoID, f)
+}
+
+// DumpLingos returns a list of all lingos and commands
+// registered in the DefaultRegistry
+func DumpLingos() string {
+	return DefaultRegistry.Dump()
+}
+
+// LookupID finds a LingoCmdID in the DefaultRegistry by the type of v
+func LookupID(v interface{}) (id LingoCmdID, ok bool) {
+	return DefaultRegistry.LookupID(v)
+}
+
+// Lookup finds a the payload in the DefaultRegistry by LingoCmdID
+// using payloadSize as a hint
+//
+// Deprecated: the discriminator rules are not checked, use DefaultRegistry.LookupPayload
+func Lookup(id LingoCmdID, payloadSize int) (LookupResult, bool) {
+	return DefaultRegistry.Lookup(id, payloadSize)
+}
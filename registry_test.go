@@ -0,0 +1,178 @@
+package ipod_test
+
+import (
+	"encoding"
+	"reflect"
+	"testing"
+
+	"github.com/oandrew/ipod"
+	"github.com/oandrew/ipod/lingo-general"
+)
+
+type RegistryPayloadV1 struct {
+	A uint8
+}
+
+type RegistryPayloadV2 struct {
+	A uint8
+	B uint8
+}
+
+type RegistryOther struct {
+	A uint8
+}
+
+func newTestRegistry(t *testing.T) *ipod.Registry {
+	r := ipod.NewRegistry()
+	err := r.RegisterLingos(0xa0, struct {
+		RegistryPayloadV1 `id:"0x01"`
+	}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.RegisterLingosVersion(0xa0, ipod.Version{Major: 1, Minor: 2}, struct {
+		RegistryPayloadV2 `id:"0x01"`
+	}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.RegisterLingos(0xa1, struct {
+		RegistryOther `id:"0x01"`
+	}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestRegistry_LookupVersion(t *testing.T) {
+	r := newTestRegistry(t)
+	id := ipod.NewLingoCmdID(0xa0, 0x01)
+	tests := []struct {
+		name    string
+		size    int
+		version ipod.Version
+		want    interface{}
+		wantTrx bool
+	}{
+		{"v1", 1, ipod.Version{Major: 1}, &RegistryPayloadV1{}, false},
+		{"v1 with trx", 3, ipod.Version{Major: 1}, &RegistryPayloadV1{}, true},
+		{"v1 ignores v2 layout", 2, ipod.Version{Major: 1, Minor: 1}, &RegistryPayloadV1{}, true},
+		{"v2", 2, ipod.Version{Major: 1, Minor: 2}, &RegistryPayloadV2{}, false},
+		{"v2 with trx", 4, ipod.Version{Major: 2}, &RegistryPayloadV2{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := r.LookupVersion(id, tt.size, tt.version)
+			if !ok {
+				t.Fatalf("Registry.LookupVersion() not found")
+			}
+			if !reflect.DeepEqual(got.Payload, tt.want) || got.Transaction != tt.wantTrx {
+				t.Errorf("Registry.LookupVersion() = %#v, want %#v trx %v", got, tt.want, tt.wantTrx)
+			}
+		})
+	}
+}
+
+func TestRegistry_LookupPayloadGeneralVersion(t *testing.T) {
+	id := ipod.NewLingoCmdID(general.LingoGeneralID, 0x28)
+	off := func(v ipod.Version) ipod.LookupContext {
+		return ipod.LookupContext{Version: v, TransactionMode: ipod.TransactionModeOff}
+	}
+	caps := []byte{0x00, 0x00, 0x00, 0x02, 0x04}
+	tests := []struct {
+		name string
+		data []byte
+		ctx  ipod.LookupContext
+		want interface{}
+	}{
+		{"v1.00 caps", caps, off(ipod.Version{Major: 1}), &general.RetAccessoryInfo{}},
+		{"v1.04 caps", caps, off(ipod.Version{Major: 1, Minor: 4}), &general.RetAccessoryInfoCaps{}},
+		{"v1.04 lingo version", []byte{0x03, 0x04, 0x01, 0x0c}, off(ipod.Version{Major: 1, Minor: 4}), &general.RetAccessoryInfoLingoVersion{}},
+		// the firmware version has the size of the lingo version
+		{"v1.04 firmware version", []byte{0x04, 0x01, 0x02, 0x03}, off(ipod.Version{Major: 1, Minor: 4}), &general.RetAccessoryInfo{}},
+		{"v1.09 name", []byte{0x01, 'a', 0x00}, off(ipod.Version{Major: 1, Minor: 9}), &general.RetAccessoryInfo{}},
+		{"unknown version caps", append([]byte{0x00, 0x01}, caps...), ipod.LookupContext{}, &general.RetAccessoryInfoCaps{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ipod.DefaultRegistry.LookupPayload(id, tt.data, tt.ctx)
+			if err != nil {
+				t.Fatalf("Registry.LookupPayload() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Payload, tt.want) {
+				t.Errorf("Registry.LookupPayload() = %#v, want %#v", got.Payload, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistry_LookupVersionGeneralRoundTrip(t *testing.T) {
+	v := ipod.Version{Major: 1, Minor: 4}
+	tests := []struct {
+		cmdID   uint16
+		payload interface{}
+	}{
+		{0x27, &general.GetAccessoryInfo2{InfoType: 0x02, ModelID: 0x000b0005, Major: 1, Minor: 2, Rev: 3}},
+		{0x27, &general.GetAccessoryInfo3{InfoType: 0x03, LingoID: 0x04}},
+		{0x28, &general.RetAccessoryInfoCaps{InfoType: 0x00, Caps: 0x00000205}},
+		{0x28, &general.RetAccessoryInfoMinFirmware{InfoType: 0x02, ModelID: 0x000b0005, Major: 1, Minor: 2, Rev: 3}},
+		{0x28, &general.RetAccessoryInfoLingoVersion{InfoType: 0x03, LingoID: 0x04, Major: 1, Minor: 12}},
+	}
+	for _, tt := range tests {
+		t.Run(reflect.TypeOf(tt.payload).Elem().Name(), func(t *testing.T) {
+			m, ok := tt.payload.(encoding.BinaryMarshaler)
+			if !ok {
+				t.Fatalf("%T has no generated codec", tt.payload)
+			}
+			data, err := m.MarshalBinary()
+			if err != nil {
+				t.Fatalf("MarshalBinary() error = %v", err)
+			}
+			res, ok := ipod.DefaultRegistry.LookupVersion(ipod.NewLingoCmdID(general.LingoGeneralID, tt.cmdID), len(data), v)
+			if !ok {
+				t.Fatalf("Registry.LookupVersion() not found")
+			}
+			u, ok := res.Payload.(encoding.BinaryUnmarshaler)
+			if !ok {
+				t.Fatalf("%T has no generated codec", res.Payload)
+			}
+			if err := u.UnmarshalBinary(data); err != nil {
+				t.Fatalf("UnmarshalBinary() error = %v", err)
+			}
+			if !reflect.DeepEqual(res.Payload, tt.payload) {
+				t.Errorf("round trip = %#v, want %#v", res.Payload, tt.payload)
+			}
+		})
+	}
+}
+
+func TestRegistry_Subset(t *testing.T) {
+	r := newTestRegistry(t).Subset(0xa1)
+	if _, ok := r.LookupID(&RegistryPayloadV1{}); ok {
+		t.Errorf("Registry.Subset() contains a disabled lingo")
+	}
+	if id, ok := r.LookupID(&RegistryOther{}); !ok || id != ipod.NewLingoCmdID(0xa1, 0x01) {
+		t.Errorf("Registry.Subset() LookupID = %v, %v", id, ok)
+	}
+	if _, ok := ipod.LookupID(&RegistryOther{}); ok {
+		t.Errorf("DefaultRegistry contains a lingo of another registry")
+	}
+}
+
+func TestRegistry_ACK(t *testing.T) {
+	r := newTestRegistry(t)
+	r.RegisterACK(0xa1, func(req *ipod.Command, status ipod.ACKStatus) interface{} {
+		return &RegistryOther{A: uint8(status)}
+	})
+	req := &ipod.Command{ID: ipod.NewLingoCmdID(0xa1, 0x01)}
+	if got, ok := r.ACK(req, ipod.ACKStatusBadParameter); !ok || !reflect.DeepEqual(got, &RegistryOther{A: 0x04}) {
+		t.Errorf("Registry.ACK() = %#v, %v", got, ok)
+	}
+	if _, ok := r.Subset(0xa1).ACK(req, ipod.ACKStatusSuccess); !ok {
+		t.Errorf("Registry.Subset() ACK not found")
+	}
+	if _, ok := r.ACK(&ipod.Command{ID: ipod.NewLingoCmdID(0xa0, 0x01)}, ipod.ACKStatusSuccess); ok {
+		t.Errorf("Registry.ACK() found for a lingo without ACK")
+	}
+}
@@ -0,0 +1,164 @@
+package ipod
+
+import (
+	"context"
+	"sync"
+)
+
+// CommandCanceler is implemented by the writers that track
+// the requests in flight, i.e. the RequestWriter of a Session
+type CommandCanceler interface {
+	CancelCommand(id LingoCmdID, trx Transaction) bool
+}
+
+// RequestWriter is the CommandWriter a Session passes to the handlers.
+// It is bound to the request being handled: the request stays in flight
+// until the handler returns or, if the handler calls Go, until
+// the function passed to Go returns.
+//
+// When the accessory cancels the request, its context is done and
+// the commands written afterwards are dropped, WriteCommand returns
+// the error of the context.
+type RequestWriter struct {
+	s      *Session
+	req    *Command
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu sync.Mutex
+}
+
+// Context returns the context of the request, it is done when
+// the request is cancelled or the session stops
+func (w *RequestWriter) Context() context.Context {
+	return w.ctx
+}
+
+// Request returns the command being handled
+func (w *RequestWriter) Request() *Command {
+	return w.req
+}
+
+// WriteCommand writes the command to the session
+// unless the request is cancelled
+func (w *RequestWriter) WriteCommand(cmd *Command) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+	return w.s.WriteCommand(cmd)
+}
+
+// Transactions returns the transactions of the session
+func (w *RequestWriter) Transactions() *Transactions {
+	return w.s.Transactions()
+}
+
+// LingoRegistry returns the registry of the session
+func (w *RequestWriter) LingoRegistry() *Registry {
+	return w.s.LingoRegistry()
+}
+
+func (w *RequestWriter) trackResponse(cmd *Command) {
+	w.s.trackResponse(cmd)
+}
+
+// SetMaxPayload sets the payload size limit of the session
+func (w *RequestWriter) SetMaxPayload(dir Dir, n int) {
+	w.s.SetMaxPayload(dir, n)
+}
+
+// CancelCommand cancels the matching request in flight of the session
+func (w *RequestWriter) CancelCommand(id LingoCmdID, trx Transaction) bool {
+	return w.s.CancelCommand(id, trx)
+}
+
+// Go runs f in a goroutine of its own and keeps the request
+// in flight until f returns, i.e. for long database listings.
+// The error returned by f is passed to the HandlerError hook.
+// Go must be called by the handler or by a function passed to Go.
+func (w *RequestWriter) Go(f func(ctx context.Context, w CommandWriter) error) {
+	w.s.requests.start(w)
+	go func() {
+		defer w.s.requests.finish(w)
+		if err := f(w.ctx, w); err != nil {
+			w.s.Hooks.handlerError(w.req, err)
+		}
+	}()
+}
+
+func (w *RequestWriter) matches(id LingoCmdID, trx Transaction) bool {
+	if w.req.ID != id {
+		return false
+	}
+	return w.req.Transaction == nil || *w.req.Transaction == trx
+}
+
+// abort cancels the context of the request, the commands
+// being written complete before it returns
+func (w *RequestWriter) abort() {
+	w.mu.Lock()
+	w.cancel()
+	w.mu.Unlock()
+}
+
+// RequestContext returns the context of the request w is bound to,
+// context.Background() if w is not a RequestWriter
+func RequestContext(w CommandWriter) context.Context {
+	if rw, ok := w.(*RequestWriter); ok {
+		return rw.Context()
+	}
+	return context.Background()
+}
+
+// requestTracker keeps the requests in flight of a Session.
+// A request is counted once for the handler and once for every Go.
+type requestTracker struct {
+	mu       sync.Mutex
+	inflight map[*RequestWriter]int
+	wg       sync.WaitGroup
+}
+
+func (rt *requestTracker) start(w *RequestWriter) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if rt.inflight == nil {
+		rt.inflight = make(map[*RequestWriter]int)
+	}
+	rt.inflight[w]++
+	rt.wg.Add(1)
+}
+
+func (rt *requestTracker) finish(w *RequestWriter) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.inflight[w]--
+	if rt.inflight[w] == 0 {
+		delete(rt.inflight, w)
+		w.cancel()
+	}
+	rt.wg.Done()
+}
+
+// cancel aborts the requests in flight matching id and trx
+// that are not done yet and reports whether there were any
+func (rt *requestTracker) cancel(id LingoCmdID, trx Transaction) bool {
+	rt.mu.Lock()
+	var matched []*RequestWriter
+	for w := range rt.inflight {
+		if w.matches(id, trx) && w.ctx.Err() == nil {
+			matched = append(matched, w)
+		}
+	}
+	rt.mu.Unlock()
+	for _, w := range matched {
+		w.abort()
+	}
+	return len(matched) > 0
+}
+
+// wait waits for the requests in flight to finish
+func (rt *requestTracker) wait() {
+	rt.wg.Wait()
+}
@@ -0,0 +1,247 @@
+package ipod_test
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/oandrew/ipod"
+	"github.com/oandrew/ipod/lingo-extremote"
+	"github.com/oandrew/ipod/lingo-general"
+)
+
+func TestSession_CancelCommand(t *testing.T) {
+	frw := &testFrameReadWriter{
+		in: [][]byte{
+			buildFrame(t, sessionReq(1)),
+			buildFrame(t, sessionReq(2)),
+		},
+	}
+	s := ipod.NewSession(frw)
+
+	started := make(chan struct{})
+	var lateErr error
+	var cancelled, cancelledAgain bool
+	s.HandleFunc(testSessionLingoID, func(cmd *ipod.Command, w ipod.CommandWriter) error {
+		switch cmd.Payload.(*SessionRequest).V {
+		case 1:
+			w.(*ipod.RequestWriter).Go(func(ctx context.Context, w ipod.CommandWriter) error {
+				respondSessionRequest(cmd, w)
+				close(started)
+				<-ctx.Done()
+				lateErr = w.WriteCommand(sessionResp(1))
+				return nil
+			})
+		case 2:
+			<-started
+			c := w.(ipod.CommandCanceler)
+			id := ipod.NewLingoCmdID(testSessionLingoID, 0x01)
+			cancelled = c.CancelCommand(id, 1)
+			cancelledAgain = c.CancelCommand(id, 1)
+			respondSessionRequest(cmd, w)
+		}
+		return nil
+	})
+
+	// Serve waits for the requests in flight
+	if err := s.Serve(); err != nil {
+		t.Fatalf("Session.Serve() error = %v", err)
+	}
+	if !cancelled {
+		t.Errorf("Session.CancelCommand() = false, want true")
+	}
+	if cancelledAgain {
+		t.Errorf("Session.CancelCommand() of a cancelled request = true, want false")
+	}
+	if lateErr != context.Canceled {
+		t.Errorf("RequestWriter.WriteCommand() after cancel error = %v, want %v", lateErr, context.Canceled)
+	}
+	want := [][]byte{
+		buildFrame(t, sessionResp(1)),
+		buildFrame(t, sessionResp(2)),
+	}
+	if !reflect.DeepEqual(frw.out, want) {
+		t.Errorf("Session.Serve() frames = %x, want %x", frw.out, want)
+	}
+}
+
+func TestSession_CancelCommandTransaction(t *testing.T) {
+	frw := &testFrameReadWriter{
+		in: [][]byte{
+			buildFrame(t, sessionReq(1)),
+		},
+	}
+	s := ipod.NewSession(frw)
+
+	var ctxErr error
+	s.HandleFunc(testSessionLingoID, func(cmd *ipod.Command, w ipod.CommandWriter) error {
+		id := ipod.NewLingoCmdID(testSessionLingoID, 0x01)
+		if s.CancelCommand(id, 2) {
+			t.Errorf("Session.CancelCommand() of another transaction = true, want false")
+		}
+		if s.CancelCommand(ipod.NewLingoCmdID(testSessionLingoID, 0x02), 1) {
+			t.Errorf("Session.CancelCommand() of another command = true, want false")
+		}
+		ctx := ipod.RequestContext(w)
+		if !s.CancelCommand(id, 1) {
+			t.Errorf("Session.CancelCommand() = false, want true")
+		}
+		ctxErr = ctx.Err()
+		respondSessionRequest(cmd, w)
+		return nil
+	})
+
+	if err := s.Serve(); err != nil {
+		t.Fatalf("Session.Serve() error = %v", err)
+	}
+	if ctxErr != context.Canceled {
+		t.Errorf("RequestContext() error = %v, want %v", ctxErr, context.Canceled)
+	}
+	if len(frw.out) != 0 {
+		t.Errorf("Session.Serve() frames = %x, want none", frw.out)
+	}
+	if ipod.RequestContext(s) != context.Background() {
+		t.Errorf("RequestContext() of a session is not context.Background()")
+	}
+}
+
+// listingDevice passes the index of every record it lists to listed
+type listingDevice struct {
+	listed chan int
+}
+
+func (d *listingDevice) PlaybackStatus() (uint32, uint32, extremote.PlayerState) {
+	return 0, 0, extremote.PlayerStateStopped
+}
+
+func (d *listingDevice) NumRecords(extremote.DBCategoryType) int {
+	return 100
+}
+
+func (d *listingDevice) Record(_ extremote.DBCategoryType, index int) string {
+	d.listed <- index
+	return "record"
+}
+
+func TestSession_CancelListing(t *testing.T) {
+	frw := newChanFrameReadWriter()
+	s := ipod.NewSession(frw)
+	dev := &listingDevice{listed: make(chan int)}
+	s.HandleFunc(extremote.LingoExtRemotelID, func(cmd *ipod.Command, w ipod.CommandWriter) error {
+		return extremote.HandleExtRemote(cmd, w, dev)
+	})
+	s.HandleFunc(general.LingoGeneralID, func(cmd *ipod.Command, w ipod.CommandWriter) error {
+		msg := cmd.Payload.(*general.CancelCommand)
+		w.(ipod.CommandCanceler).CancelCommand(ipod.NewLingoCmdID(uint16(msg.LingoID), msg.CmdID), ipod.Transaction(msg.TransactionID))
+		ipod.Respond(cmd, w, &general.ACK{Status: general.ACKStatusSuccess, CmdID: uint8(cmd.ID.CmdID())})
+		return nil
+	})
+	served := make(chan error)
+	go func() {
+		served <- s.Serve()
+	}()
+
+	retrieve := ipod.NewLingoCmdID(extremote.LingoExtRemotelID, 0x001A)
+	frw.in <- buildFrame(t, &ipod.Command{
+		ID:          retrieve,
+		Transaction: ipod.NewTransaction(0x10),
+		Payload:     &extremote.RetrieveCategorizedDatabaseRecords{CategoryType: extremote.DbCategoryTrack, Count: -1},
+	})
+	if i := <-dev.listed; i != 0 {
+		t.Fatalf("listed record %d, want 0", i)
+	}
+	readCommand(t, <-frw.out)
+
+	// the session reads the cancel while the listing runs
+	frw.in <- buildFrame(t, &ipod.Command{
+		ID:          ipod.NewLingoCmdID(general.LingoGeneralID, 0x50),
+		Transaction: ipod.NewTransaction(0x11),
+		Payload:     &general.CancelCommand{LingoID: extremote.LingoExtRemotelID, CmdID: 0x001A, TransactionID: 0x10},
+	})
+	select {
+	case frame := <-frw.out:
+		if _, ok := readCommand(t, frame).Payload.(*general.ACK); !ok {
+			t.Fatalf("Session.Serve() sent %x, want the ACK of the cancel", frame)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("the cancel was not handled while the listing runs")
+	}
+	// the record being listed is dropped and the listing stops
+	<-dev.listed
+	close(frw.in)
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Fatalf("Session.Serve() error = %v", err)
+		}
+	case i := <-dev.listed:
+		t.Fatalf("listed record %d after the cancel", i)
+	case <-time.After(time.Second):
+		t.Fatalf("Session.Serve() didn't return")
+	}
+	select {
+	case frame := <-frw.out:
+		t.Errorf("Session.Serve() sent %x after the cancel", frame)
+	default:
+	}
+}
+
+// maxPayloadDevice is a general device that only
+// answers the payload size negotiation
+type maxPayloadDevice struct {
+	general.DeviceGeneral
+	max uint16
+}
+
+func (d *maxPayloadDevice) MaxPayload() uint16 {
+	return d.max
+}
+
+func (d *maxPayloadDevice) SetToken(general.FIDTokenValue) error {
+	return nil
+}
+
+// rawFrame builds a frame of the encoded packets,
+// for the payloads that have no encoder
+func rawFrame(t testing.TB, pkts ...[]byte) []byte {
+	frame := bytes.Buffer{}
+	pw := ipod.NewPacketWriter(&frame)
+	for _, pkt := range pkts {
+		if err := pw.WritePacket(pkt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return frame.Bytes()
+}
+
+// maxPayloadFrames negotiate the payload sizes: the accessory sets out
+// in its AccInfoMaxPayload token and asks for the incoming limit
+func maxPayloadFrames(t testing.TB, out uint16) [][]byte {
+	return [][]byte{
+		// SetFIDTokenValues with an AccInfoMaxPayload FIDAccInfoToken
+		rawFrame(t, []byte{0x00, 0x39, 0x00, 0x01, 0x01, 0x05, 0x00, 0x02, 0x09, byte(out >> 8), byte(out)}),
+		// RequestTransportMaxPayloadSize
+		rawFrame(t, []byte{0x00, 0x11, 0x00, 0x02}),
+	}
+}
+
+func TestSession_HandleGeneralMaxPayload(t *testing.T) {
+	frw := &testFrameReadWriter{in: maxPayloadFrames(t, 0x40)}
+	s := ipod.NewSession(frw)
+	s.Transactions().SetMode(ipod.TransactionModeOn)
+	dev := &maxPayloadDevice{max: 100}
+	s.HandleFunc(general.LingoGeneralID, func(cmd *ipod.Command, w ipod.CommandWriter) error {
+		return general.HandleGeneral(cmd, w, dev)
+	})
+	if err := s.Serve(); err != nil {
+		t.Fatalf("Session.Serve() error = %v", err)
+	}
+	if got := s.MaxPayload(ipod.DirOut); got != 0x40 {
+		t.Errorf("Session.MaxPayload(DirOut) = %d, want %d", got, 0x40)
+	}
+	if got := s.MaxPayload(ipod.DirIn); got != 100 {
+		t.Errorf("Session.MaxPayload(DirIn) = %d, want %d", got, 100)
+	}
+}
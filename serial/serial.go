@@ -0,0 +1,163 @@
+// Package serial implements iap over a serial (uart) link
+package serial
+
+import (
+	"bufio"
+	"errors"
+	"io"
+
+	"github.com/oandrew/ipod"
+)
+
+// SyncByte is sent before the packet start byte
+// to wake up the other side of the link
+const SyncByte byte = 0xff
+
+// Common baud rates of the serial link
+const (
+	BaudRate19200 = 19200
+	BaudRate57600 = 57600
+)
+
+// Transport frames raw iap packets over an io.ReadWriter
+// i.e. a tty, a pty or a socket.
+// Every frame contains exactly one packet.
+type Transport struct {
+	r *bufio.Reader
+	w io.Writer
+	// Sync prefixes every outgoing frame with SyncByte
+	Sync bool
+
+	buf []byte
+	out []byte
+	// pend are the bytes of a false start, scanned before reading more
+	pend []byte
+}
+
+// ErrChecksum is returned by ReadFrame for a packet with a bad checksum
+var ErrChecksum = errors.New("serial: packet checksum mismatch")
+
+// NewTransport creates a Transport that reads and writes frames using rw
+func NewTransport(rw io.ReadWriter) *Transport {
+	return &Transport{
+		r:    bufio.NewReaderSize(rw, 512),
+		w:    rw,
+		Sync: true,
+		buf:  make([]byte, 0, 1024),
+	}
+}
+
+// ReadFrame reads a single raw packet including the start byte,
+// the length and the checksum. Sync bytes and garbage preceding
+// the start byte are skipped. The returned slice is only valid
+// until the next call to ReadFrame.
+//
+// A start byte in the garbage can look like a packet: if its checksum
+// doesn't match ErrChecksum is returned and the next call scans again
+// from the byte after the start byte.
+func (t *Transport) ReadFrame() ([]byte, error) {
+	for {
+		b, err := t.readByte()
+		if err != nil {
+			return nil, err
+		}
+		if b == ipod.PacketStartByte {
+			break
+		}
+	}
+	buf := append(t.buf[:0], ipod.PacketStartByte)
+
+	lenByte, err := t.readByte()
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	buf = append(buf, lenByte)
+
+	payLen := int(lenByte)
+	if lenByte == 0x00 {
+		var lenBuf [2]byte
+		if err := t.readFull(lenBuf[:]); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		buf = append(buf, lenBuf[:]...)
+		payLen = int(lenBuf[0])<<8 | int(lenBuf[1])
+		if payLen == 0 {
+			t.rescan(buf)
+			return nil, errors.New("serial: zero length packet")
+		}
+	}
+
+	n := len(buf)
+	buf = grow(buf, payLen+1)
+	if err := t.readFull(buf[n:]); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	t.buf = buf
+	if !checksumOK(buf) {
+		t.rescan(buf)
+		return nil, ErrChecksum
+	}
+	return buf, nil
+}
+
+func (t *Transport) readByte() (byte, error) {
+	if len(t.pend) > 0 {
+		b := t.pend[0]
+		t.pend = t.pend[1:]
+		return b, nil
+	}
+	return t.r.ReadByte()
+}
+
+func (t *Transport) readFull(p []byte) error {
+	n := copy(p, t.pend)
+	t.pend = t.pend[n:]
+	_, err := io.ReadFull(t.r, p[n:])
+	return err
+}
+
+// rescan makes the bytes of frame after the start byte
+// the next ones to scan
+func (t *Transport) rescan(frame []byte) {
+	pend := make([]byte, 0, len(frame)-1+len(t.pend))
+	pend = append(pend, frame[1:]...)
+	t.pend = append(pend, t.pend...)
+}
+
+// checksumOK reports whether the length, the payload
+// and the checksum of the packet add up to zero
+func checksumOK(frame []byte) bool {
+	var sum byte
+	for _, b := range frame[1:] {
+		sum += b
+	}
+	return sum == 0
+}
+
+// WriteFrame writes a frame that contains one or more packets
+func (t *Transport) WriteFrame(data []byte) error {
+	out := t.out[:0]
+	if t.Sync {
+		out = append(out, SyncByte)
+	}
+	out = append(out, data...)
+	t.out = out
+	_, err := t.w.Write(out)
+	return err
+}
+
+func grow(b []byte, n int) []byte {
+	if cap(b)-len(b) < n {
+		nb := make([]byte, len(b), len(b)+n)
+		copy(nb, b)
+		b = nb
+	}
+	return b[:len(b)+n]
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
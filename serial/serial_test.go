@@ -0,0 +1,116 @@
+package serial_test
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/oandrew/ipod/serial"
+)
+
+type testReadWriter struct {
+	r io.Reader
+	w bytes.Buffer
+}
+
+func (rw *testReadWriter) Read(p []byte) (int, error) {
+	return rw.r.Read(p)
+}
+
+func (rw *testReadWriter) Write(p []byte) (int, error) {
+	return rw.w.Write(p)
+}
+
+func TestTransport_ReadFrame(t *testing.T) {
+	largeData := bytes.Repeat([]byte{0xee}, 255)
+	largePacket := append([]byte{0x55, 0x00, 0x01, 0x01, 0x01, 0x02}, append(largeData, 0xe9)...)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    [][]byte
+		wantErr bool
+	}{
+		{"packet", []byte{0x55, 0x03, 0x01, 0x02, 0xfd, 0xfd}, [][]byte{
+			{0x55, 0x03, 0x01, 0x02, 0xfd, 0xfd},
+		}, false},
+		{"sync-packet", []byte{0xff, 0x55, 0x03, 0x01, 0x02, 0xfd, 0xfd}, [][]byte{
+			{0x55, 0x03, 0x01, 0x02, 0xfd, 0xfd},
+		}, false},
+		{"garbage-packet", []byte{0x01, 0x02, 0xff, 0x55, 0x02, 0x01, 0x02, 0xfb}, [][]byte{
+			{0x55, 0x02, 0x01, 0x02, 0xfb},
+		}, false},
+		{"two-packets", []byte{0xff, 0x55, 0x02, 0x01, 0x02, 0xfb, 0xff, 0x55, 0x03, 0x01, 0x02, 0xfd, 0xfd}, [][]byte{
+			{0x55, 0x02, 0x01, 0x02, 0xfb},
+			{0x55, 0x03, 0x01, 0x02, 0xfd, 0xfd},
+		}, false},
+		{"large-packet", largePacket, [][]byte{largePacket}, false},
+		{"short-packet", []byte{0x55, 0x03, 0x01, 0x02}, nil, true},
+		{"short-large-packet", []byte{0x55, 0x00, 0x01}, nil, true},
+		{"bad-checksum", []byte{0x55, 0x02, 0x01, 0x02, 0xfc}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// one byte per read to make sure frames are reassembled
+			rw := &testReadWriter{r: &oneByteReader{bytes.NewReader(tt.data)}}
+			tr := serial.NewTransport(rw)
+			var got [][]byte
+			for {
+				frame, err := tr.ReadFrame()
+				if err == io.EOF {
+					break
+				}
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("Transport.ReadFrame() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if err != nil {
+					return
+				}
+				got = append(got, append([]byte(nil), frame...))
+			}
+			if tt.wantErr {
+				t.Fatalf("Transport.ReadFrame() expected error")
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Transport.ReadFrame() = %x, want %x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransport_WriteFrame(t *testing.T) {
+	frame := []byte{0x55, 0x03, 0x01, 0x02, 0xfd, 0xfd}
+	tests := []struct {
+		name string
+		sync bool
+		want []byte
+	}{
+		{"sync", true, append([]byte{0xff}, frame...)},
+		{"no-sync", false, frame},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := &testReadWriter{r: bytes.NewReader(nil)}
+			tr := serial.NewTransport(rw)
+			tr.Sync = tt.sync
+			if err := tr.WriteFrame(frame); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(rw.w.Bytes(), tt.want) {
+				t.Errorf("Transport.WriteFrame() = %x, want %x", rw.w.Bytes(), tt.want)
+			}
+		})
+	}
+}
+
+type oneByteReader struct {
+	r io.Reader
+}
+
+func (r *oneByteReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	return r.r.Read(p[:1])
+}
@@ -0,0 +1,121 @@
+package serial_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"syscall"
+	"testing"
+	"unsafe"
+
+	"github.com/oandrew/ipod/serial"
+)
+
+func openPty(t *testing.T) (master, slave *os.File) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("pty not available: %v", err)
+	}
+	var unlock int32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, master.Fd(), syscall.TIOCSPTLCK, uintptr(unsafe.Pointer(&unlock))); errno != 0 {
+		master.Close()
+		t.Skipf("pty unlock: %v", errno)
+	}
+	var n uint32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, master.Fd(), syscall.TIOCGPTN, uintptr(unsafe.Pointer(&n))); errno != 0 {
+		master.Close()
+		t.Skipf("pty number: %v", errno)
+	}
+	slave, err = os.OpenFile(fmt.Sprintf("/dev/pts/%d", n), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		t.Skipf("pty slave: %v", err)
+	}
+	return master, slave
+}
+
+func TestTransport_Pty(t *testing.T) {
+	master, slave := openPty(t)
+	defer master.Close()
+	defer slave.Close()
+
+	if err := serial.Configure(slave, serial.BaudRate57600); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	tr := serial.NewTransport(slave)
+
+	in := []byte{0xff, 0x55, 0x03, 0x01, 0x02, 0xfd, 0xfd}
+	if _, err := master.Write(in); err != nil {
+		t.Fatal(err)
+	}
+	frame, err := tr.ReadFrame()
+	if err != nil {
+		t.Fatalf("Transport.ReadFrame() error = %v", err)
+	}
+	if !reflect.DeepEqual(frame, in[1:]) {
+		t.Errorf("Transport.ReadFrame() = %x, want %x", frame, in[1:])
+	}
+
+	out := []byte{0x55, 0x02, 0x01, 0x02, 0xfb}
+	if err := tr.WriteFrame(out); err != nil {
+		t.Fatalf("Transport.WriteFrame() error = %v", err)
+	}
+	got := make([]byte, len(out)+1)
+	n := 0
+	for n < len(got) {
+		m, err := master.Read(got[n:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		n += m
+	}
+	if want := append([]byte{serial.SyncByte}, out...); !bytes.Equal(got, want) {
+		t.Errorf("written frame = %x, want %x", got, want)
+	}
+}
+
+func TestTransport_PtyNoise(t *testing.T) {
+	master, slave := openPty(t)
+	defer master.Close()
+	defer slave.Close()
+
+	if err := serial.Configure(slave, serial.BaudRate57600); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	tr := serial.NewTransport(slave)
+
+	// the false start's length covers the start of the packet
+	noise := []byte{0x01, 0x55, 0x03}
+	packet := []byte{0x55, 0x02, 0x01, 0x02, 0xfb}
+	in := append(append(noise, serial.SyncByte), packet...)
+	if _, err := master.Write(in); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.ReadFrame(); err != serial.ErrChecksum {
+		t.Fatalf("Transport.ReadFrame() error = %v, want %v", err, serial.ErrChecksum)
+	}
+	frame, err := tr.ReadFrame()
+	if err != nil {
+		t.Fatalf("Transport.ReadFrame() error = %v", err)
+	}
+	if !reflect.DeepEqual(frame, packet) {
+		t.Errorf("Transport.ReadFrame() = %x, want %x", frame, packet)
+	}
+}
+
+func TestConfigure_NotTty(t *testing.T) {
+	f, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer f.Close()
+	if err := serial.Configure(f, serial.BaudRate19200); err == nil {
+		t.Errorf("Configure() expected error for non-tty")
+	}
+	if err := serial.Configure(f, 1234); err == nil {
+		t.Errorf("Configure() expected error for bad baud rate")
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package serial
+
+import (
+	"errors"
+	"os"
+)
+
+// Configure is only supported on linux
+func Configure(f *os.File, baud int) error {
+	return errors.New("serial: tty configuration is not supported on this platform")
+}
@@ -0,0 +1,577 @@
+package ipod
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Handler handles incoming commands
+type Handler interface {
+	HandleCommand(cmd *Command, w CommandWriter) error
+}
+
+// HandlerFunc is an adapter to allow the use of
+// ordinary functions as a Handler
+type HandlerFunc func(cmd *Command, w CommandWriter) error
+
+// HandleCommand calls f(cmd, w)
+func (f HandlerFunc) HandleCommand(cmd *Command, w CommandWriter) error {
+	return f(cmd, w)
+}
+
+// Dir is the direction of the traffic
+type Dir uint8
+
+const (
+	// DirIn is accessory->ipod
+	DirIn Dir = iota
+	// DirOut is ipod->accessory
+	DirOut
+)
+
+func (d Dir) String() string {
+	switch d {
+	case DirIn:
+		return "in"
+	case DirOut:
+		return "out"
+	default:
+		return "unknown"
+	}
+}
+
+// Hooks are called by the Session on every decode/encode step
+// i.e. for logging. Any of them can be nil.
+// The frames and packets are only valid during the call,
+// the session reuses their buffers.
+type Hooks struct {
+	Frame   func(dir Dir, frame []byte, err error)
+	Packet  func(dir Dir, pkt []byte, err error)
+	Command func(dir Dir, cmd *Command, err error)
+	// HandlerError is called when a handler returns an error
+	HandlerError func(cmd *Command, err error)
+	// TransactionError is called when an incoming command
+	// has a duplicate or out-of-order transaction
+	TransactionError func(cmd *Command, err error)
+	// TeardownError is called when a teardown function returns an error
+	TeardownError func(lingoID uint8, err error)
+}
+
+func (h *Hooks) frame(dir Dir, frame []byte, err error) {
+	if h.Frame != nil {
+		h.Frame(dir, frame, err)
+	}
+}
+
+func (h *Hooks) packet(dir Dir, pkt []byte, err error) {
+	if h.Packet != nil {
+		h.Packet(dir, pkt, err)
+	}
+}
+
+func (h *Hooks) command(dir Dir, cmd *Command, err error) {
+	if h.Command != nil {
+		h.Command(dir, cmd, err)
+	}
+}
+
+func (h *Hooks) handlerError(cmd *Command, err error) {
+	if h.HandlerError != nil {
+		h.HandlerError(cmd, err)
+	}
+}
+
+func (h *Hooks) transactionError(cmd *Command, err error) {
+	if h.TransactionError != nil {
+		h.TransactionError(cmd, err)
+	}
+}
+
+func (h *Hooks) teardownError(lingoID uint8, err error) {
+	if h.TeardownError != nil {
+		h.TeardownError(lingoID, err)
+	}
+}
+
+// TeardownFunc undoes the changes a lingo made for the session,
+// i.e. restores the preferences set with RestoreOnExit
+type TeardownFunc func(w CommandWriter) error
+
+// FlushPolicy decides when the packets written to a Session are sent
+type FlushPolicy uint8
+
+const (
+	// FlushPacket sends every packet in a frame of its own
+	FlushPacket FlushPolicy = iota
+	// FlushFrame packs the packets written while an incoming frame
+	// is handled into as few frames as the transport allows.
+	// Packets written at other times are sent immediately.
+	FlushFrame
+)
+
+// Session runs the protocol over a FrameReadWriter:
+// it decodes incoming frames into commands, dispatches them
+// to the lingo handlers and encodes the responses.
+type Session struct {
+	frw       FrameReadWriter
+	handlers  map[uint8]Handler
+	fallback  Handler
+	teardowns map[uint8]TeardownFunc
+
+	Hooks Hooks
+	// Registry holds the lingos the session understands,
+	// DefaultRegistry if nil
+	Registry *Registry
+	// LingoVersion selects the payload layouts of a lingo by
+	// its protocol version, i.e. DeviceGeneral.LingoProtocolVersion.
+	// If nil all layouts are considered.
+	LingoVersion func(lingoID uint8) (major, minor uint8)
+	// CallTimeout is the time Call waits for a response,
+	// DefaultCallTimeout if zero
+	CallTimeout time.Duration
+	// FlushPolicy decides how outgoing packets are packed into frames
+	FlushPolicy FlushPolicy
+
+	calls    callTracker
+	requests requestTracker
+	trx      Transactions
+
+	smu    sync.Mutex
+	stats  PacketStats
+	maxIn  int
+	maxOut int
+
+	// the decoding state of handleFrame
+	inFrame   bytes.Reader
+	inPackets *PacketReader
+	inCmds    []*Command
+
+	wmu      sync.Mutex
+	frames   *FrameBuilder
+	batching bool
+	// outPacket is the buffer the outgoing packets are encoded in
+	outPacket []byte
+
+	qmu   sync.Mutex
+	queue *QueueWriter
+}
+
+// NewSession creates a Session on top of the frame transport frw.
+// The outgoing frames are limited to the MaxFrameSize of frw
+// if it implements FrameSizer.
+// The frames passed to the WriteFrame of frw are only valid during the call.
+func NewSession(frw FrameReadWriter) *Session {
+	s := &Session{
+		frw:       frw,
+		handlers:  make(map[uint8]Handler),
+		teardowns: make(map[uint8]TeardownFunc),
+		maxIn:     DefaultMaxPayload,
+	}
+	maxSize := 0
+	if fs, ok := frw.(FrameSizer); ok {
+		maxSize = fs.MaxFrameSize()
+	}
+	s.frames = NewFrameBuilder(sessionFrameWriter{s}, maxSize)
+	s.inPackets = NewPacketReader(&s.inFrame)
+	return s
+}
+
+// sessionFrameWriter writes the frames of the session to its transport
+type sessionFrameWriter struct {
+	s *Session
+}
+
+func (w sessionFrameWriter) WriteFrame(frame []byte) error {
+	err := w.s.frw.WriteFrame(frame)
+	w.s.Hooks.frame(DirOut, frame, err)
+	return err
+}
+
+// Handle registers the handler for the lingo
+func (s *Session) Handle(lingoID uint8, h Handler) {
+	s.handlers[lingoID] = h
+}
+
+// HandleFunc registers the handler function for the lingo
+func (s *Session) HandleFunc(lingoID uint8, f func(cmd *Command, w CommandWriter) error) {
+	s.Handle(lingoID, HandlerFunc(f))
+}
+
+// HandleDefault registers the handler for the lingos
+// that don't have a handler of their own
+func (s *Session) HandleDefault(h Handler) {
+	s.fallback = h
+}
+
+// HandleTeardown registers the teardown function of the lingo,
+// it is called when ServeContext returns, after the transport ends or its context is done
+func (s *Session) HandleTeardown(lingoID uint8, f TeardownFunc) {
+	s.teardowns[lingoID] = f
+}
+
+// Serve reads and handles frames until the transport returns io.EOF
+func (s *Session) Serve() error {
+	return s.ServeContext(context.Background())
+}
+
+type frameResult struct {
+	frame []byte
+	err   error
+}
+
+// ServeContext reads and handles frames until the transport
+// returns io.EOF or ctx is done, then it returns nil or ctx.Err().
+// A ReadFrame in progress is abandoned, it returns when the caller
+// closes the transport.
+// On either exit the requests in flight are cancelled and waited for,
+// the teardown functions are called, the queued commands are written
+// and the Queue is closed.
+func (s *Session) ServeContext(ctx context.Context) error {
+	reqCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	frames := make(chan frameResult)
+	next := make(chan struct{})
+	stop := make(chan struct{})
+	defer close(stop)
+	go s.readFrames(frames, next, stop)
+
+	err := s.serveFrames(ctx, reqCtx, frames, next)
+	cancel()
+	s.requests.wait()
+	s.teardown()
+	return err
+}
+
+// serveFrames handles the frames until there are no more or ctx is done
+func (s *Session) serveFrames(ctx, reqCtx context.Context, frames <-chan frameResult, next chan<- struct{}) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case f, ok := <-frames:
+			if !ok {
+				return nil
+			}
+			s.handleFrame(reqCtx, f.frame, f.err)
+			next <- struct{}{}
+		}
+	}
+}
+
+// readFrames passes the frames to ServeContext one at a time,
+// the transport may reuse the buffer of a frame for the next one
+func (s *Session) readFrames(frames chan<- frameResult, next, stop <-chan struct{}) {
+	defer close(frames)
+	for {
+		frame, err := s.frw.ReadFrame()
+		if err == io.EOF {
+			return
+		}
+		select {
+		case frames <- frameResult{frame, err}:
+		case <-stop:
+			return
+		}
+		select {
+		case <-next:
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (s *Session) handleFrame(ctx context.Context, inFrame []byte, err error) {
+	s.Hooks.frame(DirIn, inFrame, err)
+	if err != nil {
+		return
+	}
+
+	s.setBatching(s.FlushPolicy == FlushFrame)
+	cmds := s.readCommands(inFrame)
+	for i, cmd := range cmds {
+		cmds[i] = nil
+		if s.calls.deliver(cmd) {
+			continue
+		}
+		response := s.calls.response(cmd)
+		s.dispatch(ctx, cmd)
+		// checked after the handler, so that the transaction
+		// that starts IDPS is checked against the reset state
+		if !response {
+			s.checkTransaction(cmd)
+		}
+	}
+	s.setBatching(false)
+	s.Flush()
+}
+
+// teardown calls the teardown functions in the order of the lingo IDs
+// and writes the commands queued so far
+func (s *Session) teardown() {
+	var lingoIDs []int
+	for lingoID := range s.teardowns {
+		lingoIDs = append(lingoIDs, int(lingoID))
+	}
+	sort.Ints(lingoIDs)
+	for _, lingoID := range lingoIDs {
+		if err := s.teardowns[uint8(lingoID)](s); err != nil {
+			s.Hooks.teardownError(uint8(lingoID), err)
+		}
+	}
+	s.closeQueue()
+	s.Flush()
+}
+
+// Queue returns the queued writer of the session for sending commands
+// from other goroutines than the handlers, i.e.
+//
+//	ipod.Send(s.Queue(), &extremote.PlayStatusChangeNotification{...})
+//
+// The commands are written in order, serialized with the responses
+// of the handlers.
+func (s *Session) Queue() *QueueWriter {
+	s.qmu.Lock()
+	defer s.qmu.Unlock()
+	if s.queue == nil {
+		s.queue = NewQueueWriter(s, DefaultQueueSize)
+	}
+	return s.queue
+}
+
+func (s *Session) closeQueue() {
+	s.qmu.Lock()
+	q := s.queue
+	s.qmu.Unlock()
+	if q != nil {
+		q.Close()
+	}
+}
+
+func (s *Session) setBatching(batching bool) {
+	s.wmu.Lock()
+	s.batching = batching
+	s.wmu.Unlock()
+}
+
+// Flush sends the packets written but not sent yet
+func (s *Session) Flush() error {
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	return s.frames.Flush()
+}
+
+// readCommands decodes the frame into new Commands, the handlers may keep them.
+// The returned slice is reused by the next call.
+func (s *Session) readCommands(frame []byte) []*Command {
+	cmds := s.inCmds[:0]
+	s.inFrame.Reset(frame)
+	packetReader := s.inPackets
+	packetReader.Reset(&s.inFrame)
+	for {
+		inPacket, err := packetReader.ReadPacket()
+		if err == io.EOF {
+			break
+		}
+		s.Hooks.packet(DirIn, inPacket, err)
+		if err != nil {
+			continue
+		}
+		if max := s.MaxPayload(DirIn); max > 0 && len(inPacket) > max {
+			s.rejectPacket(inPacket, max)
+			continue
+		}
+
+		var inCmd Command
+		inCmdErr := s.LingoRegistry().UnmarshalCommand(&inCmd, inPacket, s.lookupContext)
+		s.Hooks.command(DirIn, &inCmd, inCmdErr)
+		cmds = append(cmds, &inCmd)
+	}
+	s.smu.Lock()
+	s.stats.Add(packetReader.Stats())
+	s.smu.Unlock()
+	s.inCmds = cmds
+	return cmds
+}
+
+// Stats returns the packet counters of the incoming frames
+func (s *Session) Stats() PacketStats {
+	s.smu.Lock()
+	defer s.smu.Unlock()
+	return s.stats
+}
+
+// MaxPayload returns the packet payload size limit of the direction,
+// zero if there is none.
+// The incoming limit is DefaultMaxPayload until set.
+func (s *Session) MaxPayload(dir Dir) int {
+	s.smu.Lock()
+	defer s.smu.Unlock()
+	if dir == DirIn {
+		return s.maxIn
+	}
+	return s.maxOut
+}
+
+// SetMaxPayload sets the packet payload size limit of the direction,
+// i.e. the max payload the accessory reports in its AccInfo token
+// for DirOut, zero removes the limit.
+//
+// Outgoing commands over the limit are split if the payload is a Splitter
+// or rejected with a *PayloadSizeError.
+// Incoming packets over the limit are answered with a bad parameter ACK.
+func (s *Session) SetMaxPayload(dir Dir, n int) {
+	s.smu.Lock()
+	defer s.smu.Unlock()
+	if dir == DirIn {
+		s.maxIn = n
+	} else {
+		s.maxOut = n
+	}
+}
+
+// rejectPacket answers an incoming packet over the size limit
+func (s *Session) rejectPacket(pkt []byte, max int) {
+	id, _, err := decodeLingoCmdID(pkt)
+	if err != nil {
+		return
+	}
+	req := &Command{ID: id}
+	// the transaction can't be told from the payload of an unknown layout
+	if n := req.ID.len(); s.trx.Mode() == TransactionModeOn && len(pkt) >= n+2 {
+		req.Transaction = NewTransaction(binary.BigEndian.Uint16(pkt[n:]))
+	}
+	s.Hooks.command(DirIn, req, &PayloadSizeError{ID: req.ID, Dir: DirIn, Size: len(pkt), Max: max})
+	if ack, ok := s.LingoRegistry().ACK(req, ACKStatusBadParameter); ok {
+		Respond(req, s, ack)
+	}
+}
+
+// LingoRegistry returns the registry the session uses
+// to decode and encode commands
+func (s *Session) LingoRegistry() *Registry {
+	if s.Registry != nil {
+		return s.Registry
+	}
+	return DefaultRegistry
+}
+
+func (s *Session) lookupContext(lingoID uint8) LookupContext {
+	ctx := LookupContext{
+		TransactionMode: s.trx.Mode(),
+	}
+	if s.LingoVersion != nil {
+		ctx.Version.Major, ctx.Version.Minor = s.LingoVersion(lingoID)
+	}
+	return ctx
+}
+
+func (s *Session) trackResponse(cmd *Command) {
+	s.calls.send(cmd)
+}
+
+// Transactions returns the transactions of the session's link
+func (s *Session) Transactions() *Transactions {
+	return &s.trx
+}
+
+func (s *Session) checkTransaction(cmd *Command) {
+	if cmd.Transaction == nil {
+		return
+	}
+	if err := s.trx.Check(*cmd.Transaction); err != nil {
+		s.Hooks.transactionError(cmd, err)
+	}
+}
+
+func (s *Session) dispatch(ctx context.Context, cmd *Command) {
+	h, ok := s.handlers[uint8(cmd.ID.LingoID())]
+	if !ok {
+		h = s.fallback
+	}
+	if h == nil {
+		return
+	}
+	w := &RequestWriter{s: s, req: cmd}
+	w.ctx, w.cancel = context.WithCancel(ctx)
+	s.requests.start(w)
+	defer s.requests.finish(w)
+	if err := h.HandleCommand(cmd, w); err != nil {
+		s.Hooks.handlerError(cmd, err)
+	}
+}
+
+// CancelCommand cancels the request in flight with the command ID
+// and the transaction, i.e. on the CancelCommand of the General lingo.
+// The transaction is ignored if the request has none.
+// The context of the request is done and its later responses are dropped.
+// It reports whether a request was cancelled.
+func (s *Session) CancelCommand(id LingoCmdID, trx Transaction) bool {
+	return s.requests.cancel(id, trx)
+}
+
+// WriteCommand encodes the command and writes it in a frame
+// according to the FlushPolicy.
+// It is safe to call WriteCommand from multiple goroutines.
+func (s *Session) WriteCommand(cmd *Command) error {
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+
+	outPacket, err := s.marshal(cmd)
+	if max := s.MaxPayload(DirOut); err == nil && max > 0 && len(outPacket) > max {
+		return s.writeSplit(cmd, len(outPacket), max)
+	}
+	return s.writePacket(cmd, outPacket, err)
+}
+
+// marshal encodes the command into the outPacket buffer,
+// the packet is valid until the next call
+func (s *Session) marshal(cmd *Command) ([]byte, error) {
+	pkt, err := cmd.AppendBinary(s.outPacket[:0])
+	if err != nil {
+		return nil, err
+	}
+	s.outPacket = pkt
+	return pkt, nil
+}
+
+func (s *Session) writePacket(cmd *Command, outPacket []byte, err error) error {
+	s.Hooks.command(DirOut, cmd, nil)
+	s.Hooks.packet(DirOut, outPacket, err)
+	if err != nil {
+		return err
+	}
+	if err := s.frames.WritePacket(outPacket); err != nil {
+		return err
+	}
+	if s.batching {
+		return nil
+	}
+	return s.frames.Flush()
+}
+
+// writeSplit writes the multi-packet form of a command over the size limit
+func (s *Session) writeSplit(cmd *Command, size, max int) error {
+	sizeErr := &PayloadSizeError{ID: cmd.ID, Dir: DirOut, Size: size, Max: max}
+	cmds, err := splitCommand(cmd, max)
+	if err != nil {
+		s.Hooks.command(DirOut, cmd, sizeErr)
+		return sizeErr
+	}
+	for _, part := range cmds {
+		outPacket, err := s.marshal(part)
+		if err == nil && len(outPacket) > max {
+			sizeErr.Size = len(outPacket)
+			err = sizeErr
+		}
+		if err := s.writePacket(part, outPacket, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
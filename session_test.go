@@ -0,0 +1,509 @@
+package ipod_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/oandrew/ipod"
+	"github.com/oandrew/ipod/lingo-general"
+)
+
+type testFrameReadWriter struct {
+	in  [][]byte
+	out [][]byte
+	// eof delays io.EOF until it is closed if set
+	eof chan struct{}
+}
+
+func (rw *testFrameReadWriter) ReadFrame() ([]byte, error) {
+	if len(rw.in) == 0 {
+		if rw.eof != nil {
+			<-rw.eof
+		}
+		return nil, io.EOF
+	}
+	frame := rw.in[0]
+	rw.in = rw.in[1:]
+	return frame, nil
+}
+
+func (rw *testFrameReadWriter) WriteFrame(data []byte) error {
+	rw.out = append(rw.out, append([]byte(nil), data...))
+	return nil
+}
+
+const testSessionLingoID = 0xab
+
+type SessionRequest struct {
+	V uint8
+}
+
+type SessionResponse struct {
+	V uint8
+}
+
+type SessionACK struct {
+	Status  uint8
+	CmdID   uint8
+	MaxWait uint32
+}
+
+func (s *SessionACK) ACKStatus() ipod.ACKStatus {
+	return ipod.ACKStatus(s.Status)
+}
+
+func (s *SessionACK) ACKCmdID() uint16 {
+	return uint16(s.CmdID)
+}
+
+func (s *SessionACK) ACKMaxWait() time.Duration {
+	return time.Duration(s.MaxWait) * time.Millisecond
+}
+
+// SessionChunks has a multi-packet form
+type SessionChunks struct {
+	Index uint8
+	Data  []byte `ipod:"rest"`
+}
+
+func (s *SessionChunks) Split(max int) ([]interface{}, error) {
+	var parts []interface{}
+	for i := 0; i < len(s.Data); i += max - 1 {
+		end := i + max - 1
+		if end > len(s.Data) {
+			end = len(s.Data)
+		}
+		parts = append(parts, &SessionChunks{Index: uint8(len(parts)), Data: s.Data[i:end]})
+	}
+	return parts, nil
+}
+
+var testSessionLingos struct {
+	SessionRequest  `id:"0x01"`
+	SessionResponse `id:"0x02"`
+	SessionACK      `id:"0x03"`
+	SessionChunks   `id:"0x04"`
+}
+
+func init() {
+	ipod.RegisterLingos(testSessionLingoID, testSessionLingos)
+	ipod.RegisterACK(testSessionLingoID, func(req *ipod.Command, status ipod.ACKStatus) interface{} {
+		return &SessionACK{Status: uint8(status), CmdID: uint8(req.ID.CmdID())}
+	})
+}
+
+func buildFrame(t testing.TB, cmds ...*ipod.Command) []byte {
+	frame := bytes.Buffer{}
+	pw := ipod.NewPacketWriter(&frame)
+	for _, cmd := range cmds {
+		pkt, err := cmd.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		pw.WritePacket(pkt)
+	}
+	return frame.Bytes()
+}
+
+func sessionReq(v uint8) *ipod.Command {
+	return &ipod.Command{
+		ID:          ipod.NewLingoCmdID(testSessionLingoID, 0x01),
+		Transaction: ipod.NewTransaction(uint16(v)),
+		Payload:     &SessionRequest{V: v},
+	}
+}
+
+func sessionResp(v uint8) *ipod.Command {
+	return &ipod.Command{
+		ID:          ipod.NewLingoCmdID(testSessionLingoID, 0x02),
+		Transaction: ipod.NewTransaction(uint16(v)),
+		Payload:     &SessionResponse{V: v + 1},
+	}
+}
+
+func respondSessionRequest(cmd *ipod.Command, w ipod.CommandWriter) error {
+	r := cmd.Payload.(*SessionRequest)
+	ipod.Respond(cmd, w, &SessionResponse{V: r.V + 1})
+	return nil
+}
+
+func TestSession_Serve(t *testing.T) {
+	frw := &testFrameReadWriter{
+		in: [][]byte{
+			buildFrame(t, sessionReq(1)),
+			buildFrame(t, sessionReq(2), sessionReq(3)),
+			// unknown lingo goes to the default handler
+			{0x55, 0x02, 0xee, 0x01, 0x0f},
+		},
+	}
+
+	var hookCmds []ipod.Dir
+	var unhandled []ipod.LingoCmdID
+	s := ipod.NewSession(frw)
+	s.Hooks.Command = func(dir ipod.Dir, cmd *ipod.Command, err error) {
+		hookCmds = append(hookCmds, dir)
+	}
+	s.HandleFunc(testSessionLingoID, respondSessionRequest)
+	s.HandleDefault(ipod.HandlerFunc(func(cmd *ipod.Command, w ipod.CommandWriter) error {
+		unhandled = append(unhandled, cmd.ID)
+		return nil
+	}))
+
+	if err := s.Serve(); err != nil {
+		t.Fatalf("Session.Serve() error = %v", err)
+	}
+
+	want := [][]byte{
+		buildFrame(t, sessionResp(1)),
+		buildFrame(t, sessionResp(2)),
+		buildFrame(t, sessionResp(3)),
+	}
+	if !reflect.DeepEqual(frw.out, want) {
+		t.Errorf("Session.Serve() frames = %x, want %x", frw.out, want)
+	}
+	if !reflect.DeepEqual(unhandled, []ipod.LingoCmdID{ipod.NewLingoCmdID(0xee, 0x01)}) {
+		t.Errorf("Session.Serve() unhandled = %v", unhandled)
+	}
+	wantDirs := []ipod.Dir{ipod.DirIn, ipod.DirOut, ipod.DirIn, ipod.DirIn, ipod.DirOut, ipod.DirOut, ipod.DirIn}
+	if !reflect.DeepEqual(hookCmds, wantDirs) {
+		t.Errorf("Session.Serve() command hooks = %v, want %v", hookCmds, wantDirs)
+	}
+}
+
+type sizedFrameReadWriter struct {
+	testFrameReadWriter
+	maxSize int
+}
+
+func (rw *sizedFrameReadWriter) MaxFrameSize() int {
+	return rw.maxSize
+}
+
+func TestSession_FlushFrame(t *testing.T) {
+	in := [][]byte{
+		buildFrame(t, sessionReq(1)),
+		buildFrame(t, sessionReq(2), sessionReq(3), sessionReq(4)),
+	}
+	respSize := len(buildFrame(t, sessionResp(1)))
+
+	tests := []struct {
+		name    string
+		policy  ipod.FlushPolicy
+		maxSize int
+		want    [][]byte
+	}{
+		{"packet", ipod.FlushPacket, 0, [][]byte{
+			buildFrame(t, sessionResp(1)),
+			buildFrame(t, sessionResp(2)),
+			buildFrame(t, sessionResp(3)),
+			buildFrame(t, sessionResp(4)),
+		}},
+		{"frame", ipod.FlushFrame, 0, [][]byte{
+			buildFrame(t, sessionResp(1)),
+			buildFrame(t, sessionResp(2), sessionResp(3), sessionResp(4)),
+		}},
+		{"frame-max-size", ipod.FlushFrame, 2 * respSize, [][]byte{
+			buildFrame(t, sessionResp(1)),
+			buildFrame(t, sessionResp(2), sessionResp(3)),
+			buildFrame(t, sessionResp(4)),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frw := &sizedFrameReadWriter{maxSize: tt.maxSize}
+			frw.in = append([][]byte(nil), in...)
+			s := ipod.NewSession(frw)
+			s.FlushPolicy = tt.policy
+			s.HandleFunc(testSessionLingoID, respondSessionRequest)
+			if err := s.Serve(); err != nil {
+				t.Fatalf("Session.Serve() error = %v", err)
+			}
+			if !reflect.DeepEqual(frw.out, tt.want) {
+				t.Errorf("Session.Serve() frames = %x, want %x", frw.out, tt.want)
+			}
+		})
+	}
+}
+
+func TestSession_MaxPayload(t *testing.T) {
+	chunks := func(v uint8, index uint8, data ...byte) *ipod.Command {
+		return &ipod.Command{
+			ID:          ipod.NewLingoCmdID(testSessionLingoID, 0x04),
+			Transaction: ipod.NewTransaction(uint16(v)),
+			Payload:     &SessionChunks{Index: index, Data: data},
+		}
+	}
+	ack := &ipod.Command{
+		ID:          ipod.NewLingoCmdID(testSessionLingoID, 0x03),
+		Transaction: ipod.NewTransaction(1),
+		Payload:     &SessionACK{Status: uint8(ipod.ACKStatusBadParameter), CmdID: 0x01},
+	}
+
+	tests := []struct {
+		name    string
+		maxIn   int
+		maxOut  int
+		resp    interface{}
+		want    [][]byte
+		wantErr bool
+	}{
+		{"fits", ipod.DefaultMaxPayload, 5, &SessionResponse{V: 2}, [][]byte{buildFrame(t, sessionResp(1))}, false},
+		{"split", ipod.DefaultMaxPayload, 9, &SessionChunks{Data: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}}, [][]byte{
+			buildFrame(t, chunks(1, 0, 1, 2, 3, 4)),
+			buildFrame(t, chunks(1, 1, 5, 6, 7, 8)),
+			buildFrame(t, chunks(1, 2, 9, 10)),
+		}, false},
+		{"too-large", ipod.DefaultMaxPayload, 4, &SessionResponse{V: 2}, nil, true},
+		{"incoming-too-large", 4, 0, &SessionResponse{V: 2}, [][]byte{buildFrame(t, ack)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frw := &testFrameReadWriter{in: [][]byte{buildFrame(t, sessionReq(1))}}
+			s := ipod.NewSession(frw)
+			s.Transactions().SetMode(ipod.TransactionModeOn)
+			s.SetMaxPayload(ipod.DirIn, tt.maxIn)
+			s.SetMaxPayload(ipod.DirOut, tt.maxOut)
+			var handlerErr error
+			s.HandleFunc(testSessionLingoID, func(cmd *ipod.Command, w ipod.CommandWriter) error {
+				resp, err := s.LingoRegistry().BuildCommand(tt.resp)
+				if err != nil {
+					return err
+				}
+				resp.Transaction = cmd.Transaction.Copy()
+				handlerErr = w.WriteCommand(resp)
+				return nil
+			})
+			if err := s.Serve(); err != nil {
+				t.Fatalf("Session.Serve() error = %v", err)
+			}
+			if _, ok := handlerErr.(*ipod.PayloadSizeError); ok != tt.wantErr {
+				t.Errorf("Session.WriteCommand() error = %v, wantErr %v", handlerErr, tt.wantErr)
+			}
+			if !reflect.DeepEqual(frw.out, tt.want) {
+				t.Errorf("Session.Serve() frames = %x, want %x", frw.out, tt.want)
+			}
+		})
+	}
+}
+
+func TestSession_MaxPayloadNegotiated(t *testing.T) {
+	data := bytes.Repeat([]byte{0xda}, 100)
+	oversize := &ipod.Command{
+		ID:          ipod.NewLingoCmdID(testSessionLingoID, 0x04),
+		Transaction: ipod.NewTransaction(5),
+		Payload:     &SessionChunks{Data: bytes.Repeat([]byte{0xda}, 101)},
+	}
+	frw := &testFrameReadWriter{in: append(maxPayloadFrames(t, 0x40),
+		buildFrame(t, sessionReq(3)),
+		buildFrame(t, sessionReq(4)),
+		buildFrame(t, oversize),
+	)}
+	s := ipod.NewSession(frw)
+	s.Transactions().SetMode(ipod.TransactionModeOn)
+	dev := &maxPayloadDevice{max: 100}
+	s.HandleFunc(general.LingoGeneralID, func(cmd *ipod.Command, w ipod.CommandWriter) error {
+		return general.HandleGeneral(cmd, w, dev)
+	})
+	var handlerErr error
+	s.HandleFunc(testSessionLingoID, func(cmd *ipod.Command, w ipod.CommandWriter) error {
+		var resp *ipod.Command
+		var err error
+		switch cmd.Payload.(*SessionRequest).V {
+		case 3:
+			resp, err = s.LingoRegistry().BuildCommand(&SessionChunks{Data: data})
+		case 4:
+			// a payload without a multi-packet form
+			resp, err = s.LingoRegistry().BuildCommand(&general.ReturniPodName{Name: data})
+		}
+		if err != nil {
+			return err
+		}
+		resp.Transaction = cmd.Transaction.Copy()
+		if err := w.WriteCommand(resp); err != nil {
+			handlerErr = err
+		}
+		return nil
+	})
+	var inErr error
+	s.Hooks.Command = func(dir ipod.Dir, cmd *ipod.Command, err error) {
+		if dir == ipod.DirIn && err != nil {
+			inErr = err
+		}
+	}
+	if err := s.Serve(); err != nil {
+		t.Fatalf("Session.Serve() error = %v", err)
+	}
+
+	var chunks []byte
+	var acks []*SessionACK
+	for _, frame := range frw.out {
+		pr := ipod.NewPacketReader(bytes.NewReader(frame))
+		for {
+			pkt, err := pr.ReadPacket()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pkt) > 0x40 {
+				t.Errorf("Session.Serve() wrote a %d byte payload, want at most %d", len(pkt), 0x40)
+			}
+			var cmd ipod.Command
+			if err := cmd.UnmarshalBinary(pkt); err != nil {
+				t.Fatal(err)
+			}
+			switch p := cmd.Payload.(type) {
+			case *SessionChunks:
+				chunks = append(chunks, p.Data...)
+			case *SessionACK:
+				acks = append(acks, p)
+			case *general.ReturniPodName:
+				t.Errorf("Session.Serve() wrote the oversize ReturniPodName")
+			}
+		}
+	}
+	if !bytes.Equal(chunks, data) {
+		t.Errorf("Session.Serve() chunks = %x, want %x", chunks, data)
+	}
+	if err, ok := handlerErr.(*ipod.PayloadSizeError); !ok || err.Dir != ipod.DirOut || err.Max != 0x40 {
+		t.Errorf("Session.WriteCommand() error = %v, want outgoing *PayloadSizeError", handlerErr)
+	}
+	if err, ok := inErr.(*ipod.PayloadSizeError); !ok || err.Dir != ipod.DirIn || err.Max != 100 {
+		t.Errorf("Hooks.Command error = %v, want incoming *PayloadSizeError", inErr)
+	}
+	if len(acks) != 1 || acks[0].Status != uint8(ipod.ACKStatusBadParameter) || acks[0].CmdID != 0x04 {
+		t.Errorf("Session.Serve() ACKs = %+v, want a bad parameter ACK of the oversize packet", acks)
+	}
+}
+
+type blockingFrameReadWriter struct {
+	in  chan []byte
+	out [][]byte
+}
+
+func (rw *blockingFrameReadWriter) ReadFrame() ([]byte, error) {
+	frame, ok := <-rw.in
+	if !ok {
+		return nil, io.EOF
+	}
+	return frame, nil
+}
+
+func (rw *blockingFrameReadWriter) WriteFrame(data []byte) error {
+	rw.out = append(rw.out, append([]byte(nil), data...))
+	return nil
+}
+
+func TestSession_ServeEOF(t *testing.T) {
+	frw := &testFrameReadWriter{in: [][]byte{buildFrame(t, sessionReq(1))}}
+	s := ipod.NewSession(frw)
+
+	var ctxErr error
+	s.HandleFunc(testSessionLingoID, func(cmd *ipod.Command, w ipod.CommandWriter) error {
+		w.(*ipod.RequestWriter).Go(func(ctx context.Context, w ipod.CommandWriter) error {
+			<-ctx.Done()
+			ctxErr = ctx.Err()
+			return nil
+		})
+		return nil
+	})
+	var teardowns int
+	s.HandleTeardown(testSessionLingoID, func(w ipod.CommandWriter) error {
+		teardowns++
+		return w.WriteCommand(sessionResp(2))
+	})
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- s.Serve()
+	}()
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Fatalf("Session.Serve() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Session.Serve() did not cancel the request in flight at EOF")
+	}
+	if ctxErr != context.Canceled {
+		t.Errorf("request context error = %v, want %v", ctxErr, context.Canceled)
+	}
+	if teardowns != 1 {
+		t.Errorf("Session.Serve() called the teardown %d times, want 1", teardowns)
+	}
+	if want := [][]byte{buildFrame(t, sessionResp(2))}; !reflect.DeepEqual(frw.out, want) {
+		t.Errorf("Session.Serve() frames = %x, want %x", frw.out, want)
+	}
+}
+
+func TestSession_ServeContext(t *testing.T) {
+	frw := &blockingFrameReadWriter{in: make(chan []byte)}
+	defer close(frw.in)
+
+	s := ipod.NewSession(frw)
+	handled := make(chan struct{})
+	s.HandleFunc(testSessionLingoID, func(cmd *ipod.Command, w ipod.CommandWriter) error {
+		respondSessionRequest(cmd, w)
+		close(handled)
+		return nil
+	})
+
+	var teardowns []uint8
+	var teardownErrs []uint8
+	teardownErr := errors.New("teardown")
+	s.Hooks.TeardownError = func(lingoID uint8, err error) {
+		if err == teardownErr {
+			teardownErrs = append(teardownErrs, lingoID)
+		}
+	}
+	s.HandleTeardown(testSessionLingoID, func(w ipod.CommandWriter) error {
+		teardowns = append(teardowns, testSessionLingoID)
+		// written by the queue after the teardown functions return
+		return s.Queue().WriteCommand(sessionResp(3))
+	})
+	s.HandleTeardown(0x00, func(w ipod.CommandWriter) error {
+		teardowns = append(teardowns, 0x00)
+		return teardownErr
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		errc <- s.ServeContext(ctx)
+	}()
+	frw.in <- buildFrame(t, sessionReq(1))
+	<-handled
+	cancel()
+
+	select {
+	case err := <-errc:
+		if err != context.Canceled {
+			t.Errorf("Session.ServeContext() error = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Session.ServeContext() did not return")
+	}
+
+	want := [][]byte{
+		buildFrame(t, sessionResp(1)),
+		buildFrame(t, sessionResp(3)),
+	}
+	if !reflect.DeepEqual(frw.out, want) {
+		t.Errorf("Session.ServeContext() frames = %x, want %x", frw.out, want)
+	}
+	if !reflect.DeepEqual(teardowns, []uint8{0x00, testSessionLingoID}) {
+		t.Errorf("Session.ServeContext() teardowns = %v", teardowns)
+	}
+	if !reflect.DeepEqual(teardownErrs, []uint8{0x00}) {
+		t.Errorf("Session.ServeContext() teardown errors = %v", teardownErrs)
+	}
+	if err := s.Queue().WriteCommand(sessionResp(4)); err != ipod.ErrQueueClosed {
+		t.Errorf("Session.Queue() after ServeContext error = %v, want %v", err, ipod.ErrQueueClosed)
+	}
+}
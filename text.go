@@ -0,0 +1,261 @@
+package ipod
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParseCommand parses the text form of a command using the DefaultRegistry
+func ParseCommand(text string) (*Command, error) {
+	return DefaultRegistry.ParseCommand(text)
+}
+
+// ParseCommand parses the text form of a command: the payload name
+// as returned by PayloadName followed by Field=Value pairs, i.e.
+//
+//	extremote.PlayControl Cmd=Toggle
+//	general.RequestLingoProtocolVersion Lingo=0x04
+//	general.SetEventNotification EventMask=EventFlowControl|EventDatabaseChanged trx=2
+//
+// Fields that are not set are zero. Values are
+//
+//	numbers            5, 0x05
+//	enum and mask names, joined with "|"
+//	                   ACKStatusSuccess, Success, EventFlowControl|EventDatabaseChanged
+//	booleans           true, false
+//	quoted strings     "ipod", NUL-terminated in untagged byte slices,
+//	                   padded into byte arrays
+//	hex bytes          0102ff
+//	JSON               for nested structs, tokens and other slices
+//
+// Enum names can drop the prefix they share with the type, so Toggle
+// stands for PlayControlToggle. The trx key sets the transaction.
+// A # starts a comment that runs to the end of the text.
+func (r *Registry) ParseCommand(text string) (*Command, error) {
+	tokens, err := splitCommandText(text)
+	if err != nil {
+		return nil, fmt.Errorf("ipod.Command parse: %v", err)
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("ipod.Command parse: empty command")
+	}
+
+	id, payload, ok := r.LookupName(tokens[0])
+	if !ok {
+		return nil, fmt.Errorf("ipod.Command parse: unknown command %s", tokens[0])
+	}
+	cmd := &Command{ID: id, Payload: payload}
+	v := reflect.ValueOf(payload).Elem()
+	for _, tok := range tokens[1:] {
+		i := strings.IndexByte(tok, '=')
+		if i <= 0 {
+			return nil, fmt.Errorf("ipod.Command parse: %s: expected Field=Value, got %q", tokens[0], tok)
+		}
+		key, val := tok[:i], tok[i+1:]
+		if key == "trx" {
+			trx, err := strconv.ParseUint(val, 0, 16)
+			if err != nil {
+				return nil, fmt.Errorf("ipod.Command parse: trx: %v", err)
+			}
+			cmd.Transaction = NewTransaction(uint16(trx))
+			continue
+		}
+		f, ok := v.Type().FieldByName(key)
+		if !ok || f.PkgPath != "" || len(f.Index) != 1 {
+			return nil, fmt.Errorf("ipod.Command parse: %s has no field %s", tokens[0], key)
+		}
+		opts, err := parseFieldTag(f.Tag.Get("ipod"))
+		if err != nil {
+			return nil, fmt.Errorf("ipod.Command parse: %s.%s: %v", tokens[0], key, err)
+		}
+		if err := parseValue(v.Field(f.Index[0]), val, opts); err != nil {
+			return nil, fmt.Errorf("ipod.Command parse: %s.%s: %v", tokens[0], key, err)
+		}
+	}
+	return cmd, nil
+}
+
+// splitCommandText splits text into tokens separated by spaces
+// keeping quoted strings and JSON brackets whole
+func splitCommandText(text string) ([]string, error) {
+	var tokens []string
+	var tok strings.Builder
+	depth := 0
+	quoted, escaped := false, false
+	flush := func() {
+		if tok.Len() > 0 {
+			tokens = append(tokens, tok.String())
+			tok.Reset()
+		}
+	}
+	for _, c := range text {
+		switch {
+		case quoted:
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				quoted = false
+			}
+		case c == '"':
+			quoted = true
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unexpected %q", c)
+			}
+		case depth > 0:
+		case c == '#':
+			flush()
+			return tokens, nil
+		case unicode.IsSpace(c):
+			flush()
+			continue
+		}
+		tok.WriteRune(c)
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated string")
+	}
+	if depth > 0 {
+		return nil, fmt.Errorf("unterminated brackets")
+	}
+	flush()
+	return tokens, nil
+}
+
+func parseValue(v reflect.Value, s string, opts fieldOpts) error {
+	switch v.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := parseUint(v.Type(), s)
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return parseBytes(v, s, opts)
+		}
+		return parseJSONValue(v, s)
+	default:
+		return parseJSONValue(v, s)
+	}
+	return nil
+}
+
+// parseUint parses a number or the "|" separated names
+// of the values of the enum or mask type t
+func parseUint(t reflect.Type, s string) (uint64, error) {
+	var n uint64
+	for _, part := range strings.Split(s, "|") {
+		if x, err := strconv.ParseUint(part, 0, t.Bits()); err == nil {
+			n |= x
+			continue
+		}
+		x, err := lookupValueName(t, part)
+		if err != nil {
+			return 0, err
+		}
+		n |= x
+	}
+	return n, nil
+}
+
+// lookupValueName finds the value of t whose String is name
+// or the only one that ends with name.
+// The values tried are the first 256 and the single bits.
+func lookupValueName(t reflect.Type, name string) (uint64, error) {
+	if !t.Implements(stringerType) || name == "" {
+		return 0, fmt.Errorf("invalid value %q", name)
+	}
+	v := reflect.New(t).Elem()
+	var found []uint64
+	try := func(x uint64) bool {
+		v.SetUint(x)
+		s := v.Interface().(fmt.Stringer).String()
+		if s == name {
+			return true
+		}
+		if len(s) > len(name) && strings.HasSuffix(s, name) {
+			found = append(found, x)
+		}
+		return false
+	}
+	for x := uint64(0); x < 256; x++ {
+		if try(x) {
+			return x, nil
+		}
+	}
+	for bit := 8; bit < t.Bits(); bit++ {
+		if try(1 << uint(bit)) {
+			return 1 << uint(bit), nil
+		}
+	}
+	switch len(found) {
+	case 0:
+		return 0, fmt.Errorf("unknown %s %q", t.Name(), name)
+	case 1:
+		return found[0], nil
+	default:
+		return 0, fmt.Errorf("ambiguous %s %q", t.Name(), name)
+	}
+}
+
+// parseBytes parses a quoted string or hex bytes.
+// An untagged byte slice holds a string with its NUL terminator,
+// the tagged ones are encoded as they are.
+func parseBytes(v reflect.Value, s string, opts fieldOpts) error {
+	var data []byte
+	if strings.HasPrefix(s, `"`) {
+		str, err := strconv.Unquote(s)
+		if err != nil {
+			return err
+		}
+		data = []byte(str)
+		if v.Kind() == reflect.Slice && opts == (fieldOpts{}) {
+			data = append(data, 0x00)
+		}
+	} else {
+		var err error
+		if data, err = hex.DecodeString(strings.TrimPrefix(s, "0x")); err != nil {
+			return err
+		}
+	}
+	if v.Kind() == reflect.Array {
+		if len(data) > v.Len() {
+			return fmt.Errorf("%d bytes do not fit in %d", len(data), v.Len())
+		}
+		reflect.Copy(v, reflect.ValueOf(data))
+		return nil
+	}
+	v.SetBytes(data)
+	return nil
+}
+
+func parseJSONValue(v reflect.Value, s string) error {
+	if err := json.Unmarshal([]byte(s), v.Addr().Interface()); err != nil {
+		return fmt.Errorf("json: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package ipod_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/oandrew/ipod"
+	"github.com/oandrew/ipod/lingo-extremote"
+	"github.com/oandrew/ipod/lingo-general"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want *ipod.Command
+	}{
+		{"enum-short", "extremote.PlayControl Cmd=Toggle", &ipod.Command{
+			ID:      ipod.NewLingoCmdID(0x04, 0x0029),
+			Payload: &extremote.PlayControl{Cmd: extremote.PlayControlToggle},
+		}},
+		{"enum-full", "extremote.PlayControl Cmd=PlayControlNext", &ipod.Command{
+			ID:      ipod.NewLingoCmdID(0x04, 0x0029),
+			Payload: &extremote.PlayControl{Cmd: extremote.PlayControlNext},
+		}},
+		{"hex", "general.RequestLingoProtocolVersion Lingo=0x04", &ipod.Command{
+			ID:      ipod.NewLingoCmdID(0x00, 0x0F),
+			Payload: &general.RequestLingoProtocolVersion{Lingo: 0x04},
+		}},
+		{"empty-payload", "  general.RequestIdentify  ", &ipod.Command{
+			ID:      ipod.NewLingoCmdID(0x00, 0x00),
+			Payload: &general.RequestIdentify{},
+		}},
+		{"mask", "general.SetEventNotification EventMask=EventFlowControl|EventDatabaseChanged|0x01 trx=2", &ipod.Command{
+			ID:          ipod.NewLingoCmdID(0x00, 0x49),
+			Transaction: ipod.NewTransaction(2),
+			Payload:     &general.SetEventNotification{EventMask: general.EventMask(general.EventFlowControl | general.EventDatabaseChanged | 1)},
+		}},
+		{"bool", "extremote.SetPlayStatusChangeNotificationShort Enabled=true # enable", &ipod.Command{
+			ID:      ipod.NewLingoCmdID(0x04, 0x0026),
+			Payload: &extremote.SetPlayStatusChangeNotificationShort{Enabled: true},
+		}},
+		{"cstring", `general.ReturniPodName Name="my ipod"`, &ipod.Command{
+			ID:      ipod.NewLingoCmdID(0x00, 0x08),
+			Payload: &general.ReturniPodName{Name: []byte("my ipod\x00")},
+		}},
+		{"rest-string", `general.DevDataTransfer SessionID=1 Data="ab"`, &ipod.Command{
+			ID:      ipod.NewLingoCmdID(0x00, 0x42),
+			Payload: &general.DevDataTransfer{SessionID: 1, Data: []byte("ab")},
+		}},
+		{"array-string", `extremote.ReturnCategorizedDatabaseRecord RecordCategoryIndex=1 String="ab"`, &ipod.Command{
+			ID:      ipod.NewLingoCmdID(0x04, 0x001B),
+			Payload: &extremote.ReturnCategorizedDatabaseRecord{RecordCategoryIndex: 1, String: [16]byte{'a', 'b'}},
+		}},
+		{"array-hex", "general.GetDevAuthenticationSignatureV1 Challenge=0102 Counter=3", &ipod.Command{
+			ID:      ipod.NewLingoCmdID(0x00, 0x17),
+			Payload: &general.GetDevAuthenticationSignatureV1{Challenge: [16]byte{0x01, 0x02}, Counter: 3},
+		}},
+		{"json", `general.SetFIDTokenValues NumFIDTokenValues=1 FIDTokenValues=[{"Len": 4, "FIDType": 2, "FIDSubtype": 0, "Token": "AQI="}]`, &ipod.Command{
+			ID: ipod.NewLingoCmdID(0x00, 0x39),
+			Payload: &general.SetFIDTokenValues{
+				NumFIDTokenValues: 1,
+				FIDTokenValues:    []general.FIDTokenValue{{Len: 4, FIDType: 0x02, Token: []byte{0x01, 0x02}}},
+			},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ipod.ParseCommand(tt.text)
+			if err != nil {
+				t.Fatalf("ParseCommand() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCommand() = %s, want %s", ipod.FormatCommand(got), ipod.FormatCommand(tt.want))
+			}
+		})
+	}
+}
+
+func TestParseCommand_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"empty", "  # nothing"},
+		{"unknown-command", "general.NoSuchCommand"},
+		{"unknown-field", "extremote.PlayControl Mode=1"},
+		{"no-value", "extremote.PlayControl Cmd"},
+		{"unknown-name", "extremote.PlayControl Cmd=Dance"},
+		{"ambiguous-name", "extremote.PlayControl Cmd=Track"},
+		{"overflow", "general.RequestLingoProtocolVersion Lingo=256"},
+		{"no-names", "general.RequestLingoProtocolVersion Lingo=Audio"},
+		{"long-array", `extremote.ReturnCategorizedDatabaseRecord String="0123456789abcdefg"`},
+		{"unterminated-string", `general.ReturniPodName Name="ipod`},
+		{"unterminated-json", `general.SetFIDTokenValues FIDTokenValues=[{}`},
+		{"bad-trx", "general.RequestIdentify trx=x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ipod.ParseCommand(tt.text); err == nil {
+				t.Errorf("ParseCommand(%q) error = nil, want an error", tt.text)
+			}
+		})
+	}
+}
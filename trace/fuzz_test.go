@@ -0,0 +1,41 @@
+//go:build go1.18
+// +build go1.18
+
+package trace_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/oandrew/ipod/trace"
+)
+
+func FuzzReader(f *testing.F) {
+	f.Add([]byte("< 01 02 03\n"))
+	f.Add([]byte("> 01 02 03\n"))
+	f.Add([]byte("? 01 02 03\n"))
+	f.Add([]byte(">\n"))
+	f.Add([]byte("< 55 03 01 02 FD FD\n\n> 55 02 01 02 FB\n"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		r := trace.NewReader(bytes.NewReader(data))
+		for i := 0; i <= len(data); i++ {
+			var m trace.Msg
+			if err := r.ReadMsg(&m); err != nil {
+				return
+			}
+			text, err := m.MarshalText()
+			if err != nil {
+				t.Fatalf("Msg.MarshalText() error = %v", err)
+			}
+			var got trace.Msg
+			if err := got.UnmarshalText(text); err != nil {
+				t.Fatalf("Msg.UnmarshalText(%q) error = %v", text, err)
+			}
+			if got.Dir != m.Dir || !reflect.DeepEqual(got.Data, m.Data) {
+				t.Fatalf("Msg.UnmarshalText(%q) = %v, want %v", text, got, m)
+			}
+		}
+		t.Fatalf("ReadMsg() returned more messages than lines")
+	})
+}
@@ -0,0 +1,130 @@
+package ipod
+
+import (
+	"fmt"
+	"sync"
+)
+
+// TransactionError describes a duplicate or out-of-order
+// transaction ID received from the accessory
+type TransactionError struct {
+	Transaction Transaction
+	Last        Transaction
+	Duplicate   bool
+}
+
+func (e *TransactionError) Error() string {
+	if e.Duplicate {
+		return fmt.Sprintf("ipod: duplicate transaction %v", e.Transaction)
+	}
+	return fmt.Sprintf("ipod: out-of-order transaction %v after %v", e.Transaction, e.Last)
+}
+
+// Transactions keeps track of the transaction IDs of a single link:
+// it numbers the ipod-initiated commands and checks
+// the ones initiated by the accessory.
+// It is safe for concurrent use.
+type Transactions struct {
+	mu   sync.Mutex
+	next uint16
+
+	last    Transaction
+	hasLast bool
+
+	mode TransactionMode
+}
+
+// Next returns a new transaction for an ipod-initiated command
+func (t *Transactions) Next() *Transaction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.next++
+	trx := Transaction(t.next)
+	return &trx
+}
+
+// Reset restarts the numbering on both sides of the link, i.e. on StartIDPS,
+// and switches the link to TransactionModeOn
+func (t *Transactions) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.next = 0
+	t.hasLast = false
+	t.mode = TransactionModeOn
+}
+
+// Mode returns the transaction mode of the link
+func (t *Transactions) Mode() TransactionMode {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.mode
+}
+
+// SetMode sets the transaction mode of the link
+func (t *Transactions) SetMode(m TransactionMode) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.mode = m
+}
+
+// Check records the transaction of a command initiated by the accessory,
+// a *TransactionError is returned if it is not newer than the last one.
+// The responses to ipod-initiated commands carry the ipod's transactions
+// and must not be checked.
+func (t *Transactions) Check(trx Transaction) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.hasLast {
+		t.last, t.hasLast = trx, true
+		return nil
+	}
+	switch d := int16(trx - t.last); {
+	case d == 0:
+		return &TransactionError{Transaction: trx, Last: t.last, Duplicate: true}
+	case d < 0:
+		return &TransactionError{Transaction: trx, Last: t.last}
+	}
+	t.last = trx
+	return nil
+}
+
+// TransactionWriter is a CommandWriter that owns
+// the transactions of its link, i.e. a Session
+type TransactionWriter interface {
+	CommandWriter
+	Transactions() *Transactions
+}
+
+var defaultTransactions Transactions
+
+func writerTransactions(w CommandWriter) *Transactions {
+	if tw, ok := w.(TransactionWriter); ok {
+		return tw.Transactions()
+	}
+	return &defaultTransactions
+}
+
+// NextTransaction returns a new transaction from the link of w.
+// Writers that are not a TransactionWriter share a global counter.
+func NextTransaction(w CommandWriter) *Transaction {
+	return writerTransactions(w).Next()
+}
+
+// ResetTransactions restarts the transaction numbering of the link of w
+func ResetTransactions(w CommandWriter) {
+	writerTransactions(w).Reset()
+}
+
+// TrxReset resets the global transaction counter.
+//
+// Deprecated: use ResetTransactions
+func TrxReset() {
+	defaultTransactions.Reset()
+}
+
+// TrxNext returns a new transaction from the global counter.
+//
+// Deprecated: use NextTransaction or Transactions.Next
+func TrxNext() *Transaction {
+	return defaultTransactions.Next()
+}
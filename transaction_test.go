@@ -0,0 +1,107 @@
+package ipod_test
+
+import (
+	"testing"
+
+	"github.com/oandrew/ipod"
+)
+
+func TestTransactions_Next(t *testing.T) {
+	var a, b ipod.Transactions
+	a.Next()
+	a.Next()
+	if got := *b.Next(); got != 1 {
+		t.Errorf("Transactions.Next() = %v, want 1", got)
+	}
+	a.Reset()
+	if got := *a.Next(); got != 1 {
+		t.Errorf("Transactions.Next() after Reset = %v, want 1", got)
+	}
+}
+
+func TestTransactions_Check(t *testing.T) {
+	tests := []struct {
+		name    string
+		issued  int
+		in      []ipod.Transaction
+		wantErr []bool
+	}{
+		{"increasing", 0, []ipod.Transaction{5, 6, 8}, []bool{false, false, false}},
+		{"duplicate", 0, []ipod.Transaction{5, 5}, []bool{false, true}},
+		{"out-of-order", 0, []ipod.Transaction{5, 4, 6}, []bool{false, true, false}},
+		{"wraparound", 0, []ipod.Transaction{0xffff, 0x0000}, []bool{false, false}},
+		// the ipod's transactions are no exception
+		{"issued", 2, []ipod.Transaction{1, 1, 2}, []bool{false, true, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var trx ipod.Transactions
+			for i := 0; i < tt.issued; i++ {
+				trx.Next()
+			}
+			for i, in := range tt.in {
+				err := trx.Check(in)
+				if (err != nil) != tt.wantErr[i] {
+					t.Errorf("Transactions.Check(%v) error = %v, wantErr %v", in, err, tt.wantErr[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSession_TransactionError(t *testing.T) {
+	req := &ipod.Command{
+		ID:          ipod.NewLingoCmdID(testSessionLingoID, 0x01),
+		Transaction: ipod.NewTransaction(7),
+		Payload:     &SessionRequest{},
+	}
+	frw := &testFrameReadWriter{
+		in: [][]byte{buildFrame(t, req), buildFrame(t, req)},
+	}
+	s := ipod.NewSession(frw)
+	var errs []error
+	s.Hooks.TransactionError = func(cmd *ipod.Command, err error) {
+		errs = append(errs, err)
+	}
+	if err := s.Serve(); err != nil {
+		t.Fatalf("Session.Serve() error = %v", err)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("Session.Serve() transaction errors = %v, want 1", errs)
+	}
+	if err, ok := errs[0].(*ipod.TransactionError); !ok || !err.Duplicate {
+		t.Errorf("Session.Serve() transaction error = %#v, want duplicate", errs[0])
+	}
+}
+
+func TestSession_TransactionErrorIssued(t *testing.T) {
+	// the accessory reuses the transaction of the command the ipod sent,
+	// only the response to it is not checked
+	frw := &testFrameReadWriter{
+		in: [][]byte{
+			buildFrame(t, sessionReq(1)),
+			buildFrame(t, sessionResp(1)),
+			buildFrame(t, sessionReq(1)),
+		},
+	}
+	s := ipod.NewSession(frw)
+	s.HandleFunc(testSessionLingoID, func(cmd *ipod.Command, w ipod.CommandWriter) error {
+		if _, ok := cmd.Payload.(*SessionRequest); ok {
+			ipod.Send(w, &SessionRequest{})
+		}
+		return nil
+	})
+	var errs []error
+	s.Hooks.TransactionError = func(cmd *ipod.Command, err error) {
+		errs = append(errs, err)
+	}
+	if err := s.Serve(); err != nil {
+		t.Fatalf("Session.Serve() error = %v", err)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("Session.Serve() transaction errors = %v, want 1", errs)
+	}
+	if err, ok := errs[0].(*ipod.TransactionError); !ok || !err.Duplicate || err.Transaction != 1 {
+		t.Errorf("Session.Serve() transaction error = %#v, want duplicate 1", errs[0])
+	}
+}
@@ -1,5 +1,9 @@
 package ipod
 
+import (
+	"bytes"
+)
+
 type FrameReader interface {
 	// ReadFrame reads a frame that contains
 	// one or more iap packets
@@ -30,50 +34,57 @@ func (d *DummyFrameReadWriter) WriteFrame([]byte) error {
 	return nil
 }
 
-// type packetReader struct {
-// 	r *RawPacketReader
-// }
-
-// func (pr *packetReader) ReadPacket() (*Packet, error) {
-// 	payload, err := pr.r.ReadPayload()
-// 	if err != nil {
-// 		return nil, err
-// 	}
-// 	return UnmarshalPacket(payload)
-// }
-
-// func NewPacketReader(frame []byte) PacketReader {
-// 	return &packetReader{
-// 		r: NewRawPacketReader(bytes.NewReader(frame)),
-// 	}
-
-// }
+// FrameSizer is implemented by transports that limit
+// the size of a frame sent in one piece, i.e. usbhid
+type FrameSizer interface {
+	// MaxFrameSize returns the size of the largest frame
+	// that is sent in one piece
+	MaxFrameSize() int
+}
 
-// type FrameBuilder struct {
-// 	buf *bytes.Buffer
-// 	w   *RawPacketWriter
-// }
+// FrameBuilder packs packets into frames of up to maxSize bytes.
+// A packet that doesn't fit by itself is sent in a frame of its own.
+type FrameBuilder struct {
+	w       FrameWriter
+	maxSize int
+	buf     bytes.Buffer
+	pw      *PacketWriter
+}
 
-// func (fb *FrameBuilder) WritePacket(pkt *Packet) error {
-// 	payload, err := MarshalPacket(pkt)
-// 	if err != nil {
-// 		return err
-// 	}
-// 	return fb.w.WritePayload(payload)
-// }
+// NewFrameBuilder creates a FrameBuilder that writes frames to w,
+// a maxSize of zero doesn't limit the frame size
+func NewFrameBuilder(w FrameWriter, maxSize int) *FrameBuilder {
+	fb := &FrameBuilder{
+		w:       w,
+		maxSize: maxSize,
+	}
+	fb.buf.Grow(1024)
+	fb.pw = NewPacketWriter(&fb.buf)
+	return fb
+}
 
-// func (fb *FrameBuilder) Frame() []byte {
-// 	return fb.buf.Bytes()
-// }
+// WritePacket adds the packet to the frame,
+// the frame is flushed first if the packet doesn't fit
+func (fb *FrameBuilder) WritePacket(pkt []byte) error {
+	if fb.maxSize > 0 && fb.buf.Len() > 0 && fb.buf.Len()+packetSize(len(pkt)) > fb.maxSize {
+		if err := fb.Flush(); err != nil {
+			return err
+		}
+	}
+	return fb.pw.WritePacket(pkt)
+}
 
-// func (fb *FrameBuilder) Reset() {
-// 	fb.buf.Reset()
-// }
+// Buffered returns the size of the frame built so far
+func (fb *FrameBuilder) Buffered() int {
+	return fb.buf.Len()
+}
 
-// func NewFrameBuilder() *FrameBuilder {
-// 	buf := bytes.NewBuffer(make([]byte, 0, 1024))
-// 	return &FrameBuilder{
-// 		buf: buf,
-// 		w:   NewRawPacketWriter(buf),
-// 	}
-// }
+// Flush writes the frame if it isn't empty
+func (fb *FrameBuilder) Flush() error {
+	if fb.buf.Len() == 0 {
+		return nil
+	}
+	err := fb.w.WriteFrame(fb.buf.Bytes())
+	fb.buf.Reset()
+	return err
+}
@@ -0,0 +1,56 @@
+package ipod_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/oandrew/ipod"
+)
+
+func encodePackets(pkts ...[]byte) []byte {
+	buf := bytes.Buffer{}
+	pw := ipod.NewPacketWriter(&buf)
+	for _, pkt := range pkts {
+		pw.WritePacket(pkt)
+	}
+	return buf.Bytes()
+}
+
+func TestFrameBuilder(t *testing.T) {
+	p1 := []byte{0x01, 0x02}
+	p2 := []byte{0x01, 0x03, 0x04}
+	large := bytes.Repeat([]byte{0xee}, 300)
+
+	tests := []struct {
+		name    string
+		maxSize int
+		pkts    [][]byte
+		want    [][]byte
+	}{
+		{"no-limit", 0, [][]byte{p1, p2, large}, [][]byte{encodePackets(p1, p2, large)}},
+		{"fits", 11, [][]byte{p1, p2}, [][]byte{encodePackets(p1, p2)}},
+		{"split", 10, [][]byte{p1, p2}, [][]byte{encodePackets(p1), encodePackets(p2)}},
+		{"too-large", 10, [][]byte{p1, large, p2}, [][]byte{encodePackets(p1), encodePackets(large), encodePackets(p2)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frw := &testFrameReadWriter{}
+			fb := ipod.NewFrameBuilder(frw, tt.maxSize)
+			for _, pkt := range tt.pkts {
+				if err := fb.WritePacket(pkt); err != nil {
+					t.Fatalf("FrameBuilder.WritePacket() error = %v", err)
+				}
+			}
+			if err := fb.Flush(); err != nil {
+				t.Fatalf("FrameBuilder.Flush() error = %v", err)
+			}
+			if fb.Buffered() != 0 {
+				t.Errorf("FrameBuilder.Buffered() = %d after Flush", fb.Buffered())
+			}
+			if !reflect.DeepEqual(frw.out, tt.want) {
+				t.Errorf("FrameBuilder frames = %x, want %x", frw.out, tt.want)
+			}
+		})
+	}
+}